@@ -0,0 +1,35 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package cli
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"github.com/immanent-tech/www-immanent-tech/server/middlewares"
+)
+
+// APIKeyCmd defines the `apikey` command for managing API keys.
+type APIKeyCmd struct {
+	Generate APIKeyGenerateCmd `cmd:"" help:"Generate an API key for a client."`
+}
+
+// APIKeyGenerateCmd defines the `apikey generate` command for generating a new API key.
+type APIKeyGenerateCmd struct {
+	Client string `arg:"" help:"Name of the client the key is issued to."`
+	Admin  bool   `help:"Generate a key for an administrator, allowed to export contact submissions."`
+}
+
+// Run generates a new API key, printing the key to give to the client and the entry to add to the configured list of
+// API keys (or admin keys).
+func (r *APIKeyGenerateCmd) Run(_ *Arguments) error {
+	list := "WWW_APIKEYS"
+	if r.Admin {
+		list = "WWW_ADMINKEYS"
+	}
+	key := rand.Text() + rand.Text()
+	fmt.Printf("Key (give to the client): %s\n", key)
+	fmt.Printf("Entry (add to %s): %s:%s\n", list, r.Client, middlewares.HashAPIKey(key))
+	return nil
+}
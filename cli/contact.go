@@ -0,0 +1,121 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/immanent-tech/www-immanent-tech/server/submissions"
+)
+
+const (
+	// exportDateFormat is the format of the dates bounding an export.
+	exportDateFormat = "2006-01-02"
+	// exportTimeout is the maximum time to wait for a server to export submissions.
+	exportTimeout = time.Minute
+)
+
+// ErrNoExportKey is returned when exporting from a server without an admin key.
+var ErrNoExportKey = errors.New("an admin key is required to export from a server")
+
+// ContactCmd defines the `contact` command for managing contact form submissions.
+type ContactCmd struct {
+	Export ContactExportCmd `cmd:"" help:"Export contact form submissions."`
+}
+
+// ContactExportCmd defines the `contact export` command for exporting stored contact form submissions. Submissions are
+// exported from a running server if its URL is given, as the database cannot be opened while a server is using it.
+// Otherwise, they are exported from a local database.
+type ContactExportCmd struct {
+	Format string    `enum:"csv,json" default:"csv" help:"Output format (${enum})."`
+	From   time.Time `format:"2006-01-02" help:"Only export submissions made on or after this date (YYYY-MM-DD)."`
+	To     time.Time `format:"2006-01-02" help:"Only export submissions made on or before this date (YYYY-MM-DD)."`
+	Output string    `short:"o" default:"-" help:"File to write to, or - for stdout."`
+	Server string    `help:"URL of a running server to export from, such as https://immanent.tech."`
+	Key    string    `help:"Admin key for exporting from the server." env:"WWW_EXPORTKEY"`
+	DB     string    `help:"Path to a local submissions database. Defaults to the configured path." type:"path"`
+}
+
+// Run performs the export of submissions.
+func (r *ContactExportCmd) Run(_ *Arguments) error {
+	var out io.Writer = os.Stdout
+	if r.Output != "-" {
+		file, err := os.Create(r.Output)
+		if err != nil {
+			return fmt.Errorf("could not create output file: %w", err)
+		}
+		defer file.Close() //nolint:errcheck
+		out = file
+	}
+
+	if r.Server != "" {
+		if err := r.exportFromServer(out); err != nil {
+			return fmt.Errorf("could not export submissions from server: %w", err)
+		}
+		return nil
+	}
+
+	store, err := submissions.OpenReadOnly(r.DB)
+	if err != nil {
+		return fmt.Errorf("could not open submissions: %w", err)
+	}
+	defer store.Close() //nolint:errcheck
+
+	// The to date is inclusive, so export up to the start of the following day.
+	to := r.To
+	if !to.IsZero() {
+		to = to.AddDate(0, 0, 1)
+	}
+	if err := store.Export(out, submissions.Format(r.Format), r.From, to); err != nil {
+		return fmt.Errorf("could not export submissions: %w", err)
+	}
+	return nil
+}
+
+// exportFromServer requests the export from the submissions endpoint of the server, authenticated with the admin key.
+func (r *ContactExportCmd) exportFromServer(out io.Writer) error {
+	if r.Key == "" {
+		return ErrNoExportKey
+	}
+
+	query := url.Values{}
+	query.Set("format", r.Format)
+	if !r.From.IsZero() {
+		query.Set("from", r.From.Format(exportDateFormat))
+	}
+	if !r.To.IsZero() {
+		query.Set("to", r.To.Format(exportDateFormat))
+	}
+	endpoint := strings.TrimRight(r.Server, "/") + "/api/v1/submissions?" + query.Encode()
+
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+r.Key)
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer res.Body.Close() //nolint:errcheck
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("server returned status %s", res.Status)
+	}
+	if _, err := io.Copy(out, res.Body); err != nil {
+		return fmt.Errorf("write export: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package cli_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/immanent-tech/www-immanent-tech/cli"
+	"github.com/immanent-tech/www-immanent-tech/server/submissions"
+)
+
+func TestContactExportFromServer(t *testing.T) {
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		got = req
+		if req.Header.Get("Authorization") != "Bearer admin-key" {
+			res.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		res.Write([]byte(`[{"reference":"IT-ABCDEFGHIJ"}]`)) //nolint:errcheck
+	}))
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name      string
+		cmd       cli.ContactExportCmd
+		wantErr   error
+		wantQuery string
+	}{
+		{
+			name: "dates",
+			cmd: cli.ContactExportCmd{
+				Format: "json",
+				From:   time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+				To:     time.Date(2026, time.January, 31, 0, 0, 0, 0, time.UTC),
+				Server: server.URL + "/",
+				Key:    "admin-key",
+			},
+			wantQuery: "format=json&from=2026-01-01&to=2026-01-31",
+		},
+		{
+			name: "unbounded",
+			cmd: cli.ContactExportCmd{
+				Format: "csv",
+				Server: server.URL,
+				Key:    "admin-key",
+			},
+			wantQuery: "format=csv",
+		},
+		{
+			name: "no key",
+			cmd: cli.ContactExportCmd{
+				Format: "csv",
+				Server: server.URL,
+			},
+			wantErr: cli.ErrNoExportKey,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			tt.cmd.Output = filepath.Join(t.TempDir(), "export")
+			err := tt.cmd.Run(nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Run() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if got.URL.Path != "/api/v1/submissions" || got.URL.RawQuery != tt.wantQuery {
+				t.Errorf("requested %s, want /api/v1/submissions?%s", got.URL, tt.wantQuery)
+			}
+			output, err := os.ReadFile(tt.cmd.Output)
+			if err != nil {
+				t.Fatalf("read output: %v", err)
+			}
+			if string(output) != `[{"reference":"IT-ABCDEFGHIJ"}]` {
+				t.Errorf("output = %s", output)
+			}
+		})
+	}
+}
+
+func TestContactExportFromServerRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+		res.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(server.Close)
+
+	cmd := cli.ContactExportCmd{
+		Format: "csv",
+		Server: server.URL,
+		Key:    "wrong-key",
+		Output: filepath.Join(t.TempDir(), "export"),
+	}
+	if err := cmd.Run(nil); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Run() error = %v, want the status of the server", err)
+	}
+}
+
+func TestContactExportLocal(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "submissions.db")
+	store, err := submissions.NewWithConfig([]byte("test-key"), submissions.Config{Path: dbPath})
+	if err != nil {
+		t.Fatalf("submissions.NewWithConfig() error = %v", err)
+	}
+	if err := store.Add(t.Context(), &submissions.Submission{
+		Reference:    "IT-ABCDEFGHIJ",
+		ContactEmail: "visitor@example.com",
+	}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	tests := []struct {
+		name      string
+		from, to  time.Time
+		wantFound bool
+	}{
+		{name: "unbounded", wantFound: true},
+		{name: "inclusive to date", from: today, to: today, wantFound: true},
+		{name: "before", to: today.AddDate(0, 0, -1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := cli.ContactExportCmd{
+				Format: "csv",
+				From:   tt.from,
+				To:     tt.to,
+				DB:     dbPath,
+				Output: filepath.Join(t.TempDir(), "export.csv"),
+			}
+			if err := cmd.Run(nil); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			output, err := os.ReadFile(cmd.Output)
+			if err != nil {
+				t.Fatalf("read output: %v", err)
+			}
+			if found := strings.Contains(string(output), "IT-ABCDEFGHIJ"); found != tt.wantFound {
+				t.Errorf("exported submission = %t, want %t:\n%s", found, tt.wantFound, output)
+			}
+		})
+	}
+}
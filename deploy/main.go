@@ -6,6 +6,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/pulumi/pulumi-gcp/sdk/v9/go/gcp/artifactregistry"
 	"github.com/pulumi/pulumi-gcp/sdk/v9/go/gcp/cloudrun"
@@ -17,6 +18,33 @@ import (
 	"github.com/pulumi/pulumi/sdk/v3/go/pulumi/config"
 )
 
+// cloudflareRanges are the address ranges Cloudflare proxies requests from, as published at
+// https://www.cloudflare.com/ips/. They are trusted to append the real client address to X-Forwarded-For.
+var cloudflareRanges = []string{
+	"173.245.48.0/20",
+	"103.21.244.0/22",
+	"103.22.200.0/22",
+	"103.31.4.0/22",
+	"141.101.64.0/18",
+	"108.162.192.0/18",
+	"190.93.240.0/20",
+	"188.114.96.0/20",
+	"197.234.240.0/22",
+	"198.41.128.0/17",
+	"162.158.0.0/15",
+	"104.16.0.0/13",
+	"104.24.0.0/14",
+	"172.64.0.0/13",
+	"131.0.72.0/22",
+	"2400:cb00::/32",
+	"2606:4700::/32",
+	"2803:f800::/32",
+	"2405:b500::/32",
+	"2405:8100::/32",
+	"2a06:98c0::/29",
+	"2c0f:f248::/32",
+}
+
 //nolint:funlen
 func main() {
 	pulumi.Run(func(ctx *pulumi.Context) error {
@@ -134,7 +162,10 @@ func main() {
 					ServiceAccount: serverSA.Email,
 					Scaling: &cloudrunv2.ServiceTemplateScalingArgs{
 						MaxInstanceCount: pulumi.Int(config.RequireInt(ctx, "server_max_instances")),
-						MinInstanceCount: pulumi.Int(0),
+						// Each instance delivers the messages in its own outbox, which is held in memory with the
+						// rest of the container filesystem. An instance is kept running so that queued messages are
+						// retried, rather than lost when the service scales to zero.
+						MinInstanceCount: pulumi.Int(1),
 					},
 					MaxInstanceRequestConcurrency: pulumi.Int(
 						config.RequireInt(ctx, "server_concurrency"),
@@ -148,7 +179,9 @@ func main() {
 									"memory": config.Require(ctx, "server_memory"),
 									"cpu":    config.Require(ctx, "server_cpu"),
 								}),
-								CpuIdle:         pulumi.Bool(true),
+								// The outbox worker delivers messages outside of requests, so it must not be
+								// throttled between them.
+								CpuIdle:         pulumi.Bool(false),
 								StartupCpuBoost: pulumi.Bool(false),
 							},
 							Ports: &cloudrunv2.ServiceTemplateContainerPortsArgs{
@@ -169,6 +202,12 @@ func main() {
 								cloudrunServiceEnv("APP_ENVIRONMENT", nil),
 								cloudrunServiceEnv("LOG_LEVEL", nil),
 								cloudrunServiceEnv("WWW_PORT", nil),
+								cloudrunServiceEnv("WWW_SIGNINGKEY", nil),
+								cloudrunServiceEnv("WWW_APIKEYS", nil),
+								cloudrunServiceEnv("WWW_ADMINKEYS", nil),
+								// Client IPs, taken from the hop added by Cloudflare before the Google front end.
+								cloudrunServiceEnv("WWW_CLIENTIPSTRATEGY", "xforwardedfor"),
+								cloudrunServiceEnv("WWW_TRUSTEDPROXIES", strings.Join(cloudflareRanges, ",")),
 								// CSP.
 								cloudrunServiceEnv("CSP_CONNECTSRC", nil),
 								cloudrunServiceEnv("CSP_IMGSRC", nil),
@@ -179,6 +218,7 @@ func main() {
 								cloudrunServiceEnv("CORS_MAXAGE", nil),
 								// Cloudflare
 								cloudrunServiceEnv("CLOUDFLARE_TURNSTILE_KEY", nil),
+								cloudrunServiceEnv("TURNSTILE_SECRET", nil),
 								// Umami
 								cloudrunServiceEnv("UMAMI_ID", nil),
 							},
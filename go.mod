@@ -5,7 +5,6 @@ replace github.com/immanent-tech/go-base v0.0.0 => ./base
 go 1.26.6
 
 require (
-	github.com/cwinters8/gomap v0.2.0
 	github.com/sebasvil20/templicons v1.1.0
 )
 
@@ -60,7 +59,6 @@ require (
 	github.com/json-iterator/go v1.1.12 // indirect
 	github.com/jub0bs/cors v1.0.5 // indirect
 	github.com/kevinburke/ssh_config v1.6.0 // indirect
-	github.com/klauspost/compress v1.18.7 // indirect
 	github.com/klauspost/cpuid/v2 v2.3.0 // indirect
 	github.com/knadh/koanf/v2 v2.3.6 // indirect
 	github.com/leodido/go-urn v1.4.0 // indirect
@@ -118,7 +116,6 @@ require (
 	go.uber.org/multierr v1.11.0 // indirect
 	golang.org/x/crypto v0.54.0 // indirect
 	golang.org/x/mod v0.38.0 // indirect
-	golang.org/x/net v0.57.0 // indirect
 	golang.org/x/sync v0.22.0 // indirect
 	golang.org/x/sys v0.47.0 // indirect
 	golang.org/x/term v0.45.0 // indirect
@@ -133,17 +130,20 @@ require (
 )
 
 require (
+	github.com/HugoSmits86/nativewebp v0.9.3
 	github.com/a-h/templ v0.3.1020
 	github.com/alecthomas/kong v1.16.1
+	github.com/andybalholm/brotli v1.1.0
 	github.com/angelofallars/htmx-go v0.5.0
 	github.com/didip/tollbooth/v8 v8.0.1
 	github.com/fatih/color v1.19.0 // indirect
 	github.com/go-chi/chi/v5 v5.3.1
 	github.com/go-json-experiment/json v0.0.0-20260623181947-01eb4420fa68 // indirect
 	github.com/go-playground/form/v4 v4.3.0
-	github.com/go-playground/validator/v10 v10.30.3 // indirect
+	github.com/go-playground/validator/v10 v10.30.3
 	github.com/go-viper/mapstructure/v2 v2.4.0 // indirect
 	github.com/immanent-tech/go-base v0.0.0
+	github.com/klauspost/compress v1.18.7
 	github.com/knadh/koanf/maps v0.1.2 // indirect
 	github.com/knadh/koanf/providers/env/v2 v2.0.1 // indirect
 	github.com/lmittmann/tint v1.2.0 // indirect
@@ -157,4 +157,7 @@ require (
 	github.com/samber/slog-multi v1.8.0 // indirect
 	github.com/veqryn/slog-context v0.9.0
 	github.com/veqryn/slog-json v0.5.0 // indirect
+	go.etcd.io/bbolt v1.4.3
+	golang.org/x/image v0.44.0
+	golang.org/x/net v0.57.0
 )
@@ -19,6 +19,8 @@ import (
 // CLI contains all of the commands and common options.
 var CLI struct {
 	Serve        cli.ServeCmd         `cmd:"" help:"Run server."`
+	Contact      cli.ContactCmd       `cmd:"" help:"Manage contact form submissions."`
+	APIKey       cli.APIKeyCmd        `cmd:"" name:"apikey" help:"Manage API keys."`
 	ProfileFlags logging.ProfileFlags `name:"profile" help:"Set profiling flags."`
 }
 
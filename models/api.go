@@ -0,0 +1,36 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// APIErrorResponse is the body of an error response from the API.
+type APIErrorResponse struct {
+	Error *APIError `json:"error"`
+}
+
+// APIError describes why an API request failed.
+type APIError struct {
+	// Code is a stable, machine-readable identifier of the error.
+	Code string `json:"code"`
+	// Message is a human-readable description of the error.
+	Message string `json:"message"`
+	// Fields maps the names of invalid fields to a description of why they are invalid.
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// WriteJSON writes the value as a JSON response with the given status.
+func WriteJSON(res http.ResponseWriter, status int, value any) error {
+	res.Header().Set("Content-Type", "application/json")
+	res.Header().Set("Cache-Control", "no-store")
+	res.WriteHeader(status)
+	if err := json.NewEncoder(res).Encode(value); err != nil {
+		return fmt.Errorf("encode response: %w", err)
+	}
+	return nil
+}
@@ -7,8 +7,13 @@ import "context"
 
 const (
 	csrfTokenCtxKey contextKey = "csrfToken"
+	apiClientCtxKey contextKey = "apiClient"
+	clientIPCtxKey  contextKey = "clientIP"
 )
 
+// CSRFTokenHeader is the request header in which forms submit the CSRF token.
+const CSRFTokenHeader = "X-CSRF-Token"
+
 type contextKey string
 
 // CSRFTokenToCtx stores the current valid CSRF token in the context.
@@ -23,3 +28,29 @@ func CSRFTokenFromCtx(ctx context.Context) string {
 	}
 	return ""
 }
+
+// APIClientToCtx stores the name of the authenticated API client in the context.
+func APIClientToCtx(ctx context.Context, client string) context.Context {
+	return context.WithValue(ctx, apiClientCtxKey, client)
+}
+
+// APIClientFromCtx retrieves the name of the authenticated API client from the context.
+func APIClientFromCtx(ctx context.Context) string {
+	if client, ok := ctx.Value(apiClientCtxKey).(string); ok {
+		return client
+	}
+	return ""
+}
+
+// ClientIPToCtx stores the IP address of the client that made the request in the context.
+func ClientIPToCtx(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPCtxKey, ip)
+}
+
+// ClientIPFromCtx retrieves the IP address of the client that made the request from the context.
+func ClientIPFromCtx(ctx context.Context) string {
+	if ip, ok := ctx.Value(clientIPCtxKey).(string); ok {
+		return ip
+	}
+	return ""
+}
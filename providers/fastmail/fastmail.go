@@ -1,30 +1,44 @@
 // Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
 // SPDX-License-Identifier: 	AGPL-3.0-or-later
 
+// Package fastmail provides a mailer.Mailer that delivers email via the Fastmail JMAP API.
+//
+// https://www.fastmail.com/dev/
 package fastmail
 
 import (
+	"context"
 	"fmt"
-	"net/mail"
+	"net/http"
 	"sync"
+	"time"
 
-	"github.com/cwinters8/gomap"
 	"github.com/immanent-tech/go-base/config"
 	"github.com/immanent-tech/go-base/validation"
+
+	"github.com/immanent-tech/www-immanent-tech/providers/mailer"
 )
 
 const (
 	configPrefix = "FASTMAIL_"
-	apiEndpoint  = "https://api.fastmail.com/jmap/session"
 )
 
-// Config contains the server configuration options.
+// Config contains the Fastmail configuration options.
 type Config struct {
-	APIKey   string `koanf:"apikey"   validate:"required"`
+	// APIKey is the API token used to authenticate with Fastmail.
+	APIKey string `koanf:"apikey" validate:"required"`
+	// Identity is the address of the Fastmail identity email is sent as.
 	Identity string `koanf:"identity" validate:"required,email"`
+	// SessionURL is the JMAP session endpoint.
+	SessionURL string `koanf:"sessionurl" validate:"required,url"`
+	// Timeout is the maximum time to wait for each request to the JMAP API.
+	Timeout config.Duration `koanf:"timeout" validate:"omitempty"`
 }
 
-var cfg Config
+var cfg = Config{
+	SessionURL: "https://api.fastmail.com/jmap/session",
+	Timeout:    config.NewDuration(30 * time.Second),
+}
 
 // loadConfig loads the server configuration and ensures this is only done
 // one time, no matter how many times it is called.
@@ -38,40 +52,112 @@ var loadConfig = sync.OnceValue(func() error {
 	return nil
 })
 
-var client *gomap.Client
+// Mailer is a mailer.Mailer that sends email through Fastmail.
+type Mailer struct {
+	client     *http.Client
+	apiKey     string
+	identity   string
+	sessionURL string
+
+	mu      sync.Mutex
+	account *account
+}
 
-var loadClient = sync.OnceValue(func() error {
+// New creates a new Fastmail Mailer. The Fastmail config will be loaded from the environment.
+func New() (*Mailer, error) {
 	if err := loadConfig(); err != nil {
-		return fmt.Errorf("load config: %w", err)
+		return nil, fmt.Errorf("load config: %w", err)
 	}
+	return NewWithConfig(cfg)
+}
 
-	var err error
-	client, err = gomap.NewClient(
-		apiEndpoint,
-		cfg.APIKey,
-		gomap.DefaultDrafts,
-		gomap.DefaultSent,
-	)
+// NewWithConfig creates a new Fastmail Mailer with the given config. The JMAP session is resolved when the first
+// message is sent.
+func NewWithConfig(fastmailCfg Config) (*Mailer, error) {
+	if err := validation.Validate.Struct(fastmailCfg); err != nil {
+		return nil, fmt.Errorf("validate config: %w", err)
+	}
+	return &Mailer{
+		client:     &http.Client{Timeout: fastmailCfg.Timeout.Duration},
+		apiKey:     fastmailCfg.APIKey,
+		identity:   fastmailCfg.Identity,
+		sessionURL: fastmailCfg.SessionURL,
+	}, nil
+}
+
+// Send will send the message through Fastmail. The message is created as a draft and then submitted, which moves it to
+// the sent mailbox. When the message has both text and html bodies, they are sent as multipart/alternative. Any
+// attachments are uploaded as blobs first and sent with the bodies as multipart/mixed.
+func (m *Mailer) Send(ctx context.Context, msg *mailer.Message) error {
+	if err := msg.Valid(); err != nil {
+		return fmt.Errorf("invalid message: %w", err)
+	}
+	acct, err := m.resolveAccount(ctx)
 	if err != nil {
-		return fmt.Errorf("new client: %w", err)
+		return fmt.Errorf("resolve account: %w", err)
 	}
 
-	return nil
-})
+	body, values := bodyStructure(msg.Text, msg.HTML)
+	if len(msg.Attachments) > 0 {
+		parts := []*bodyPart{body}
+		for _, attachment := range msg.Attachments {
+			blobID, err := m.upload(ctx, acct, attachment)
+			if err != nil {
+				return fmt.Errorf("upload attachment %q: %w", attachment.Filename, err)
+			}
+			parts = append(parts, &bodyPart{
+				BlobID:      blobID,
+				Type:        attachment.ContentType,
+				Name:        attachment.Filename,
+				Disposition: "attachment",
+			})
+		}
+		body = &bodyPart{Type: "multipart/mixed", SubParts: parts}
+	}
 
-func SendEmail(from *mail.Address, subject, body string) error {
-	if err := loadClient(); err != nil {
-		return fmt.Errorf("load client: %w", err)
+	email := map[string]any{
+		"mailboxIds":    map[string]bool{acct.draftsID: true},
+		"keywords":      map[string]bool{"$draft": true, "$seen": true},
+		"from":          []*emailAddress{newEmailAddress(msg.From.Name, msg.From.Address)},
+		"to":            newEmailAddresses(msg),
+		"subject":       msg.Subject,
+		"bodyStructure": body,
+		"bodyValues":    values,
+	}
+	for key, value := range msg.ExtraHeaders() {
+		email["header:"+key+":asText"] = value
 	}
-	if err := client.SendEmailWithIdentity(
-		gomap.NewAddresses(gomap.NewAddress(from.Name, from.Address)),
-		gomap.NewAddresses(gomap.NewAddress("Immanent Tech", "hello@immanent.tech")),
-		subject,
-		body,
-		cfg.Identity,
-		false,
-	); err != nil {
+
+	if err := m.submit(ctx, acct, email); err != nil {
 		return fmt.Errorf("send email: %w", err)
 	}
 	return nil
 }
+
+// bodyStructure returns the body structure of an email with the given bodies, and the values of its parts. An email
+// with both a text and html body is sent as multipart/alternative, with the text first as the least preferred.
+func bodyStructure(text, htmlBody string) (*bodyPart, map[string]*bodyValue) {
+	values := make(map[string]*bodyValue)
+	var parts []*bodyPart
+	if text != "" {
+		parts = append(parts, &bodyPart{PartID: "text", Type: "text/plain"})
+		values["text"] = &bodyValue{Value: text}
+	}
+	if htmlBody != "" {
+		parts = append(parts, &bodyPart{PartID: "html", Type: "text/html"})
+		values["html"] = &bodyValue{Value: htmlBody}
+	}
+	if len(parts) == 1 {
+		return parts[0], values
+	}
+	return &bodyPart{Type: "multipart/alternative", SubParts: parts}, values
+}
+
+// newEmailAddresses returns the recipients of the message as JMAP addresses.
+func newEmailAddresses(msg *mailer.Message) []*emailAddress {
+	addresses := make([]*emailAddress, 0, len(msg.To))
+	for _, to := range msg.To {
+		addresses = append(addresses, newEmailAddress(to.Name, to.Address))
+	}
+	return addresses
+}
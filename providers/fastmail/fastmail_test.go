@@ -0,0 +1,362 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package fastmail_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/immanent-tech/www-immanent-tech/providers/fastmail"
+	"github.com/immanent-tech/www-immanent-tech/providers/mailer"
+)
+
+const (
+	testAPIKey   = "test-key"
+	testIdentity = "hello@example.com"
+	testAccount  = "u1"
+)
+
+// jmapServer is a stub JMAP API, recording the emails and submissions it is asked to create.
+type jmapServer struct {
+	*httptest.Server
+
+	// notCreated, if set, is returned as the error creating each email.
+	notCreated string
+
+	mu          sync.Mutex
+	sessions    int
+	uploads     []upload
+	emails      []map[string]any
+	submissions []map[string]any
+}
+
+// upload is a blob uploaded to the stub JMAP API.
+type upload struct {
+	accountID   string
+	contentType string
+	data        []byte
+}
+
+func newJMAPServer(t *testing.T) *jmapServer {
+	t.Helper()
+	stub := &jmapServer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /session", stub.session)
+	mux.HandleFunc("POST /api", stub.api)
+	mux.HandleFunc("POST /upload/{accountId}/", stub.upload)
+	stub.Server = httptest.NewServer(stub.authenticate(mux))
+	t.Cleanup(stub.Close)
+	return stub
+}
+
+func (s *jmapServer) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer "+testAPIKey {
+			res.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(res, req)
+	})
+}
+
+func (s *jmapServer) session(res http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	s.sessions++
+	s.mu.Unlock()
+	json.NewEncoder(res).Encode(map[string]any{
+		"apiUrl":          s.URL + "/api",
+		"uploadUrl":       s.URL + "/upload/{accountId}/",
+		"primaryAccounts": map[string]string{"urn:ietf:params:jmap:mail": testAccount},
+	})
+}
+
+func (s *jmapServer) upload(res http.ResponseWriter, req *http.Request) {
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uploads = append(s.uploads, upload{
+		accountID:   req.PathValue("accountId"),
+		contentType: req.Header.Get("Content-Type"),
+		data:        data,
+	})
+	res.WriteHeader(http.StatusCreated)
+	json.NewEncoder(res).Encode(map[string]any{
+		"accountId": req.PathValue("accountId"),
+		"blobId":    "blob-" + strconv.Itoa(len(s.uploads)),
+		"size":      len(data),
+	})
+}
+
+func (s *jmapServer) api(res http.ResponseWriter, req *http.Request) {
+	var request struct {
+		MethodCalls [][3]json.RawMessage `json:"methodCalls"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var responses [][3]any
+	for _, call := range request.MethodCalls {
+		var name, id string
+		var arguments map[string]any
+		json.Unmarshal(call[0], &name)
+		json.Unmarshal(call[1], &arguments)
+		json.Unmarshal(call[2], &id)
+
+		var result any
+		switch name {
+		case "Mailbox/query":
+			role := arguments["filter"].(map[string]any)["role"].(string)
+			result = map[string]any{"ids": []string{"mailbox-" + role}}
+		case "Identity/get":
+			result = map[string]any{"list": []map[string]string{
+				{"id": "identity-other", "email": "other@example.com"},
+				{"id": "identity-hello", "email": testIdentity},
+			}}
+		case "Email/set":
+			draft := arguments["create"].(map[string]any)["draft"].(map[string]any)
+			if s.notCreated != "" {
+				result = map[string]any{"notCreated": map[string]any{"draft": map[string]string{"type": s.notCreated}}}
+				break
+			}
+			s.emails = append(s.emails, draft)
+			result = map[string]any{"created": map[string]any{"draft": map[string]string{"id": "email-1"}}}
+		case "EmailSubmission/set":
+			if s.notCreated != "" {
+				result = map[string]any{
+					"notCreated": map[string]any{"submission": map[string]string{"type": "invalidEmail"}},
+				}
+				break
+			}
+			s.submissions = append(s.submissions, arguments)
+			result = map[string]any{"created": map[string]any{"submission": map[string]string{"id": "submission-1"}}}
+		default:
+			responses = append(responses, [3]any{"error", map[string]string{"type": "unknownMethod"}, id})
+			continue
+		}
+		responses = append(responses, [3]any{name, result, id})
+	}
+	json.NewEncoder(res).Encode(map[string]any{"methodResponses": responses})
+}
+
+func newTestMailer(t *testing.T, stub *jmapServer, apiKey, identity string) *fastmail.Mailer {
+	t.Helper()
+	m, err := fastmail.NewWithConfig(fastmail.Config{
+		APIKey:     apiKey,
+		Identity:   identity,
+		SessionURL: stub.URL + "/session",
+	})
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+	return m
+}
+
+func newTestMessage() *mailer.Message {
+	return &mailer.Message{
+		From:    &mail.Address{Name: "Visitor", Address: "visitor@example.com"},
+		To:      []*mail.Address{{Address: "hello@example.com"}},
+		Subject: "Greetings",
+		Text:    "hello",
+		HTML:    "<p>hello</p>",
+		Headers: map[string]string{"X-Submission-Id": "abc123", "Subject": "Overridden"},
+	}
+}
+
+func TestSend(t *testing.T) {
+	stub := newJMAPServer(t)
+	m := newTestMailer(t, stub, testAPIKey, testIdentity)
+
+	for range 2 {
+		if err := m.Send(t.Context(), newTestMessage()); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+	if stub.sessions != 1 {
+		t.Errorf("session fetched %d times, want once", stub.sessions)
+	}
+	if len(stub.emails) != 2 || len(stub.submissions) != 2 {
+		t.Fatalf("created %d emails and %d submissions, want 2 of each", len(stub.emails), len(stub.submissions))
+	}
+
+	email := stub.emails[0]
+	if got := email["subject"]; got != "Greetings" {
+		t.Errorf("subject = %v, want Greetings", got)
+	}
+	if got := email["header:X-Submission-Id:asText"]; got != "abc123" {
+		t.Errorf("X-Submission-Id = %v, want abc123", got)
+	}
+	if _, found := email["header:Subject:asText"]; found {
+		t.Error("reserved Subject header was set")
+	}
+	if got := email["mailboxIds"].(map[string]any); got["mailbox-drafts"] != true {
+		t.Errorf("mailboxIds = %v, want the drafts mailbox", got)
+	}
+
+	// Both bodies are sent as alternatives.
+	body := email["bodyStructure"].(map[string]any)
+	if got := body["type"]; got != "multipart/alternative" {
+		t.Fatalf("body type = %v, want multipart/alternative", got)
+	}
+	parts := body["subParts"].([]any)
+	if len(parts) != 2 {
+		t.Fatalf("body has %d parts, want 2", len(parts))
+	}
+	values := email["bodyValues"].(map[string]any)
+	for i, want := range []struct{ contentType, value string }{{"text/plain", "hello"}, {"text/html", "<p>hello</p>"}} {
+		part := parts[i].(map[string]any)
+		if part["type"] != want.contentType {
+			t.Errorf("part %d type = %v, want %s", i, part["type"], want.contentType)
+		}
+		value := values[part["partId"].(string)].(map[string]any)["value"]
+		if value != want.value {
+			t.Errorf("part %d value = %v, want %s", i, value, want.value)
+		}
+	}
+
+	// The email is submitted with the identity and moved to the sent mailbox.
+	submission := stub.submissions[0]
+	created := submission["create"].(map[string]any)["submission"].(map[string]any)
+	if created["identityId"] != "identity-hello" || created["emailId"] != "#draft" {
+		t.Errorf("submission = %v, want identity-hello sending #draft", created)
+	}
+	update := submission["onSuccessUpdateEmail"].(map[string]any)["#submission"].(map[string]any)
+	if update["mailboxIds/mailbox-sent"] != true {
+		t.Errorf("onSuccessUpdateEmail = %v, want the email moved to the sent mailbox", update)
+	}
+}
+
+func TestSendSingleBody(t *testing.T) {
+	stub := newJMAPServer(t)
+	m := newTestMailer(t, stub, testAPIKey, testIdentity)
+
+	msg := newTestMessage()
+	msg.HTML = ""
+	if err := m.Send(t.Context(), msg); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	body := stub.emails[0]["bodyStructure"].(map[string]any)
+	if body["type"] != "text/plain" || body["subParts"] != nil {
+		t.Errorf("bodyStructure = %v, want a single text/plain part", body)
+	}
+}
+
+func TestSendAttachments(t *testing.T) {
+	stub := newJMAPServer(t)
+	m := newTestMailer(t, stub, testAPIKey, testIdentity)
+
+	msg := newTestMessage()
+	msg.Attachments = []*mailer.Attachment{
+		{Filename: "notes.txt", ContentType: "text/plain", Data: []byte("notes")},
+		{Filename: "brief.pdf", ContentType: "application/pdf", Data: []byte("%PDF-1.7")},
+	}
+	if err := m.Send(t.Context(), msg); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if len(stub.uploads) != len(msg.Attachments) {
+		t.Fatalf("uploaded %d blobs, want %d", len(stub.uploads), len(msg.Attachments))
+	}
+	for i, attachment := range msg.Attachments {
+		uploaded := stub.uploads[i]
+		if uploaded.accountID != testAccount || uploaded.contentType != attachment.ContentType ||
+			!bytes.Equal(uploaded.data, attachment.Data) {
+			t.Errorf("upload %d = %+v, want %s to account %s", i, uploaded, attachment.Filename, testAccount)
+		}
+	}
+
+	// The attachments are sent alongside the alternative bodies.
+	body := stub.emails[0]["bodyStructure"].(map[string]any)
+	if got := body["type"]; got != "multipart/mixed" {
+		t.Fatalf("body type = %v, want multipart/mixed", got)
+	}
+	parts := body["subParts"].([]any)
+	if len(parts) != 3 {
+		t.Fatalf("body has %d parts, want 3", len(parts))
+	}
+	if got := parts[0].(map[string]any)["type"]; got != "multipart/alternative" {
+		t.Errorf("first part type = %v, want multipart/alternative", got)
+	}
+	for i, attachment := range msg.Attachments {
+		part := parts[i+1].(map[string]any)
+		want := map[string]any{
+			"blobId":      "blob-" + strconv.Itoa(i+1),
+			"type":        attachment.ContentType,
+			"name":        attachment.Filename,
+			"disposition": "attachment",
+		}
+		for key, value := range want {
+			if part[key] != value {
+				t.Errorf("attachment part %d %s = %v, want %v", i, key, part[key], value)
+			}
+		}
+	}
+}
+
+func TestSendErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		apiKey     string
+		identity   string
+		notCreated string
+		msg        *mailer.Message
+		want       error
+	}{
+		{
+			name:     "invalid message",
+			apiKey:   testAPIKey,
+			identity: testIdentity,
+			msg:      &mailer.Message{},
+			want:     mailer.ErrNoSender,
+		},
+		{
+			name:     "unknown identity",
+			apiKey:   testAPIKey,
+			identity: "unknown@example.com",
+			msg:      newTestMessage(),
+			want:     fastmail.ErrNoIdentity,
+		},
+		{
+			name:       "email not created",
+			apiKey:     testAPIKey,
+			identity:   testIdentity,
+			notCreated: "invalidProperties",
+			msg:        newTestMessage(),
+			want:       fastmail.ErrNotCreated,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := newJMAPServer(t)
+			stub.notCreated = tt.notCreated
+			m := newTestMailer(t, stub, tt.apiKey, tt.identity)
+			if err := m.Send(t.Context(), tt.msg); !errors.Is(err, tt.want) {
+				t.Errorf("Send() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	t.Run("unauthorised", func(t *testing.T) {
+		stub := newJMAPServer(t)
+		m := newTestMailer(t, stub, "wrong-key", testIdentity)
+		if err := m.Send(t.Context(), newTestMessage()); err == nil {
+			t.Error("Send() error = nil, want an error")
+		}
+	})
+}
@@ -0,0 +1,347 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package fastmail
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/immanent-tech/www-immanent-tech/providers/mailer"
+)
+
+const (
+	capabilityCore       = "urn:ietf:params:jmap:core"
+	capabilityMail       = "urn:ietf:params:jmap:mail"
+	capabilitySubmission = "urn:ietf:params:jmap:submission"
+	// methodError is the name of the response to a method call that failed.
+	methodError = "error"
+)
+
+var (
+	// ErrNoAccount is returned when the session has no primary mail account.
+	ErrNoAccount = errors.New("no mail account")
+	// ErrNoMailbox is returned when the account has no mailbox with a role needed to send email.
+	ErrNoMailbox = errors.New("no mailbox")
+	// ErrNoIdentity is returned when the account has no identity for the configured address.
+	ErrNoIdentity = errors.New("no identity")
+	// ErrMethodFailed is returned when a method call to the JMAP API fails.
+	ErrMethodFailed = errors.New("method failed")
+	// ErrNoBlob is returned when uploading an attachment does not return a blob ID.
+	ErrNoBlob = errors.New("no blob id")
+	// ErrNotCreated is returned when the JMAP API does not create an object, such as the email or its submission.
+	ErrNotCreated = errors.New("not created")
+)
+
+// session is the part of the JMAP session resource used by the Mailer.
+type session struct {
+	APIURL          string            `json:"apiUrl"`
+	UploadURL       string            `json:"uploadUrl"`
+	PrimaryAccounts map[string]string `json:"primaryAccounts"`
+}
+
+// account contains the details of the JMAP account needed to send email, resolved from the session.
+type account struct {
+	id         string
+	apiURL     string
+	uploadURL  string
+	identityID string
+	draftsID   string
+	sentID     string
+}
+
+// call is a JMAP method call: the method name, its arguments and an ID identifying its response.
+type call [3]any
+
+// request is a JMAP API request.
+type request struct {
+	Using       []string `json:"using"`
+	MethodCalls []call   `json:"methodCalls"`
+}
+
+// response is a JMAP API response. Each method response is the method name, its arguments and the ID of the call.
+type response struct {
+	MethodResponses [][3]json.RawMessage `json:"methodResponses"`
+}
+
+// methodErrorResponse is the arguments of a method response for a failed call.
+type methodErrorResponse struct {
+	Type        string `json:"type"`
+	Description string `json:"description"`
+}
+
+// queryResponse is the arguments of a response to a /query call.
+type queryResponse struct {
+	IDs []string `json:"ids"`
+}
+
+// identitiesResponse is the arguments of a response to an Identity/get call.
+type identitiesResponse struct {
+	List []struct {
+		ID    string `json:"id"`
+		Email string `json:"email"`
+	} `json:"list"`
+}
+
+// setResponse is the arguments of a response to a /set call.
+type setResponse struct {
+	Created    map[string]json.RawMessage     `json:"created"`
+	NotCreated map[string]methodErrorResponse `json:"notCreated"`
+}
+
+// emailAddress is a JMAP EmailAddress.
+type emailAddress struct {
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email"`
+}
+
+func newEmailAddress(name, address string) *emailAddress {
+	return &emailAddress{Name: name, Email: address}
+}
+
+// bodyPart is a JMAP EmailBodyPart, as used to create an email. Parts either reference a body value by PartID, an
+// uploaded blob by BlobID, or contain SubParts.
+type bodyPart struct {
+	PartID      string      `json:"partId,omitempty"`
+	BlobID      string      `json:"blobId,omitempty"`
+	Type        string      `json:"type"`
+	Name        string      `json:"name,omitempty"`
+	Disposition string      `json:"disposition,omitempty"`
+	SubParts    []*bodyPart `json:"subParts,omitempty"`
+}
+
+// uploadResponse is the response to a blob upload.
+type uploadResponse struct {
+	BlobID string `json:"blobId"`
+}
+
+// bodyValue is a JMAP EmailBodyValue.
+type bodyValue struct {
+	Value string `json:"value"`
+}
+
+// resolveAccount returns the details of the account used to send email. They are resolved from the session on first
+// use and cached, so that only the first message sent waits for them.
+func (m *Mailer) resolveAccount(ctx context.Context) (*account, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.account != nil {
+		return m.account, nil
+	}
+
+	sess, err := m.getSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+	acct := &account{
+		id:        sess.PrimaryAccounts[capabilityMail],
+		apiURL:    sess.APIURL,
+		uploadURL: sess.UploadURL,
+	}
+	if acct.id == "" {
+		return nil, ErrNoAccount
+	}
+
+	responses, err := m.call(ctx, acct.apiURL,
+		mailboxQuery(acct.id, "drafts"),
+		mailboxQuery(acct.id, "sent"),
+		call{"Identity/get", map[string]any{"accountId": acct.id, "ids": nil}, "identities"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for role, id := range map[string]*string{"drafts": &acct.draftsID, "sent": &acct.sentID} {
+		var mailboxes queryResponse
+		if err := json.Unmarshal(responses[role], &mailboxes); err != nil {
+			return nil, fmt.Errorf("decode %s mailbox: %w", role, err)
+		}
+		if len(mailboxes.IDs) == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrNoMailbox, role)
+		}
+		*id = mailboxes.IDs[0]
+	}
+
+	var identities identitiesResponse
+	if err := json.Unmarshal(responses["identities"], &identities); err != nil {
+		return nil, fmt.Errorf("decode identities: %w", err)
+	}
+	for _, identity := range identities.List {
+		if strings.EqualFold(identity.Email, m.identity) {
+			acct.identityID = identity.ID
+			break
+		}
+	}
+	if acct.identityID == "" {
+		return nil, fmt.Errorf("%w: %s", ErrNoIdentity, m.identity)
+	}
+
+	m.account = acct
+	return acct, nil
+}
+
+// mailboxQuery returns a call querying the mailbox with the given role, identified by the role.
+func mailboxQuery(accountID, role string) call {
+	return call{"Mailbox/query", map[string]any{
+		"accountId": accountID,
+		"filter":    map[string]string{"role": role},
+	}, role}
+}
+
+// getSession fetches the JMAP session resource.
+func (m *Mailer) getSession(ctx context.Context) (*session, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.sessionURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create session request: %w", err)
+	}
+	res, err := m.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("get session: %w", err)
+	}
+	defer res.Body.Close() //nolint:errcheck
+
+	sess := &session{}
+	if err := json.NewDecoder(res.Body).Decode(sess); err != nil {
+		return nil, fmt.Errorf("decode session: %w", err)
+	}
+	return sess, nil
+}
+
+// upload uploads the attachment as a blob, returning its ID.
+func (m *Mailer) upload(ctx context.Context, acct *account, attachment *mailer.Attachment) (string, error) {
+	uploadURL := strings.ReplaceAll(acct.uploadURL, "{accountId}", url.PathEscape(acct.id))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, bytes.NewReader(attachment.Data))
+	if err != nil {
+		return "", fmt.Errorf("create upload request: %w", err)
+	}
+	req.Header.Set("Content-Type", attachment.ContentType)
+	res, err := m.do(req)
+	if err != nil {
+		return "", fmt.Errorf("upload blob: %w", err)
+	}
+	defer res.Body.Close() //nolint:errcheck
+
+	var uploaded uploadResponse
+	if err := json.NewDecoder(res.Body).Decode(&uploaded); err != nil {
+		return "", fmt.Errorf("decode upload response: %w", err)
+	}
+	if uploaded.BlobID == "" {
+		return "", ErrNoBlob
+	}
+	return uploaded.BlobID, nil
+}
+
+// submit creates the email as a draft and submits it for delivery in a single request. Once submitted, the email is
+// moved from the drafts mailbox to the sent mailbox.
+func (m *Mailer) submit(ctx context.Context, acct *account, email map[string]any) error {
+	responses, err := m.call(ctx, acct.apiURL,
+		call{"Email/set", map[string]any{
+			"accountId": acct.id,
+			"create":    map[string]any{"draft": email},
+		}, "email"},
+		call{"EmailSubmission/set", map[string]any{
+			"accountId": acct.id,
+			"create": map[string]any{
+				"submission": map[string]string{"identityId": acct.identityID, "emailId": "#draft"},
+			},
+			"onSuccessUpdateEmail": map[string]any{
+				"#submission": map[string]any{
+					"mailboxIds/" + acct.draftsID: nil,
+					"mailboxIds/" + acct.sentID:   true,
+					"keywords/$draft":             nil,
+				},
+			},
+		}, "submission"},
+	)
+	if err != nil {
+		return err
+	}
+	if err := checkCreated(responses["email"], "draft"); err != nil {
+		return fmt.Errorf("create email: %w", err)
+	}
+	if err := checkCreated(responses["submission"], "submission"); err != nil {
+		return fmt.Errorf("submit email: %w", err)
+	}
+	return nil
+}
+
+// checkCreated checks the response to a /set call created the object with the given creation ID.
+func checkCreated(arguments json.RawMessage, creationID string) error {
+	var set setResponse
+	if err := json.Unmarshal(arguments, &set); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	if setErr, found := set.NotCreated[creationID]; found {
+		return fmt.Errorf("%w: %s: %s", ErrNotCreated, setErr.Type, setErr.Description)
+	}
+	if _, found := set.Created[creationID]; !found {
+		return ErrNotCreated
+	}
+	return nil
+}
+
+// call makes the method calls to the JMAP API, returning the arguments of each method response by call ID. If any of
+// the calls failed, an error is returned.
+func (m *Mailer) call(ctx context.Context, apiURL string, calls ...call) (map[string]json.RawMessage, error) {
+	body, err := json.Marshal(&request{
+		Using:       []string{capabilityCore, capabilityMail, capabilitySubmission},
+		MethodCalls: calls,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create api request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := m.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("call api: %w", err)
+	}
+	defer res.Body.Close() //nolint:errcheck
+
+	var apiResponse response
+	if err := json.NewDecoder(res.Body).Decode(&apiResponse); err != nil {
+		return nil, fmt.Errorf("decode api response: %w", err)
+	}
+	responses := make(map[string]json.RawMessage, len(apiResponse.MethodResponses))
+	for _, methodResponse := range apiResponse.MethodResponses {
+		var name, id string
+		if err := json.Unmarshal(methodResponse[0], &name); err != nil {
+			return nil, fmt.Errorf("decode method name: %w", err)
+		}
+		if err := json.Unmarshal(methodResponse[2], &id); err != nil {
+			return nil, fmt.Errorf("decode call id: %w", err)
+		}
+		if name == methodError {
+			var methodErr methodErrorResponse
+			if err := json.Unmarshal(methodResponse[1], &methodErr); err != nil {
+				return nil, fmt.Errorf("decode method error: %w", err)
+			}
+			return nil, fmt.Errorf("%w: %s: %s", ErrMethodFailed, id, methodErr.Type)
+		}
+		responses[id] = methodResponse[1]
+	}
+	return responses, nil
+}
+
+// do sends the request authenticated with the API key, returning an error if the response is not successful.
+func (m *Mailer) do(req *http.Request) (*http.Response, error) {
+	req.Header.Set("Authorization", "Bearer "+m.apiKey)
+	res, err := m.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send request: %w", err)
+	}
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		res.Body.Close() //nolint:errcheck
+		return nil, fmt.Errorf("jmap returned status %s", res.Status)
+	}
+	return res, nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+// Package mailer defines an interface for delivering email, allowing the mail provider used by the server to be swapped
+// out without changing the handlers that send mail.
+package mailer
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/mail"
+	"net/textproto"
+	"slices"
+
+	slogctx "github.com/veqryn/slog-context"
+)
+
+var (
+	// ErrNoSender indicates a message was sent without a sender.
+	ErrNoSender = errors.New("no sender")
+	// ErrNoRecipients indicates a message was sent without any recipients.
+	ErrNoRecipients = errors.New("no recipients")
+	// ErrNoBody indicates a message was sent without either a text or html body.
+	ErrNoBody = errors.New("no message body")
+)
+
+// reservedHeaders are the headers that mailers set from the fields of a message, which cannot be overridden by its
+// additional headers.
+var reservedHeaders = []string{
+	"Bcc",
+	"Cc",
+	"Content-Disposition",
+	"Content-Transfer-Encoding",
+	"Content-Type",
+	"Date",
+	"From",
+	"Message-Id",
+	"Mime-Version",
+	"Reply-To",
+	"Sender",
+	"Subject",
+	"To",
+}
+
+// Mailer is a provider that can deliver email messages.
+type Mailer interface {
+	// Send will deliver the given message, returning a non-nil error if delivery failed.
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Message represents an email message to be sent by a Mailer.
+type Message struct {
+	// From is the sender of the message.
+	From *mail.Address `json:"from"`
+	// To contains the recipients of the message.
+	To []*mail.Address `json:"to"`
+	// Subject is the message subject.
+	Subject string `json:"subject"`
+	// Text is the plain text body of the message.
+	Text string `json:"text,omitempty"`
+	// HTML is the html body of the message.
+	HTML string `json:"html,omitempty"`
+	// Headers contains any additional headers to set on the message. Headers that are set from the other fields of
+	// the message cannot be overridden.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Attachments contains any files attached to the message.
+	Attachments []*Attachment `json:"attachments,omitempty"`
+}
+
+// Attachment is a file attached to a message.
+type Attachment struct {
+	// Filename is the name of the file.
+	Filename string `json:"filename"`
+	// ContentType is the MIME type of the file.
+	ContentType string `json:"content_type"`
+	// Data is the content of the file.
+	Data []byte `json:"data"`
+}
+
+// Valid will return a non-nil error if the message cannot be sent.
+func (m *Message) Valid() error {
+	if m.From == nil {
+		return ErrNoSender
+	}
+	if len(m.To) == 0 {
+		return ErrNoRecipients
+	}
+	if m.Text == "" && m.HTML == "" {
+		return ErrNoBody
+	}
+	return nil
+}
+
+// ExtraHeaders returns the additional headers of the message that a mailer should set, keyed by their canonical name.
+// Headers that would override those set from the fields of the message, or that have an invalid name, are omitted.
+func (m *Message) ExtraHeaders() map[string]string {
+	headers := make(map[string]string, len(m.Headers))
+	for key, value := range m.Headers {
+		if !validHeaderName(key) {
+			continue
+		}
+		key = textproto.CanonicalMIMEHeaderKey(key)
+		if slices.Contains(reservedHeaders, key) {
+			continue
+		}
+		headers[key] = value
+	}
+	return headers
+}
+
+// validHeaderName reports whether the name is a valid header field name, which consists of printable ASCII characters
+// other than colon (RFC 5322).
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range []byte(name) {
+		if c < '!' || c > '~' || c == ':' {
+			return false
+		}
+	}
+	return true
+}
+
+// LogMailer is a Mailer that will log messages rather than deliver them. It is useful for local development and
+// testing, where no real mail provider is available.
+type LogMailer struct{}
+
+// NewLogMailer creates a new LogMailer.
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+// Send will log the message.
+func (l *LogMailer) Send(ctx context.Context, msg *Message) error {
+	if err := msg.Valid(); err != nil {
+		return err
+	}
+	recipients := make([]string, 0, len(msg.To))
+	for _, to := range msg.To {
+		recipients = append(recipients, to.String())
+	}
+	attachments := make([]string, 0, len(msg.Attachments))
+	for _, attachment := range msg.Attachments {
+		attachments = append(attachments, attachment.Filename)
+	}
+	slogctx.FromCtx(ctx).Info("Email message.",
+		slog.String("from", msg.From.String()),
+		slog.Any("to", recipients),
+		slog.String("subject", msg.Subject),
+		slog.String("text", msg.Text),
+		slog.String("html", msg.HTML),
+		slog.Any("headers", msg.Headers),
+		slog.Any("attachments", attachments),
+	)
+	return nil
+}
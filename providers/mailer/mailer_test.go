@@ -0,0 +1,112 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package mailer_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/mail"
+	"strings"
+	"testing"
+
+	slogctx "github.com/veqryn/slog-context"
+
+	"github.com/immanent-tech/www-immanent-tech/providers/mailer"
+)
+
+func TestMessageValid(t *testing.T) {
+	from := &mail.Address{Address: "sender@example.com"}
+	to := []*mail.Address{{Address: "recipient@example.com"}}
+
+	tests := []struct {
+		name string
+		msg  *mailer.Message
+		want error
+	}{
+		{
+			name: "text body",
+			msg:  &mailer.Message{From: from, To: to, Text: "hello"},
+		},
+		{
+			name: "html body",
+			msg:  &mailer.Message{From: from, To: to, HTML: "<p>hello</p>"},
+		},
+		{
+			name: "no sender",
+			msg:  &mailer.Message{To: to, Text: "hello"},
+			want: mailer.ErrNoSender,
+		},
+		{
+			name: "no recipients",
+			msg:  &mailer.Message{From: from, Text: "hello"},
+			want: mailer.ErrNoRecipients,
+		},
+		{
+			name: "no body",
+			msg:  &mailer.Message{From: from, To: to, Subject: "hello"},
+			want: mailer.ErrNoBody,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.msg.Valid(); !errors.Is(err, tt.want) {
+				t.Errorf("Valid() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogMailerSend(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := slogctx.NewCtx(t.Context(), slog.New(slog.NewTextHandler(&buf, nil)))
+
+	err := mailer.NewLogMailer().Send(ctx, &mailer.Message{
+		From:        &mail.Address{Address: "sender@example.com"},
+		To:          []*mail.Address{{Address: "recipient@example.com"}},
+		Subject:     "Greetings",
+		Text:        "hello",
+		Attachments: []*mailer.Attachment{{Filename: "notes.txt", ContentType: "text/plain", Data: []byte("notes")}},
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	for _, want := range []string{"<recipient@example.com>", "subject=Greetings", "notes.txt"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("log output %q does not contain %q", buf.String(), want)
+		}
+	}
+	if err := mailer.NewLogMailer().Send(ctx, &mailer.Message{}); !errors.Is(err, mailer.ErrNoSender) {
+		t.Errorf("Send() of invalid message error = %v, want %v", err, mailer.ErrNoSender)
+	}
+}
+
+func TestMessageExtraHeaders(t *testing.T) {
+	msg := &mailer.Message{
+		Headers: map[string]string{
+			"auto-submitted":  "auto-replied",
+			"X-Submission-Id": "abc123",
+			"From":            "attacker@example.com",
+			"subject":         "Overridden",
+			"Message-ID":      "<forged@example.com>",
+			"Bad Header":      "value",
+			"Bad:Header":      "value",
+			"":                "value",
+		},
+	}
+	want := map[string]string{
+		"Auto-Submitted":  "auto-replied",
+		"X-Submission-Id": "abc123",
+	}
+
+	got := msg.ExtraHeaders()
+	if len(got) != len(want) {
+		t.Fatalf("ExtraHeaders() = %v, want %v", got, want)
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("ExtraHeaders()[%q] = %q, want %q", key, got[key], value)
+		}
+	}
+}
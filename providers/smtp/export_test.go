@@ -0,0 +1,14 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package smtp
+
+import "crypto/x509"
+
+// BuildMessage exports buildMessage for testing.
+var BuildMessage = buildMessage
+
+// SetRootCAs sets the certificate authorities used to verify the server certificate.
+func (m *Mailer) SetRootCAs(roots *x509.CertPool) {
+	m.rootCAs = roots
+}
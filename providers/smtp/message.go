@@ -0,0 +1,188 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package smtp
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/immanent-tech/www-immanent-tech/providers/mailer"
+)
+
+// base64LineLength is the maximum line length of base64 encoded attachments (RFC 2045).
+const base64LineLength = 76
+
+var headerSanitiser = strings.NewReplacer("\r", "", "\n", "")
+
+// buildMessage renders the message into RFC 5322 format, ready for sending over SMTP. If the message has both a text
+// and html body, a multipart/alternative body will be created. If the message has attachments, the body and the
+// attachments will be wrapped in a multipart/mixed message.
+func buildMessage(msg *mailer.Message, sender, replyTo *mail.Address) ([]byte, error) {
+	var buf bytes.Buffer
+
+	header := make(textproto.MIMEHeader)
+	header.Set("From", sender.String())
+	if replyTo != nil {
+		header.Set("Reply-To", replyTo.String())
+	}
+	recipients := make([]string, 0, len(msg.To))
+	for _, to := range msg.To {
+		recipients = append(recipients, to.String())
+	}
+	header.Set("To", strings.Join(recipients, ", "))
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header.Set("Date", time.Now().Format(time.RFC1123Z))
+	header.Set("Message-Id", newMessageID(sender))
+	header.Set("Mime-Version", "1.0")
+	for key, value := range msg.ExtraHeaders() {
+		header.Set(key, value)
+	}
+
+	bodyHeader, body, err := buildBody(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(msg.Attachments) == 0 {
+		maps.Copy(header, bodyHeader)
+		writeHeader(&buf, header)
+		buf.Write(body)
+		return buf.Bytes(), nil
+	}
+
+	writer := multipart.NewWriter(&buf)
+	header.Set("Content-Type", "multipart/mixed; boundary="+writer.Boundary())
+	writeHeader(&buf, header)
+	part, err := writer.CreatePart(bodyHeader)
+	if err != nil {
+		return nil, fmt.Errorf("create body part: %w", err)
+	}
+	if _, err := part.Write(body); err != nil {
+		return nil, fmt.Errorf("write body part: %w", err)
+	}
+	for _, attachment := range msg.Attachments {
+		if err := writeAttachment(writer, attachment); err != nil {
+			return nil, fmt.Errorf("write attachment %q: %w", attachment.Filename, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("close multipart: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// buildBody renders the text and/or html body of the message, returning the content headers of the body along with
+// the encoded body.
+func buildBody(msg *mailer.Message) (textproto.MIMEHeader, []byte, error) {
+	var buf bytes.Buffer
+	header := make(textproto.MIMEHeader)
+
+	switch {
+	case msg.Text != "" && msg.HTML != "":
+		writer := multipart.NewWriter(&buf)
+		header.Set("Content-Type", "multipart/alternative; boundary="+writer.Boundary())
+		if err := writePart(writer, "text/plain", msg.Text); err != nil {
+			return nil, nil, fmt.Errorf("write text part: %w", err)
+		}
+		if err := writePart(writer, "text/html", msg.HTML); err != nil {
+			return nil, nil, fmt.Errorf("write html part: %w", err)
+		}
+		if err := writer.Close(); err != nil {
+			return nil, nil, fmt.Errorf("close multipart: %w", err)
+		}
+	case msg.HTML != "":
+		header.Set("Content-Type", "text/html; charset=utf-8")
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		if err := writeQuotedPrintable(&buf, msg.HTML); err != nil {
+			return nil, nil, fmt.Errorf("write html body: %w", err)
+		}
+	default:
+		header.Set("Content-Type", "text/plain; charset=utf-8")
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		if err := writeQuotedPrintable(&buf, msg.Text); err != nil {
+			return nil, nil, fmt.Errorf("write text body: %w", err)
+		}
+	}
+
+	return header, buf.Bytes(), nil
+}
+
+// writeHeader writes the header in a stable order, followed by the blank line separating the header from the body.
+func writeHeader(w io.Writer, header textproto.MIMEHeader) {
+	for _, key := range slices.Sorted(maps.Keys(header)) {
+		for _, value := range header[key] {
+			fmt.Fprintf(w, "%s: %s\r\n", key, headerSanitiser.Replace(value))
+		}
+	}
+	fmt.Fprint(w, "\r\n")
+}
+
+// writePart writes a quoted-printable encoded part of the given content type to a multipart message.
+func writePart(writer *multipart.Writer, contentType, body string) error {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Type", contentType+"; charset=utf-8")
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return fmt.Errorf("create part: %w", err)
+	}
+	return writeQuotedPrintable(part, body)
+}
+
+// writeAttachment writes a base64 encoded attachment to a multipart message.
+func writeAttachment(writer *multipart.Writer, attachment *mailer.Attachment) error {
+	filename := headerSanitiser.Replace(attachment.Filename)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Type", mime.FormatMediaType(attachment.ContentType, map[string]string{"name": filename}))
+	header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	header.Set("Content-Transfer-Encoding", "base64")
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return fmt.Errorf("create part: %w", err)
+	}
+	encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+	for len(encoded) > base64LineLength {
+		if _, err := io.WriteString(part, encoded[:base64LineLength]+"\r\n"); err != nil {
+			return fmt.Errorf("write attachment: %w", err)
+		}
+		encoded = encoded[base64LineLength:]
+	}
+	if _, err := io.WriteString(part, encoded+"\r\n"); err != nil {
+		return fmt.Errorf("write attachment: %w", err)
+	}
+	return nil
+}
+
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return fmt.Errorf("encode body: %w", err)
+	}
+	if err := qp.Close(); err != nil {
+		return fmt.Errorf("close encoder: %w", err)
+	}
+	return nil
+}
+
+// newMessageID generates a unique Message-ID using the domain of the sender address.
+func newMessageID(sender *mail.Address) string {
+	domain := "localhost"
+	if _, host, found := strings.Cut(sender.Address, "@"); found {
+		domain = host
+	}
+	return "<" + rand.Text() + "@" + domain + ">"
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package smtp_test
+
+import (
+	"bytes"
+	"net/mail"
+	"testing"
+
+	"github.com/immanent-tech/www-immanent-tech/providers/mailer"
+	"github.com/immanent-tech/www-immanent-tech/providers/smtp"
+)
+
+func TestBuildMessageHeaders(t *testing.T) {
+	sender := &mail.Address{Name: "Website", Address: "website@example.com"}
+	replyTo := &mail.Address{Address: "visitor@example.com"}
+	msg := &mailer.Message{
+		From:    replyTo,
+		To:      []*mail.Address{{Address: "hello@example.com"}},
+		Subject: "Enquiry",
+		Text:    "hello",
+		Headers: map[string]string{
+			"X-Submission-Id": "abc123",
+			"From":            "attacker@example.com",
+			"To":              "victim@example.com",
+			"Subject":         "Overridden",
+			"Date":            "Thu, 01 Jan 1970 00:00:00 +0000",
+			"Reply-To":        "attacker@example.com",
+			"Bcc":             "victim@example.com",
+		},
+	}
+
+	data, err := smtp.BuildMessage(msg, sender, replyTo)
+	if err != nil {
+		t.Fatalf("BuildMessage() error = %v", err)
+	}
+	parsed, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+
+	want := map[string]string{
+		"From":            `"Website" <website@example.com>`,
+		"Reply-To":        "<visitor@example.com>",
+		"To":              "<hello@example.com>",
+		"Subject":         "Enquiry",
+		"X-Submission-Id": "abc123",
+		"Bcc":             "",
+	}
+	for key, value := range want {
+		if got := parsed.Header.Get(key); got != value {
+			t.Errorf("header %s = %q, want %q", key, got, value)
+		}
+	}
+	if values := parsed.Header["From"]; len(values) != 1 {
+		t.Errorf("From header set %d times, want once", len(values))
+	}
+	if date, err := parsed.Header.Date(); err != nil || date.Year() == 1970 {
+		t.Errorf("Date header = %v (%v), want the current date", date, err)
+	}
+}
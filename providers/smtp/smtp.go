@@ -0,0 +1,211 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+// Package smtp provides a mailer.Mailer that delivers email to an SMTP server.
+package smtp
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/immanent-tech/go-base/config"
+	"github.com/immanent-tech/go-base/validation"
+
+	"github.com/immanent-tech/www-immanent-tech/providers/mailer"
+)
+
+const (
+	configPrefix = "SMTP_"
+)
+
+const (
+	// TLSModeNone will not use TLS when connecting to the SMTP server.
+	TLSModeNone = "none"
+	// TLSModeStartTLS will connect to the SMTP server in plain text and upgrade the connection with STARTTLS.
+	TLSModeStartTLS = "starttls"
+	// TLSModeImplicit will connect to the SMTP server over TLS.
+	TLSModeImplicit = "tls"
+)
+
+var (
+	// ErrNoStartTLS indicates the SMTP server does not support STARTTLS when it was required.
+	ErrNoStartTLS = errors.New("server does not support STARTTLS")
+	// ErrNoAuth indicates the SMTP server does not support AUTH when credentials were configured.
+	ErrNoAuth = errors.New("server does not support AUTH")
+)
+
+// Config contains the SMTP configuration options.
+type Config struct {
+	Host     string          `koanf:"host"     validate:"required,hostname|fqdn|ip"`
+	Port     uint64          `koanf:"port"     validate:"required,port"`
+	Username string          `koanf:"username" validate:"required_with=Password"`
+	Password string          `koanf:"password" validate:"required_with=Username"`
+	TLSMode  string          `koanf:"tlsmode"  validate:"required,oneof=none starttls tls"`
+	From     string          `koanf:"from"     validate:"required,email"`
+	Timeout  config.Duration `koanf:"timeout"  validate:"omitempty"`
+}
+
+var cfg = Config{
+	Port:    587,
+	TLSMode: TLSModeStartTLS,
+	Timeout: config.NewDuration(30 * time.Second),
+}
+
+// loadConfig loads the SMTP configuration and ensures this is only done
+// one time, no matter how many times it is called.
+var loadConfig = sync.OnceValue(func() error {
+	if err := config.Load(configPrefix, &cfg); err != nil {
+		return fmt.Errorf("load config from environment: %w", err)
+	}
+	if err := validation.Validate.Struct(cfg); err != nil {
+		return fmt.Errorf("validate config: %w", err)
+	}
+	return nil
+})
+
+// Mailer is a mailer.Mailer that sends email through an SMTP server.
+type Mailer struct {
+	cfg Config
+	// from is the address messages are sent from. Visitors' addresses cannot be used as the sender, as mail from their
+	// domains would not pass SPF or DMARC checks.
+	from *mail.Address
+	// rootCAs are the certificate authorities used to verify the server certificate. If nil, the system roots are
+	// used.
+	rootCAs *x509.CertPool
+}
+
+// New creates a new SMTP Mailer. The SMTP config will be loaded from the environment.
+func New() (*Mailer, error) {
+	if err := loadConfig(); err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	return NewWithConfig(cfg)
+}
+
+// NewWithConfig creates a new SMTP Mailer with the given config.
+func NewWithConfig(smtpCfg Config) (*Mailer, error) {
+	if err := validation.Validate.Struct(smtpCfg); err != nil {
+		return nil, fmt.Errorf("validate config: %w", err)
+	}
+	from, err := mail.ParseAddress(smtpCfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("parse from address: %w", err)
+	}
+	return &Mailer{cfg: smtpCfg, from: from}, nil
+}
+
+// Send will deliver the message to the SMTP server. The message is sent from the configured from address and the
+// original sender is set as the Reply-To address.
+func (m *Mailer) Send(ctx context.Context, msg *mailer.Message) error {
+	if err := msg.Valid(); err != nil {
+		return fmt.Errorf("invalid message: %w", err)
+	}
+
+	data, err := buildMessage(msg, m.from, msg.From)
+	if err != nil {
+		return fmt.Errorf("build message: %w", err)
+	}
+
+	conn, err := m.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("connect to server: %w", err)
+	}
+	client, err := smtp.NewClient(conn, m.cfg.Host)
+	if err != nil {
+		conn.Close() //nolint:errcheck
+		return fmt.Errorf("create client: %w", err)
+	}
+	defer client.Close() //nolint:errcheck
+
+	if m.cfg.TLSMode == TLSModeStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return ErrNoStartTLS
+		}
+		if err := client.StartTLS(m.tlsConfig()); err != nil {
+			return fmt.Errorf("starttls: %w", err)
+		}
+	}
+	if m.cfg.Username != "" {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return ErrNoAuth
+		}
+		if err := client.Auth(smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+
+	if err := client.Mail(m.from.Address); err != nil {
+		return fmt.Errorf("mail from: %w", err)
+	}
+	for _, to := range msg.To {
+		if err := client.Rcpt(to.Address); err != nil {
+			return fmt.Errorf("rcpt to %s: %w", to.Address, err)
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("data: %w", err)
+	}
+	if _, err := writer.Write(data); err != nil {
+		return fmt.Errorf("write message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("close message: %w", err)
+	}
+	if err := client.Quit(); err != nil {
+		return fmt.Errorf("quit: %w", err)
+	}
+	return nil
+}
+
+// dial opens a connection to the SMTP server, using TLS if configured for implicit TLS. The connection deadline is set
+// from the context deadline or the configured timeout, whichever is sooner.
+func (m *Mailer) dial(ctx context.Context) (net.Conn, error) {
+	addr := net.JoinHostPort(m.cfg.Host, strconv.FormatUint(m.cfg.Port, 10))
+	dialer := &net.Dialer{Timeout: m.cfg.Timeout.Duration}
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	if m.cfg.TLSMode == TLSModeImplicit {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: m.tlsConfig()}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", addr, err)
+	}
+
+	deadline, ok := ctx.Deadline()
+	if timeout := m.cfg.Timeout.Duration; timeout > 0 {
+		if d := time.Now().Add(timeout); !ok || d.Before(deadline) {
+			deadline, ok = d, true
+		}
+	}
+	if ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			conn.Close() //nolint:errcheck
+			return nil, fmt.Errorf("set deadline: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+func (m *Mailer) tlsConfig() *tls.Config {
+	return &tls.Config{
+		ServerName: m.cfg.Host,
+		RootCAs:    m.rootCAs,
+		MinVersion: tls.VersionTLS12,
+	}
+}
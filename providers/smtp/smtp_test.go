@@ -0,0 +1,502 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package smtp_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"errors"
+	"io"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/immanent-tech/go-base/config"
+
+	"github.com/immanent-tech/www-immanent-tech/providers/mailer"
+	"github.com/immanent-tech/www-immanent-tech/providers/smtp"
+)
+
+const (
+	testUsername = "website"
+	testPassword = "secret"
+)
+
+// envelope is a message received by the stub server.
+type envelope struct {
+	from string
+	to   []string
+	data []byte
+	// tls reports whether the message was received over TLS.
+	tls bool
+	// username is the user that authenticated before the message was sent, if any.
+	username string
+}
+
+// stubServer is a minimal in-process SMTP server that supports STARTTLS, implicit TLS and AUTH PLAIN.
+type stubServer struct {
+	listener  net.Listener
+	tlsConfig *tls.Config
+	startTLS  bool
+	auth      bool
+
+	mu       sync.Mutex
+	received []*envelope
+}
+
+// newStubServer starts a stub server on a local port. If implicitTLS is set, connections are accepted over TLS. The
+// startTLS and auth flags control whether the server advertises the STARTTLS and AUTH extensions.
+func newStubServer(t *testing.T, implicitTLS, startTLS, auth bool) (*stubServer, *x509.CertPool) {
+	t.Helper()
+	tlsConfig, roots := newTestCertificate(t)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	if implicitTLS {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+	server := &stubServer{
+		listener:  listener,
+		tlsConfig: tlsConfig,
+		startTLS:  startTLS,
+		auth:      auth,
+	}
+	t.Cleanup(func() {
+		listener.Close()
+	})
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server, roots
+}
+
+// config returns a mailer config for connecting to the server with the given TLS mode.
+func (s *stubServer) config(t *testing.T, tlsMode string) smtp.Config {
+	t.Helper()
+	_, port, err := net.SplitHostPort(s.listener.Addr().String())
+	if err != nil {
+		t.Fatalf("split address: %v", err)
+	}
+	portNum, err := strconv.ParseUint(port, 10, 64)
+	if err != nil {
+		t.Fatalf("parse port: %v", err)
+	}
+	return smtp.Config{
+		Host:    "127.0.0.1",
+		Port:    portNum,
+		TLSMode: tlsMode,
+		From:    "website@example.com",
+		Timeout: config.NewDuration(5 * time.Second),
+	}
+}
+
+func (s *stubServer) messages() []*envelope {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.received
+}
+
+//nolint:cyclop // Dispatches each SMTP command.
+func (s *stubServer) serve(conn net.Conn) {
+	defer func() {
+		conn.Close()
+	}()
+	text := textproto.NewConn(conn)
+	_, isTLS := conn.(*tls.Conn)
+	env := &envelope{}
+
+	text.PrintfLine("220 localhost ESMTP")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			extensions := []string{"localhost"}
+			if s.startTLS && !isTLS {
+				extensions = append(extensions, "STARTTLS")
+			}
+			if s.auth {
+				extensions = append(extensions, "AUTH PLAIN")
+			}
+			for i, extension := range extensions {
+				separator := "-"
+				if i == len(extensions)-1 {
+					separator = " "
+				}
+				text.PrintfLine("250%s%s", separator, extension)
+			}
+		case "STARTTLS":
+			text.PrintfLine("220 ready to start TLS")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn, isTLS = tlsConn, true
+			text = textproto.NewConn(conn)
+		case "AUTH":
+			mechanism, response, _ := strings.Cut(arg, " ")
+			decoded, _ := base64.StdEncoding.DecodeString(response)
+			fields := bytes.Split(decoded, []byte{0})
+			if mechanism != "PLAIN" || len(fields) != 3 ||
+				string(fields[1]) != testUsername || string(fields[2]) != testPassword {
+				text.PrintfLine("535 authentication failed")
+				continue
+			}
+			env.username = string(fields[1])
+			text.PrintfLine("235 authenticated")
+		case "MAIL":
+			env.from = parsePath(arg, "FROM:")
+			text.PrintfLine("250 ok")
+		case "RCPT":
+			env.to = append(env.to, parsePath(arg, "TO:"))
+			text.PrintfLine("250 ok")
+		case "DATA":
+			text.PrintfLine("354 end data with <CR><LF>.<CR><LF>")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			env.data, env.tls = data, isTLS
+			s.mu.Lock()
+			s.received = append(s.received, env)
+			s.mu.Unlock()
+			env = &envelope{username: env.username}
+			text.PrintfLine("250 queued")
+		case "QUIT":
+			text.PrintfLine("221 bye")
+			return
+		default:
+			text.PrintfLine("502 command not implemented")
+		}
+	}
+}
+
+// parsePath returns the address from a MAIL or RCPT argument, such as "FROM:<user@example.com> BODY=8BITMIME".
+func parsePath(arg, prefix string) string {
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return ""
+	}
+	path, _, _ := strings.Cut(arg[len(prefix):], " ")
+	return strings.Trim(path, "<>")
+}
+
+// newTestCertificate creates a self-signed certificate for 127.0.0.1, returning the server TLS config and a pool
+// containing the certificate for clients to trust.
+func newTestCertificate(t *testing.T) (*tls.Config, *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		MinVersion:   tls.VersionTLS12,
+	}, roots
+}
+
+func newTestMailer(t *testing.T, cfg smtp.Config, roots *x509.CertPool) *smtp.Mailer {
+	t.Helper()
+	m, err := smtp.NewWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+	m.SetRootCAs(roots)
+	return m
+}
+
+func newTestMessage() *mailer.Message {
+	return &mailer.Message{
+		From:    &mail.Address{Address: "visitor@example.com"},
+		To:      []*mail.Address{{Address: "hello@example.com"}, {Address: "support@example.com"}},
+		Subject: "Enquiry",
+		Text:    "hello",
+	}
+}
+
+func TestSend(t *testing.T) {
+	tests := []struct {
+		name        string
+		implicitTLS bool
+		startTLS    bool
+		auth        bool
+		tlsMode     string
+		credentials bool
+		wantTLS     bool
+		wantErr     error
+	}{
+		{
+			name:        "starttls with auth",
+			startTLS:    true,
+			auth:        true,
+			tlsMode:     smtp.TLSModeStartTLS,
+			credentials: true,
+			wantTLS:     true,
+		},
+		{
+			name:        "implicit tls with auth",
+			implicitTLS: true,
+			auth:        true,
+			tlsMode:     smtp.TLSModeImplicit,
+			credentials: true,
+			wantTLS:     true,
+		},
+		{
+			name:    "plain text without auth",
+			tlsMode: smtp.TLSModeNone,
+		},
+		{
+			name:    "starttls not supported",
+			auth:    true,
+			tlsMode: smtp.TLSModeStartTLS,
+			wantErr: smtp.ErrNoStartTLS,
+		},
+		{
+			name:        "auth not supported",
+			startTLS:    true,
+			tlsMode:     smtp.TLSModeStartTLS,
+			credentials: true,
+			wantErr:     smtp.ErrNoAuth,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, roots := newStubServer(t, tt.implicitTLS, tt.startTLS, tt.auth)
+			cfg := server.config(t, tt.tlsMode)
+			if tt.credentials {
+				cfg.Username, cfg.Password = testUsername, testPassword
+			}
+
+			err := newTestMailer(t, cfg, roots).Send(t.Context(), newTestMessage())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Send() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(server.messages()) != 0 {
+					t.Errorf("server received a message after an error")
+				}
+				return
+			}
+
+			messages := server.messages()
+			if len(messages) != 1 {
+				t.Fatalf("server received %d messages, want 1", len(messages))
+			}
+			received := messages[0]
+			if received.tls != tt.wantTLS {
+				t.Errorf("received over TLS = %t, want %t", received.tls, tt.wantTLS)
+			}
+			if tt.credentials && received.username != testUsername {
+				t.Errorf("authenticated as %q, want %q", received.username, testUsername)
+			}
+			if received.from != "website@example.com" {
+				t.Errorf("MAIL FROM = %q, want %q", received.from, "website@example.com")
+			}
+			if strings.Join(received.to, ",") != "hello@example.com,support@example.com" {
+				t.Errorf("RCPT TO = %v", received.to)
+			}
+		})
+	}
+}
+
+func TestSendAuthFailed(t *testing.T) {
+	server, roots := newStubServer(t, false, true, true)
+	cfg := server.config(t, smtp.TLSModeStartTLS)
+	cfg.Username, cfg.Password = testUsername, "wrong"
+
+	if err := newTestMailer(t, cfg, roots).Send(t.Context(), newTestMessage()); err == nil {
+		t.Fatal("Send() with wrong password succeeded")
+	}
+	if len(server.messages()) != 0 {
+		t.Error("server received a message without authentication")
+	}
+}
+
+func TestSendUntrustedCertificate(t *testing.T) {
+	server, _ := newStubServer(t, true, false, false)
+
+	err := newTestMailer(t, server.config(t, smtp.TLSModeImplicit), nil).Send(t.Context(), newTestMessage())
+	if err == nil {
+		t.Fatal("Send() to a server with an untrusted certificate succeeded")
+	}
+}
+
+func TestNewWithConfigFromAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    string
+		wantErr bool
+	}{
+		{name: "valid", from: "website@example.com"},
+		{name: "missing", from: "", wantErr: true},
+		{name: "invalid", from: "website", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := smtp.NewWithConfig(smtp.Config{
+				Host:    "127.0.0.1",
+				Port:    587,
+				TLSMode: smtp.TLSModeStartTLS,
+				From:    tt.from,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewWithConfig() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSendFromAddress(t *testing.T) {
+	server, roots := newStubServer(t, false, true, false)
+	cfg := server.config(t, smtp.TLSModeStartTLS)
+
+	if err := newTestMailer(t, cfg, roots).Send(t.Context(), newTestMessage()); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	received := server.messages()[0]
+	if received.from != "website@example.com" {
+		t.Errorf("MAIL FROM = %q, want the configured from address", received.from)
+	}
+	msg, err := mail.ReadMessage(bytes.NewReader(received.data))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	if got := msg.Header.Get("Reply-To"); got != "<visitor@example.com>" {
+		t.Errorf("Reply-To = %q, want the original sender", got)
+	}
+}
+
+func TestSendMultipartMixed(t *testing.T) {
+	server, roots := newStubServer(t, false, true, false)
+	msg := newTestMessage()
+	msg.HTML = "<p>hello</p>"
+	attachment := bytes.Repeat([]byte("attachment data "), 16)
+	msg.Attachments = []*mailer.Attachment{{Filename: "notes.txt", ContentType: "text/plain", Data: attachment}}
+
+	err := newTestMailer(t, server.config(t, smtp.TLSModeStartTLS), roots).Send(t.Context(), msg)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	parsed, err := mail.ReadMessage(bytes.NewReader(server.messages()[0].data))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+
+	parts := readParts(t, parsed.Header.Get("Content-Type"), parsed.Body, "multipart/mixed")
+	if len(parts) != 2 {
+		t.Fatalf("multipart/mixed has %d parts, want 2", len(parts))
+	}
+
+	alternatives := readParts(t, parts[0].Header.Get("Content-Type"), parts[0], "multipart/alternative")
+	if len(alternatives) != 2 {
+		t.Fatalf("multipart/alternative has %d parts, want 2", len(alternatives))
+	}
+	for i, want := range []struct{ mediaType, body string }{
+		{mediaType: "text/plain", body: "hello"},
+		{mediaType: "text/html", body: "<p>hello</p>"},
+	} {
+		mediaType, _, _ := mime.ParseMediaType(alternatives[i].Header.Get("Content-Type"))
+		if mediaType != want.mediaType {
+			t.Errorf("alternative %d is %q, want %q", i, mediaType, want.mediaType)
+		}
+		// The multipart reader transparently decodes quoted-printable parts.
+		body, _ := io.ReadAll(alternatives[i])
+		if string(body) != want.body {
+			t.Errorf("alternative %d body = %q, want %q", i, body, want.body)
+		}
+	}
+
+	if got := parts[1].FileName(); got != "notes.txt" {
+		t.Errorf("attachment filename = %q, want %q", got, "notes.txt")
+	}
+	encoded, _ := io.ReadAll(parts[1])
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(encoded), "\r\n", ""))
+	if err != nil {
+		t.Fatalf("decode attachment: %v", err)
+	}
+	if !bytes.Equal(decoded, attachment) {
+		t.Errorf("attachment data = %q, want %q", decoded, attachment)
+	}
+}
+
+// part is a buffered part of a multipart body.
+type part struct {
+	*bytes.Reader
+	Header   textproto.MIMEHeader
+	filename string
+}
+
+func (p *part) FileName() string {
+	return p.filename
+}
+
+// readParts reads the parts of a multipart body, checking it has the expected media type.
+func readParts(t *testing.T, contentType string, body io.Reader, wantMediaType string) []*part {
+	t.Helper()
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != wantMediaType {
+		t.Fatalf("content type = %q (%v), want %q", contentType, err, wantMediaType)
+	}
+	reader := multipart.NewReader(body, params["boundary"])
+	var parts []*part
+	for {
+		p, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return parts
+		}
+		if err != nil {
+			t.Fatalf("read part: %v", err)
+		}
+		data, err := io.ReadAll(p)
+		if err != nil {
+			t.Fatalf("read part body: %v", err)
+		}
+		parts = append(parts, &part{Reader: bytes.NewReader(data), Header: p.Header, filename: p.FileName()})
+	}
+}
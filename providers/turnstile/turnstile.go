@@ -0,0 +1,155 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+// Package turnstile provides server-side verification of Cloudflare Turnstile tokens.
+//
+// https://developers.cloudflare.com/turnstile/get-started/server-side-validation/
+package turnstile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/immanent-tech/go-base/config"
+	"github.com/immanent-tech/go-base/validation"
+)
+
+const (
+	configPrefix = "TURNSTILE_"
+	// ResponseField is the name of the form field in which the Turnstile widget places its token.
+	ResponseField = "cf-turnstile-response"
+	// maxTokenLength is the maximum length of a Turnstile token.
+	maxTokenLength = 2048
+	// errCodeDuplicate is the error code returned when a token has already been verified or has expired.
+	errCodeDuplicate = "timeout-or-duplicate"
+)
+
+var (
+	// ErrMissingToken indicates no token was provided for verification.
+	ErrMissingToken = errors.New("missing token")
+	// ErrInvalidToken indicates the token failed verification.
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrReplayedToken indicates the token has already been verified or has expired.
+	ErrReplayedToken = errors.New("token already used or expired")
+)
+
+// Config contains the Turnstile configuration options.
+type Config struct {
+	// Secret is the Turnstile secret key for the site.
+	Secret string `koanf:"secret" validate:"required"`
+	// VerifyURL is the siteverify endpoint used to validate tokens.
+	VerifyURL string `koanf:"verifyurl" validate:"required,url"`
+	// Timeout is the maximum time to wait for a verification response.
+	Timeout config.Duration `koanf:"timeout" validate:"omitempty"`
+}
+
+var cfg = Config{
+	VerifyURL: "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+	Timeout:   config.NewDuration(10 * time.Second),
+}
+
+// loadConfig loads the Turnstile configuration and ensures this is only done
+// one time, no matter how many times it is called.
+var loadConfig = sync.OnceValue(func() error {
+	if err := config.Load(configPrefix, &cfg); err != nil {
+		return fmt.Errorf("load config from environment: %w", err)
+	}
+	if err := validation.Validate.Struct(cfg); err != nil {
+		return fmt.Errorf("validate config: %w", err)
+	}
+	return nil
+})
+
+// Response is the response from the siteverify endpoint.
+type Response struct {
+	Success     bool      `json:"success"`
+	ChallengeTS time.Time `json:"challenge_ts"`
+	Hostname    string    `json:"hostname"`
+	ErrorCodes  []string  `json:"error-codes"`
+	Action      string    `json:"action"`
+	CData       string    `json:"cdata"`
+}
+
+// Verifier verifies Turnstile tokens against the siteverify endpoint.
+type Verifier struct {
+	client    *http.Client
+	secret    string
+	verifyURL string
+}
+
+// New creates a new Verifier. The Turnstile config will be loaded from the environment.
+func New() (*Verifier, error) {
+	if err := loadConfig(); err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	return NewWithConfig(cfg)
+}
+
+// NewWithConfig creates a new Verifier with the given config.
+func NewWithConfig(turnstileCfg Config) (*Verifier, error) {
+	if err := validation.Validate.Struct(turnstileCfg); err != nil {
+		return nil, fmt.Errorf("validate config: %w", err)
+	}
+	return &Verifier{
+		client:    &http.Client{Timeout: turnstileCfg.Timeout.Duration},
+		secret:    turnstileCfg.Secret,
+		verifyURL: turnstileCfg.VerifyURL,
+	}, nil
+}
+
+// Verify will verify the given token, optionally including the IP address of the client that submitted it. A nil error
+// indicates a valid token. Tokens that have already been verified will return ErrReplayedToken and other invalid
+// tokens will return ErrInvalidToken.
+func (v *Verifier) Verify(ctx context.Context, token, remoteIP string) (*Response, error) {
+	if token == "" {
+		return nil, ErrMissingToken
+	}
+	if len(token) > maxTokenLength {
+		return nil, fmt.Errorf("%w: token too long", ErrInvalidToken)
+	}
+
+	values := url.Values{}
+	values.Set("secret", v.secret)
+	values.Set("response", token)
+	if remoteIP != "" {
+		values.Set("remoteip", remoteIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.verifyURL, strings.NewReader(values.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send request: %w", err)
+	}
+	defer res.Body.Close() //nolint:errcheck
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("siteverify returned status %s", res.Status)
+	}
+
+	response := &Response{}
+	if err := json.NewDecoder(res.Body).Decode(response); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	switch {
+	case response.Success:
+		return response, nil
+	case slices.Contains(response.ErrorCodes, errCodeDuplicate):
+		return response, ErrReplayedToken
+	default:
+		return response, fmt.Errorf("%w: %s", ErrInvalidToken, strings.Join(response.ErrorCodes, ", "))
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package turnstile_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/immanent-tech/www-immanent-tech/providers/turnstile"
+)
+
+const testSecret = "secret"
+
+// siteverify is a stub siteverify endpoint, which responds with the given status and body and records the form
+// values of each request.
+type siteverify struct {
+	status int
+	body   string
+
+	mu       sync.Mutex
+	requests []url.Values
+}
+
+func (s *siteverify) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	if err := req.ParseForm(); err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	s.requests = append(s.requests, req.PostForm)
+	s.mu.Unlock()
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(s.status)
+	res.Write([]byte(s.body))
+}
+
+func newTestVerifier(t *testing.T, endpoint *siteverify) *turnstile.Verifier {
+	t.Helper()
+	server := httptest.NewServer(endpoint)
+	t.Cleanup(server.Close)
+	verifier, err := turnstile.NewWithConfig(turnstile.Config{
+		Secret:    testSecret,
+		VerifyURL: server.URL,
+	})
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+	return verifier
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name         string
+		token        string
+		status       int
+		body         string
+		wantErr      error
+		wantErrAny   bool
+		wantRequests int
+	}{
+		{
+			name:         "valid token",
+			token:        "token",
+			status:       http.StatusOK,
+			body:         `{"success":true,"hostname":"immanent.tech"}`,
+			wantRequests: 1,
+		},
+		{
+			name:    "missing token",
+			status:  http.StatusOK,
+			body:    `{"success":true}`,
+			wantErr: turnstile.ErrMissingToken,
+		},
+		{
+			name:    "token too long",
+			token:   strings.Repeat("a", 2049),
+			status:  http.StatusOK,
+			body:    `{"success":true}`,
+			wantErr: turnstile.ErrInvalidToken,
+		},
+		{
+			name:         "invalid token",
+			token:        "token",
+			status:       http.StatusOK,
+			body:         `{"success":false,"error-codes":["invalid-input-response"]}`,
+			wantErr:      turnstile.ErrInvalidToken,
+			wantRequests: 1,
+		},
+		{
+			name:         "replayed token",
+			token:        "token",
+			status:       http.StatusOK,
+			body:         `{"success":false,"error-codes":["timeout-or-duplicate"]}`,
+			wantErr:      turnstile.ErrReplayedToken,
+			wantRequests: 1,
+		},
+		{
+			name:         "siteverify error",
+			token:        "token",
+			status:       http.StatusInternalServerError,
+			body:         `{}`,
+			wantErrAny:   true,
+			wantRequests: 1,
+		},
+		{
+			name:         "malformed response",
+			token:        "token",
+			status:       http.StatusOK,
+			body:         `not json`,
+			wantErrAny:   true,
+			wantRequests: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &siteverify{status: tt.status, body: tt.body}
+			_, err := newTestVerifier(t, endpoint).Verify(t.Context(), tt.token, "192.0.2.1")
+
+			switch {
+			case tt.wantErrAny:
+				// Failures of the endpoint itself must not be mistaken for a rejected token.
+				if err == nil || errors.Is(err, turnstile.ErrInvalidToken) || errors.Is(err, turnstile.ErrReplayedToken) {
+					t.Errorf("Verify() error = %v, want an error other than a rejected token", err)
+				}
+			case !errors.Is(err, tt.wantErr):
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(endpoint.requests) != tt.wantRequests {
+				t.Errorf("siteverify received %d requests, want %d", len(endpoint.requests), tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestVerifyRequest(t *testing.T) {
+	endpoint := &siteverify{status: http.StatusOK, body: `{"success":true,"action":"contact"}`}
+	response, err := newTestVerifier(t, endpoint).Verify(t.Context(), "token", "192.0.2.1")
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if response.Action != "contact" {
+		t.Errorf("response action = %q, want %q", response.Action, "contact")
+	}
+
+	want := url.Values{"secret": {testSecret}, "response": {"token"}, "remoteip": {"192.0.2.1"}}
+	got, _ := json.Marshal(endpoint.requests[0])
+	if wantJSON, _ := json.Marshal(want); string(got) != string(wantJSON) {
+		t.Errorf("siteverify request = %s, want %s", got, wantJSON)
+	}
+}
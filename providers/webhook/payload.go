@@ -0,0 +1,126 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// maxDetailsLength is the maximum length of the details included in chat messages. Longer details are truncated,
+	// as chat services limit message length.
+	maxDetailsLength = 1000
+	// discordColour is the colour of the embed in Discord messages.
+	discordColour = 0x6d28d9
+)
+
+// slackEscaper escapes the control characters of Slack message formatting.
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// slackMessage is the payload of a Slack incoming webhook.
+//
+// https://docs.slack.dev/messaging/sending-messages-using-incoming-webhooks
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+// discordMessage is the payload of a Discord incoming webhook.
+//
+// https://discord.com/developers/docs/resources/webhook#execute-webhook
+type discordMessage struct {
+	Content         string                 `json:"content"`
+	Embeds          []discordEmbed         `json:"embeds"`
+	AllowedMentions discordAllowedMentions `json:"allowed_mentions"`
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Colour      int            `json:"color"`
+	Fields      []discordField `json:"fields"`
+	Timestamp   string         `json:"timestamp"`
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+// discordAllowedMentions controls which mentions in a message notify users. Submissions are untrusted, so no
+// mentions are allowed.
+type discordAllowedMentions struct {
+	Parse []string `json:"parse"`
+}
+
+// encode encodes the event in the payload shape of the given format.
+func encode(format Format, event *Event) ([]byte, error) {
+	var payload any
+	switch format {
+	case FormatSlack:
+		payload = slackPayload(event)
+	case FormatDiscord:
+		payload = discordPayload(event)
+	case FormatJSON, "":
+		payload = event
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("marshal payload: %w", err)
+	}
+	return data, nil
+}
+
+func slackPayload(event *Event) *slackMessage {
+	var text strings.Builder
+	fmt.Fprintf(&text, "*New contact submission* %s\n", slackEscaper.Replace(event.Reference))
+	if event.Category != "" {
+		fmt.Fprintf(&text, "*Category:* %s\n", slackEscaper.Replace(event.Category))
+	}
+	fmt.Fprintf(&text, "*From:* %s\n", slackEscaper.Replace(event.ContactEmail))
+	if len(event.Attachments) > 0 {
+		fmt.Fprintf(&text, "*Attachments:* %s\n", slackEscaper.Replace(strings.Join(event.Attachments, ", ")))
+	}
+	for line := range strings.Lines(truncate(event.Details)) {
+		text.WriteString("> " + slackEscaper.Replace(line))
+	}
+	return &slackMessage{Text: text.String()}
+}
+
+func discordPayload(event *Event) *discordMessage {
+	fields := []discordField{
+		{Name: "Reference", Value: event.Reference, Inline: true},
+		{Name: "From", Value: event.ContactEmail, Inline: true},
+	}
+	if event.Category != "" {
+		fields = append(fields, discordField{Name: "Category", Value: event.Category, Inline: true})
+	}
+	if len(event.Attachments) > 0 {
+		fields = append(fields, discordField{Name: "Attachments", Value: strings.Join(event.Attachments, ", ")})
+	}
+	return &discordMessage{
+		Content: "New contact submission",
+		Embeds: []discordEmbed{{
+			Title:       event.Reference,
+			Description: truncate(event.Details),
+			Colour:      discordColour,
+			Fields:      fields,
+			Timestamp:   event.CreatedAt.Format(time.RFC3339),
+		}},
+		AllowedMentions: discordAllowedMentions{Parse: []string{}},
+	}
+}
+
+// truncate shortens the details to the maximum length included in chat messages.
+func truncate(details string) string {
+	if runes := []rune(details); len(runes) > maxDetailsLength {
+		return string(runes[:maxDetailsLength]) + "…"
+	}
+	return details
+}
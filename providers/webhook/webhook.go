@@ -0,0 +1,269 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+// Package webhook provides notification of contact form submissions to webhooks. Events can be sent as generic JSON,
+// or in the payload shapes expected by Slack and Discord incoming webhooks. Requests to endpoints with a secret are
+// signed with HMAC-SHA256.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/immanent-tech/go-base/config"
+	"github.com/immanent-tech/go-base/validation"
+)
+
+const (
+	configPrefix = "WEBHOOK_"
+	// EventSubmission is the type of event sent for a contact form submission.
+	EventSubmission = "contact.submission"
+	// HeaderSignature is the header containing the signature of a signed request.
+	HeaderSignature = "X-Webhook-Signature"
+	// HeaderTimestamp is the header containing the unix timestamp of a signed request, which is included in the
+	// signature.
+	HeaderTimestamp = "X-Webhook-Timestamp"
+)
+
+// errRetryable indicates a request failed in a way that may succeed if retried.
+var errRetryable = errors.New("retryable")
+
+// Format is the payload shape of an endpoint.
+type Format string
+
+const (
+	// FormatJSON sends the event as generic JSON.
+	FormatJSON Format = "json"
+	// FormatSlack sends the event as a Slack incoming webhook message.
+	FormatSlack Format = "slack"
+	// FormatDiscord sends the event as a Discord incoming webhook message.
+	FormatDiscord Format = "discord"
+)
+
+// Config contains the webhook configuration options.
+type Config struct {
+	// Path is the location of a JSON file containing an array of endpoints that are notified of all events.
+	Path string `koanf:"path" validate:"omitempty,file"`
+	// Timeout is the maximum time to wait for a webhook to respond.
+	Timeout config.Duration `koanf:"timeout" validate:"omitempty"`
+	// MaxAttempts is the number of times a request is attempted before giving up.
+	MaxAttempts int `koanf:"maxattempts" validate:"min=1,max=10"`
+	// Backoff is the delay before retrying a failed request, which doubles with each attempt.
+	Backoff config.Duration `koanf:"backoff" validate:"omitempty"`
+}
+
+var cfg = Config{
+	Timeout:     config.NewDuration(10 * time.Second),
+	MaxAttempts: 3,
+	Backoff:     config.NewDuration(time.Second),
+}
+
+// loadConfig loads the webhook configuration and ensures this is only done
+// one time, no matter how many times it is called.
+var loadConfig = sync.OnceValue(func() error {
+	if err := config.Load(configPrefix, &cfg); err != nil {
+		return fmt.Errorf("load config from environment: %w", err)
+	}
+	if err := validation.Validate.Struct(cfg); err != nil {
+		return fmt.Errorf("validate config: %w", err)
+	}
+	return nil
+})
+
+// Endpoint is a webhook that is notified of events.
+type Endpoint struct {
+	// URL is the address of the webhook.
+	URL string `json:"url" validate:"required,url"`
+	// Format is the payload shape expected by the webhook. Defaults to FormatJSON.
+	Format Format `json:"format,omitempty" validate:"omitempty,oneof=json slack discord"`
+	// Secret is an optional key with which requests to the webhook are signed.
+	Secret string `json:"secret,omitempty"`
+}
+
+// Event is the payload sent to a webhook.
+type Event struct {
+	Type         string    `json:"type"`
+	Reference    string    `json:"reference"`
+	Category     string    `json:"category,omitempty"`
+	ContactEmail string    `json:"contact_email"`
+	Details      string    `json:"details"`
+	Attachments  []string  `json:"attachments,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Notifier sends events to webhooks.
+type Notifier struct {
+	client      *http.Client
+	endpoints   []*Endpoint
+	maxAttempts int
+	backoff     time.Duration
+	// background tracks the notifications sent in the background, so that they can be waited for on shutdown.
+	background sync.WaitGroup
+}
+
+// New creates a new Notifier. The webhook config will be loaded from the environment.
+func New() (*Notifier, error) {
+	if err := loadConfig(); err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	return NewWithConfig(cfg)
+}
+
+// NewWithConfig creates a new Notifier with the given config.
+func NewWithConfig(webhookCfg Config) (*Notifier, error) {
+	if err := validation.Validate.Struct(webhookCfg); err != nil {
+		return nil, fmt.Errorf("validate config: %w", err)
+	}
+	notifier := &Notifier{
+		client:      &http.Client{Timeout: webhookCfg.Timeout.Duration},
+		maxAttempts: webhookCfg.MaxAttempts,
+		backoff:     webhookCfg.Backoff.Duration,
+	}
+	if webhookCfg.Path != "" {
+		data, err := os.ReadFile(webhookCfg.Path)
+		if err != nil {
+			return nil, fmt.Errorf("read endpoints: %w", err)
+		}
+		if err := json.Unmarshal(data, &notifier.endpoints); err != nil {
+			return nil, fmt.Errorf("decode endpoints: %w", err)
+		}
+		for _, endpoint := range notifier.endpoints {
+			if err := validation.Validate.Struct(endpoint); err != nil {
+				return nil, fmt.Errorf("validate endpoint: %w", err)
+			}
+		}
+	}
+	return notifier, nil
+}
+
+// Notify sends the event to all configured endpoints, as well as any additional endpoints given. Endpoints are
+// notified concurrently, with failed requests retried. An error is returned for any endpoints that could not be
+// notified.
+func (n *Notifier) Notify(ctx context.Context, event *Event, endpoints ...*Endpoint) error {
+	endpoints = append(endpoints, n.endpoints...)
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, endpoint := range endpoints {
+		wg.Go(func() {
+			if err := n.send(ctx, endpoint, event); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// NotifyInBackground sends the event as with Notify, but in the background, so that the caller is not delayed by slow
+// endpoints. The result is passed to done, if it is not nil. Notifications sent in the background are waited for by
+// Shutdown.
+func (n *Notifier) NotifyInBackground(ctx context.Context, event *Event, done func(error), endpoints ...*Endpoint) {
+	n.background.Go(func() {
+		err := n.Notify(ctx, event, endpoints...)
+		if done != nil {
+			done(err)
+		}
+	})
+}
+
+// Shutdown waits for any notifications being sent in the background to finish. If the context is done first, its
+// error is returned.
+func (n *Notifier) Shutdown(ctx context.Context) error {
+	finished := make(chan struct{})
+	go func() {
+		n.background.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("wait for notifications: %w", ctx.Err())
+	}
+}
+
+// send sends the event to the endpoint, retrying with backoff on network errors and server errors.
+func (n *Notifier) send(ctx context.Context, endpoint *Endpoint, event *Event) error {
+	body, err := encode(endpoint.Format, event)
+	if err != nil {
+		return fmt.Errorf("encode event: %w", err)
+	}
+
+	backoff := n.backoff
+	for attempt := 1; ; attempt++ {
+		err = n.post(ctx, endpoint, body)
+		if err == nil || !errors.Is(err, errRetryable) || attempt >= n.maxAttempts {
+			break
+		}
+		// Add up to 10% jitter, so that retries to the same endpoint are spread out.
+		delay := backoff + rand.N(backoff/10+1)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("notify %s: %w", endpoint.URL, ctx.Err())
+		case <-time.After(delay):
+		}
+		backoff *= 2
+	}
+	if err != nil {
+		return fmt.Errorf("notify %s: %w", endpoint.URL, err)
+	}
+	return nil
+}
+
+// post makes a single request to the endpoint. Errors that may succeed if retried wrap errRetryable.
+func (n *Notifier) post(ctx context.Context, endpoint *Endpoint, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if endpoint.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(HeaderTimestamp, timestamp)
+		req.Header.Set(HeaderSignature, Sign([]byte(endpoint.Secret), timestamp, body))
+	}
+
+	res, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: send request: %w", errRetryable, err)
+	}
+	defer res.Body.Close() //nolint:errcheck
+
+	switch {
+	case res.StatusCode >= http.StatusOK && res.StatusCode < http.StatusMultipleChoices:
+		return nil
+	case res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= http.StatusInternalServerError:
+		return fmt.Errorf("%w: webhook returned status %s", errRetryable, res.Status)
+	default:
+		return fmt.Errorf("webhook returned status %s", res.Status)
+	}
+}
+
+// Sign returns the signature of a request with the given timestamp and body; the hex encoded HMAC-SHA256 of the
+// timestamp and body joined by a ".", prefixed with "sha256=". Receivers should verify the signature and reject
+// requests with a stale timestamp.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,305 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package webhook_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/immanent-tech/go-base/config"
+
+	"github.com/immanent-tech/www-immanent-tech/providers/webhook"
+)
+
+// receiver is a stub webhook that responds with each of its statuses in turn, recording the requests it receives.
+type receiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	requests []*receivedRequest
+}
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	t.Helper()
+	stub := &receiver{statuses: statuses}
+	stub.Server = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		stub.mu.Lock()
+		defer stub.mu.Unlock()
+		stub.requests = append(stub.requests, &receivedRequest{header: req.Header.Clone(), body: body})
+		status := http.StatusNoContent
+		if len(stub.statuses) > 0 {
+			status, stub.statuses = stub.statuses[0], stub.statuses[1:]
+		}
+		res.WriteHeader(status)
+	}))
+	t.Cleanup(stub.Close)
+	return stub
+}
+
+func (r *receiver) received() []*receivedRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.requests
+}
+
+func newTestNotifier(t *testing.T, path string) *webhook.Notifier {
+	t.Helper()
+	notifier, err := webhook.NewWithConfig(webhook.Config{
+		Path:        path,
+		Timeout:     config.NewDuration(time.Second),
+		MaxAttempts: 3,
+		Backoff:     config.NewDuration(time.Millisecond),
+	})
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+	return notifier
+}
+
+func newTestEvent() *webhook.Event {
+	return &webhook.Event{
+		Type:         webhook.EventSubmission,
+		Reference:    "IT-ABCDEFGHIJ",
+		Category:     "General enquiry",
+		ContactEmail: "visitor@example.com",
+		Details:      "Hello <@everyone> & friends",
+		CreatedAt:    time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestNotifySigned(t *testing.T) {
+	stub := newReceiver(t)
+	secret := "shh"
+	err := newTestNotifier(t, "").Notify(t.Context(), newTestEvent(), &webhook.Endpoint{URL: stub.URL, Secret: secret})
+	if err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	received := stub.received()
+	if len(received) != 1 {
+		t.Fatalf("received %d requests, want 1", len(received))
+	}
+	req := received[0]
+	if got := req.header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	var event webhook.Event
+	if err := json.Unmarshal(req.body, &event); err != nil {
+		t.Fatalf("decode event: %v", err)
+	}
+	if event.Reference != "IT-ABCDEFGHIJ" || event.Type != webhook.EventSubmission {
+		t.Errorf("event = %+v, want the submission", event)
+	}
+
+	// The signature is the HMAC of the timestamp and body.
+	timestamp := req.header.Get(webhook.HeaderTimestamp)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + string(req.body)))
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := req.header.Get(webhook.HeaderSignature); got != want {
+		t.Errorf("%s = %q, want %q", webhook.HeaderSignature, got, want)
+	}
+	if got := webhook.Sign([]byte(secret), timestamp, req.body); got != want {
+		t.Errorf("Sign() = %q, want %q", got, want)
+	}
+}
+
+func TestNotifyInBackground(t *testing.T) {
+	stub := newReceiver(t)
+	notifier := newTestNotifier(t, "")
+
+	results := make(chan error, 1)
+	notifier.NotifyInBackground(t.Context(), newTestEvent(), func(err error) {
+		results <- err
+	}, &webhook.Endpoint{URL: stub.URL})
+	if err := notifier.Shutdown(t.Context()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	// Shutdown returns only once the notification has been sent.
+	select {
+	case err := <-results:
+		if err != nil {
+			t.Errorf("NotifyInBackground() error = %v", err)
+		}
+	default:
+		t.Fatal("Shutdown() returned before the notification was sent")
+	}
+	if len(stub.received()) != 1 {
+		t.Errorf("received %d requests, want 1", len(stub.received()))
+	}
+}
+
+func TestNotifyUnsigned(t *testing.T) {
+	stub := newReceiver(t)
+	if err := newTestNotifier(t, "").Notify(t.Context(), newTestEvent(), &webhook.Endpoint{URL: stub.URL}); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	header := stub.received()[0].header
+	if header.Get(webhook.HeaderSignature) != "" || header.Get(webhook.HeaderTimestamp) != "" {
+		t.Error("request to an endpoint without a secret was signed")
+	}
+}
+
+func TestNotifyRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantAttempts int
+		wantErr      bool
+	}{
+		{
+			name:         "success",
+			statuses:     []int{http.StatusOK},
+			wantAttempts: 1,
+		},
+		{
+			name:         "server error then success",
+			statuses:     []int{http.StatusBadGateway, http.StatusOK},
+			wantAttempts: 2,
+		},
+		{
+			name:         "rate limited then success",
+			statuses:     []int{http.StatusTooManyRequests, http.StatusOK},
+			wantAttempts: 2,
+		},
+		{
+			name:         "client error is not retried",
+			statuses:     []int{http.StatusBadRequest},
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+		{
+			name: "gives up after the maximum attempts",
+			statuses: []int{
+				http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError,
+			},
+			wantAttempts: 3,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := newReceiver(t, tt.statuses...)
+			err := newTestNotifier(t, "").Notify(t.Context(), newTestEvent(), &webhook.Endpoint{URL: stub.URL})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Notify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := len(stub.received()); got != tt.wantAttempts {
+				t.Errorf("received %d attempts, want %d", got, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestNotifySlack(t *testing.T) {
+	stub := newReceiver(t)
+	endpoint := &webhook.Endpoint{URL: stub.URL, Format: webhook.FormatSlack}
+	if err := newTestNotifier(t, "").Notify(t.Context(), newTestEvent(), endpoint); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	var message struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(stub.received()[0].body, &message); err != nil {
+		t.Fatalf("decode message: %v", err)
+	}
+	want := "*New contact submission* IT-ABCDEFGHIJ\n*Category:* General enquiry\n*From:* visitor@example.com\n" +
+		"> Hello &lt;@everyone&gt; &amp; friends"
+	if message.Text != want {
+		t.Errorf("text = %q, want %q", message.Text, want)
+	}
+}
+
+func TestNotifyDiscord(t *testing.T) {
+	stub := newReceiver(t)
+	endpoint := &webhook.Endpoint{URL: stub.URL, Format: webhook.FormatDiscord}
+	if err := newTestNotifier(t, "").Notify(t.Context(), newTestEvent(), endpoint); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	var message struct {
+		Embeds []struct {
+			Title     string `json:"title"`
+			Timestamp string `json:"timestamp"`
+		} `json:"embeds"`
+		AllowedMentions struct {
+			Parse []string `json:"parse"`
+		} `json:"allowed_mentions"`
+	}
+	if err := json.Unmarshal(stub.received()[0].body, &message); err != nil {
+		t.Fatalf("decode message: %v", err)
+	}
+	if len(message.Embeds) != 1 || message.Embeds[0].Title != "IT-ABCDEFGHIJ" ||
+		message.Embeds[0].Timestamp != "2026-10-17T00:00:00Z" {
+		t.Errorf("embeds = %+v, want the submission", message.Embeds)
+	}
+	// Mentions in the submission must not notify anyone.
+	if message.AllowedMentions.Parse == nil || len(message.AllowedMentions.Parse) != 0 {
+		t.Errorf("allowed_mentions.parse = %v, want an empty list", message.AllowedMentions.Parse)
+	}
+}
+
+func TestNotifyTruncatesDetails(t *testing.T) {
+	stub := newReceiver(t)
+	event := newTestEvent()
+	event.Details = strings.Repeat("a", 1500)
+	endpoint := &webhook.Endpoint{URL: stub.URL, Format: webhook.FormatDiscord}
+	if err := newTestNotifier(t, "").Notify(t.Context(), event, endpoint); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	var message struct {
+		Embeds []struct {
+			Description string `json:"description"`
+		} `json:"embeds"`
+	}
+	if err := json.Unmarshal(stub.received()[0].body, &message); err != nil {
+		t.Fatalf("decode message: %v", err)
+	}
+	if got := message.Embeds[0].Description; got != strings.Repeat("a", 1000)+"…" {
+		t.Errorf("description has %d characters, want the details truncated to 1000", len([]rune(got)))
+	}
+}
+
+func TestNotifyConfiguredEndpoints(t *testing.T) {
+	configured, extra := newReceiver(t), newReceiver(t, http.StatusBadRequest)
+	path := filepath.Join(t.TempDir(), "webhooks.json")
+	if err := os.WriteFile(path, []byte(`[{"url": "`+configured.URL+`"}]`), 0o600); err != nil {
+		t.Fatalf("write endpoints: %v", err)
+	}
+
+	// A failing endpoint is reported without preventing the others from being notified.
+	err := newTestNotifier(t, path).Notify(t.Context(), newTestEvent(), &webhook.Endpoint{URL: extra.URL})
+	if err == nil || !strings.Contains(err.Error(), extra.URL) {
+		t.Errorf("Notify() error = %v, want an error for %s", err, extra.URL)
+	}
+	if len(configured.received()) != 1 || len(extra.received()) != 1 {
+		t.Errorf("configured endpoint received %d and extra endpoint %d requests, want 1 each",
+			len(configured.received()), len(extra.received()))
+	}
+
+	invalid := filepath.Join(t.TempDir(), "invalid.json")
+	if err := os.WriteFile(invalid, []byte(`[{"url": "not a url"}]`), 0o600); err != nil {
+		t.Fatalf("write endpoints: %v", err)
+	}
+	if _, err := webhook.NewWithConfig(webhook.Config{Path: invalid, MaxAttempts: 1}); err == nil {
+		t.Error("NewWithConfig() with an invalid endpoint error = nil")
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+// Package attachments provides handling of files attached to form submissions. Attachments are limited in number and
+// size, and their content is sniffed to ensure only allowed types of files are accepted.
+package attachments
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/immanent-tech/go-base/config"
+	"github.com/immanent-tech/go-base/validation"
+
+	"github.com/immanent-tech/www-immanent-tech/providers/mailer"
+)
+
+const (
+	configPrefix = "ATTACHMENTS_"
+	// maxFilenameLength is the maximum length of an attachment filename. Longer filenames are truncated.
+	maxFilenameLength = 128
+)
+
+var (
+	// ErrTooMany is returned when more than the maximum number of attachments are submitted.
+	ErrTooMany = errors.New("too many attachments")
+	// ErrTooLarge is returned when an attachment, or all attachments combined, exceed the maximum size.
+	ErrTooLarge = errors.New("attachment too large")
+	// ErrTypeNotAllowed is returned when an attachment is not one of the allowed types.
+	ErrTypeNotAllowed = errors.New("attachment type not allowed")
+)
+
+// allowedTypes are the content types of files that can be attached, as sniffed from their content.
+var allowedTypes = []string{
+	"image/png",
+	"image/jpeg",
+	"image/gif",
+	"image/webp",
+	"application/pdf",
+	"text/plain",
+}
+
+// Config contains the attachment configuration options.
+type Config struct {
+	// MaxCount is the maximum number of files that can be attached to a submission. A value of 0 disables attachments.
+	MaxCount int `koanf:"maxcount" validate:"min=0,max=10"`
+	// MaxSize is the maximum size in bytes of each attached file.
+	MaxSize int64 `koanf:"maxsize" validate:"min=1"`
+	// MaxTotalSize is the maximum size in bytes of all files attached to a submission combined.
+	MaxTotalSize int64 `koanf:"maxtotalsize" validate:"min=1,gtefield=MaxSize"`
+}
+
+var cfg = Config{
+	MaxCount:     3,
+	MaxSize:      5 << 20,
+	MaxTotalSize: 10 << 20,
+}
+
+// loadConfig loads the attachment configuration and ensures this is only done
+// one time, no matter how many times it is called.
+var loadConfig = sync.OnceValue(func() error {
+	if err := config.Load(configPrefix, &cfg); err != nil {
+		return fmt.Errorf("load config from environment: %w", err)
+	}
+	if err := validation.Validate.Struct(cfg); err != nil {
+		return fmt.Errorf("validate config: %w", err)
+	}
+	return nil
+})
+
+// Policy enforces the limits on attachments.
+type Policy struct {
+	cfg Config
+}
+
+// New creates a new Policy. The attachment config will be loaded from the environment.
+func New() (*Policy, error) {
+	if err := loadConfig(); err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	return NewWithConfig(cfg)
+}
+
+// NewWithConfig creates a new Policy with the given config.
+func NewWithConfig(attachmentsCfg Config) (*Policy, error) {
+	if err := validation.Validate.Struct(attachmentsCfg); err != nil {
+		return nil, fmt.Errorf("validate config: %w", err)
+	}
+	return &Policy{cfg: attachmentsCfg}, nil
+}
+
+// Enabled reports whether attachments are accepted.
+func (p *Policy) Enabled() bool {
+	return p.cfg.MaxCount > 0
+}
+
+// MaxCount returns the maximum number of files that can be attached.
+func (p *Policy) MaxCount() int {
+	return p.cfg.MaxCount
+}
+
+// MaxSize returns the maximum size in bytes of each attached file.
+func (p *Policy) MaxSize() int64 {
+	return p.cfg.MaxSize
+}
+
+// MaxTotalSize returns the maximum size in bytes of all attached files combined.
+func (p *Policy) MaxTotalSize() int64 {
+	return p.cfg.MaxTotalSize
+}
+
+// Accept returns the allowed content types, formatted for the accept attribute of a file input.
+func (p *Policy) Accept() string {
+	return strings.Join(allowedTypes, ",")
+}
+
+// Read reads the given uploaded files into attachments, enforcing the limits of the policy. The content type of each
+// file is sniffed from its content rather than trusting the type declared by the client.
+func (p *Policy) Read(files []*multipart.FileHeader) ([]*mailer.Attachment, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+	if len(files) > p.cfg.MaxCount {
+		return nil, fmt.Errorf("%w: %d files, at most %d allowed", ErrTooMany, len(files), p.cfg.MaxCount)
+	}
+
+	attachments := make([]*mailer.Attachment, 0, len(files))
+	var total int64
+	for _, file := range files {
+		if file.Size > p.cfg.MaxSize {
+			return nil, fmt.Errorf("%w: %s", ErrTooLarge, file.Filename)
+		}
+		total += file.Size
+		if total > p.cfg.MaxTotalSize {
+			return nil, fmt.Errorf("%w: combined size exceeds limit", ErrTooLarge)
+		}
+		attachment, err := p.read(file)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+	return attachments, nil
+}
+
+// read reads the uploaded file, returning an error if its sniffed content type is not allowed.
+func (p *Policy) read(file *multipart.FileHeader) (*mailer.Attachment, error) {
+	content, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", file.Filename, err)
+	}
+	defer content.Close() //nolint:errcheck
+
+	// Read no more than the limit, in case the declared size is wrong.
+	data, err := io.ReadAll(io.LimitReader(content, p.cfg.MaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", file.Filename, err)
+	}
+	if int64(len(data)) > p.cfg.MaxSize {
+		return nil, fmt.Errorf("%w: %s", ErrTooLarge, file.Filename)
+	}
+
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(data))
+	if err != nil || !slices.Contains(allowedTypes, contentType) {
+		return nil, fmt.Errorf("%w: %s", ErrTypeNotAllowed, file.Filename)
+	}
+
+	return &mailer.Attachment{
+		Filename:    sanitiseFilename(file.Filename),
+		ContentType: contentType,
+		Data:        data,
+	}, nil
+}
+
+// sanitiseFilename removes any path and control characters from the filename, and truncates it to a reasonable
+// length.
+func sanitiseFilename(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	if runes := []rune(name); len(runes) > maxFilenameLength {
+		name = string(runes[:maxFilenameLength])
+	}
+	if name == "" || name == "." || name == ".." || name == "/" {
+		return "attachment"
+	}
+	return name
+}
@@ -0,0 +1,177 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package attachments_test
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"strings"
+	"testing"
+
+	"github.com/immanent-tech/www-immanent-tech/server/attachments"
+)
+
+var (
+	pngData  = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	pdfData  = []byte("%PDF-1.7\n")
+	textData = []byte("some notes")
+	zipData  = []byte("PK\x03\x04\x14\x00")
+)
+
+// testFile is a file uploaded in a test form.
+type testFile struct {
+	name        string
+	contentType string
+	data        []byte
+}
+
+// newFileHeaders uploads the files in a multipart form, returning their headers as parsed by a server.
+func newFileHeaders(t *testing.T, files ...testFile) []*multipart.FileHeader {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, file := range files {
+		header := make(map[string][]string)
+		header["Content-Disposition"] = []string{`form-data; name="attachments"; filename="` + file.name + `"`}
+		header["Content-Type"] = []string{file.contentType}
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			t.Fatalf("create part: %v", err)
+		}
+		part.Write(file.data)
+	}
+	writer.Close()
+
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatalf("read form: %v", err)
+	}
+	t.Cleanup(func() {
+		form.RemoveAll()
+	})
+	return form.File["attachments"]
+}
+
+func newTestPolicy(t *testing.T) *attachments.Policy {
+	t.Helper()
+	policy, err := attachments.NewWithConfig(attachments.Config{MaxCount: 2, MaxSize: 16, MaxTotalSize: 28})
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+	return policy
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     []testFile
+		want      error
+		wantTypes []string
+	}{
+		{
+			name: "no files",
+		},
+		{
+			name: "allowed types",
+			files: []testFile{
+				{name: "image.png", contentType: "image/png", data: pngData},
+				{name: "brief.pdf", contentType: "application/pdf", data: pdfData},
+			},
+			wantTypes: []string{"image/png", "application/pdf"},
+		},
+		{
+			name:      "declared type is ignored",
+			files:     []testFile{{name: "notes.png", contentType: "image/png", data: textData}},
+			wantTypes: []string{"text/plain"},
+		},
+		{
+			name:  "type not allowed",
+			files: []testFile{{name: "archive.pdf", contentType: "application/pdf", data: zipData}},
+			want:  attachments.ErrTypeNotAllowed,
+		},
+		{
+			name: "too many",
+			files: []testFile{
+				{name: "a.txt", contentType: "text/plain", data: textData},
+				{name: "b.txt", contentType: "text/plain", data: textData},
+				{name: "c.txt", contentType: "text/plain", data: textData},
+			},
+			want: attachments.ErrTooMany,
+		},
+		{
+			name:  "file too large",
+			files: []testFile{{name: "large.txt", contentType: "text/plain", data: bytes.Repeat([]byte("a"), 17)}},
+			want:  attachments.ErrTooLarge,
+		},
+		{
+			name: "combined size too large",
+			files: []testFile{
+				{name: "a.txt", contentType: "text/plain", data: bytes.Repeat([]byte("a"), 16)},
+				{name: "b.txt", contentType: "text/plain", data: bytes.Repeat([]byte("b"), 16)},
+			},
+			want: attachments.ErrTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newTestPolicy(t).Read(newFileHeaders(t, tt.files...))
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Read() error = %v, want %v", err, tt.want)
+			}
+			if len(got) != len(tt.wantTypes) {
+				t.Fatalf("Read() returned %d attachments, want %d", len(got), len(tt.wantTypes))
+			}
+			for i, attachment := range got {
+				if attachment.ContentType != tt.wantTypes[i] {
+					t.Errorf("attachment %d type = %s, want %s", i, attachment.ContentType, tt.wantTypes[i])
+				}
+				if !bytes.Equal(attachment.Data, tt.files[i].data) {
+					t.Errorf("attachment %d data = %q, want %q", i, attachment.Data, tt.files[i].data)
+				}
+			}
+		})
+	}
+}
+
+func TestReadFilename(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "notes.txt", want: "notes.txt"},
+		{name: "../../etc/notes.txt", want: "notes.txt"},
+		{name: `C:\\Users\\visitor\\notes.txt`, want: "notes.txt"},
+		{name: "no\ttes.txt", want: "notes.txt"},
+		{name: strings.Repeat("a", 200), want: strings.Repeat("a", 128)},
+		{name: "..", want: "attachment"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			got, err := newTestPolicy(t).Read(newFileHeaders(t, testFile{name: tt.name, data: textData}))
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if got[0].Filename != tt.want {
+				t.Errorf("Filename = %q, want %q", got[0].Filename, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyEnabled(t *testing.T) {
+	disabled, err := attachments.NewWithConfig(attachments.Config{MaxSize: 1, MaxTotalSize: 1})
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+	if disabled.Enabled() {
+		t.Error("Enabled() = true with a MaxCount of 0")
+	}
+	if !newTestPolicy(t).Enabled() {
+		t.Error("Enabled() = false with a MaxCount of 2")
+	}
+	if _, err := attachments.NewWithConfig(attachments.Config{MaxCount: 1, MaxSize: 2, MaxTotalSize: 1}); err == nil {
+		t.Error("NewWithConfig() with MaxTotalSize less than MaxSize error = nil")
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+// Package categories provides the enquiry categories of the contact form. Each category is routed to its own
+// recipient, with its own subject prefix and optionally a webhook to notify.
+package categories
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"os"
+
+	"github.com/immanent-tech/go-base/validation"
+
+	"github.com/immanent-tech/www-immanent-tech/providers/webhook"
+)
+
+// ErrDuplicateID is returned when more than one category has the same ID.
+var ErrDuplicateID = errors.New("duplicate category id")
+
+// defaultRecipient is the recipient of the default categories.
+const defaultRecipient = "Immanent Tech <hello@immanent.tech>"
+
+// defaults are the categories used when no categories file has been configured.
+var defaults = []*Category{
+	{ID: "general", Name: "General enquiry", Recipient: defaultRecipient, SubjectPrefix: "[General]"},
+	{ID: "foragd", Name: "Foragd support", Recipient: defaultRecipient, SubjectPrefix: "[Foragd]"},
+	{ID: "consulting", Name: "Consulting", Recipient: defaultRecipient, SubjectPrefix: "[Consulting]"},
+	{ID: "press", Name: "Press", Recipient: defaultRecipient, SubjectPrefix: "[Press]"},
+}
+
+// Category is an enquiry category of the contact form.
+type Category struct {
+	// ID is the value submitted by the form for the category.
+	ID string `json:"id" validate:"required,alphanum,lowercase"`
+	// Name is the name of the category shown on the form.
+	Name string `json:"name" validate:"required"`
+	// Recipient is the address to which submissions in the category are sent.
+	Recipient string `json:"recipient" validate:"required"`
+	// SubjectPrefix is prepended to the subject of submissions in the category.
+	SubjectPrefix string `json:"subject_prefix,omitempty"`
+	// Webhook is an optional webhook that is notified of submissions in the category.
+	Webhook *webhook.Endpoint `json:"webhook,omitempty"`
+
+	address *mail.Address
+}
+
+// Address returns the parsed recipient address of the category.
+func (c *Category) Address() *mail.Address {
+	return c.address
+}
+
+// Categories is the list of enquiry categories.
+type Categories struct {
+	list []*Category
+	byID map[string]*Category
+}
+
+// Load loads the categories from the JSON file at the given path, which should contain an array of categories. The
+// categories are shown on the form in the order they appear in the file. If path is empty, a default list of
+// categories is used.
+func Load(path string) (*Categories, error) {
+	list := defaults
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read categories: %w", err)
+		}
+		list = nil
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, fmt.Errorf("decode categories: %w", err)
+		}
+	}
+	return New(list)
+}
+
+// New creates a new list of categories from the given categories.
+func New(list []*Category) (*Categories, error) {
+	if len(list) == 0 {
+		return nil, errors.New("no categories")
+	}
+	categories := &Categories{
+		list: list,
+		byID: make(map[string]*Category, len(list)),
+	}
+	for _, category := range list {
+		if err := validation.Validate.Struct(category); err != nil {
+			return nil, fmt.Errorf("validate category %q: %w", category.ID, err)
+		}
+		if _, found := categories.byID[category.ID]; found {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateID, category.ID)
+		}
+		address, err := mail.ParseAddress(category.Recipient)
+		if err != nil {
+			return nil, fmt.Errorf("parse recipient of category %q: %w", category.ID, err)
+		}
+		category.address = address
+		categories.byID[category.ID] = category
+	}
+	return categories, nil
+}
+
+// All returns all categories, in the order they are shown on the form.
+func (c *Categories) All() []*Category {
+	return c.list
+}
+
+// Get returns the category with the given ID, if there is one.
+func (c *Categories) Get(id string) (*Category, bool) {
+	category, found := c.byID[id]
+	return category, found
+}
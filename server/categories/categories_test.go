@@ -0,0 +1,168 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package categories_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/immanent-tech/www-immanent-tech/server/categories"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    []*categories.Category
+		wantErr bool
+		want    error
+	}{
+		{
+			name: "valid",
+			list: []*categories.Category{
+				{ID: "general", Name: "General enquiry", Recipient: "Hello <hello@example.com>"},
+				{ID: "press", Name: "Press", Recipient: "press@example.com", SubjectPrefix: "[Press]"},
+			},
+		},
+		{
+			name:    "no categories",
+			wantErr: true,
+		},
+		{
+			name: "duplicate id",
+			list: []*categories.Category{
+				{ID: "general", Name: "General enquiry", Recipient: "hello@example.com"},
+				{ID: "general", Name: "Other", Recipient: "other@example.com"},
+			},
+			wantErr: true,
+			want:    categories.ErrDuplicateID,
+		},
+		{
+			name:    "id not lowercase",
+			list:    []*categories.Category{{ID: "General", Name: "General enquiry", Recipient: "hello@example.com"}},
+			wantErr: true,
+		},
+		{
+			name:    "id not alphanumeric",
+			list:    []*categories.Category{{ID: "gen-eral", Name: "General enquiry", Recipient: "hello@example.com"}},
+			wantErr: true,
+		},
+		{
+			name:    "missing name",
+			list:    []*categories.Category{{ID: "general", Recipient: "hello@example.com"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid recipient",
+			list:    []*categories.Category{{ID: "general", Name: "General enquiry", Recipient: "not an address"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := categories.New(tt.list)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("New() error = %v, want %v", err, tt.want)
+			}
+			if err != nil {
+				return
+			}
+			if len(got.All()) != len(tt.list) {
+				t.Errorf("All() returned %d categories, want %d", len(got.All()), len(tt.list))
+			}
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	list, err := categories.New([]*categories.Category{
+		{ID: "general", Name: "General enquiry", Recipient: "Hello <hello@example.com>"},
+		{ID: "press", Name: "Press", Recipient: "press@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	category, found := list.Get("general")
+	if !found {
+		t.Fatal("Get(general) not found")
+	}
+	if address := category.Address(); address.Name != "Hello" || address.Address != "hello@example.com" {
+		t.Errorf("Address() = %v, want Hello <hello@example.com>", address)
+	}
+	if _, found := list.Get("unknown"); found {
+		t.Error("Get(unknown) found a category")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("write categories: %v", err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantIDs []string
+		wantErr bool
+	}{
+		{
+			name:    "defaults",
+			wantIDs: []string{"general", "foragd", "consulting", "press"},
+		},
+		{
+			name: "file in order",
+			path: write("valid.json", `[
+				{"id": "support", "name": "Support", "recipient": "support@example.com"},
+				{"id": "sales", "name": "Sales", "recipient": "sales@example.com", "subject_prefix": "[Sales]"}
+			]`),
+			wantIDs: []string{"support", "sales"},
+		},
+		{
+			name:    "missing file",
+			path:    filepath.Join(dir, "missing.json"),
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			path:    write("invalid.json", `{"id": "support"}`),
+			wantErr: true,
+		},
+		{
+			name:    "empty list",
+			path:    write("empty.json", `[]`),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := categories.Load(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			all := got.All()
+			if len(all) != len(tt.wantIDs) {
+				t.Fatalf("Load() returned %d categories, want %d", len(all), len(tt.wantIDs))
+			}
+			for i, id := range tt.wantIDs {
+				if all[i].ID != id {
+					t.Errorf("category %d = %s, want %s", i, all[i].ID, id)
+				}
+			}
+		})
+	}
+}
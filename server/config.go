@@ -4,12 +4,18 @@
 package server
 
 import (
+	"crypto/rand"
+	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/immanent-tech/go-base/config"
 	"github.com/immanent-tech/go-base/validation"
+
+	"github.com/immanent-tech/www-immanent-tech/server/middlewares"
 )
 
 const (
@@ -17,13 +23,24 @@ const (
 	defaultCompressionLevel = 5
 )
 
-var compressMimetypes = []string{"text/html", "text/css", "text/javascript", "font/woff2", "image/svg+xml"}
+// compressMimetypes are the content types of responses compressed for each request. Static content is not included, as
+// it is compressed once by its handler and must not be re-encoded, which would break its ETags and range requests.
+var compressMimetypes = []string{"text/html"}
 
 var cfg = Config{
 	Host:         "0.0.0.0",
 	ReadTimeout:  config.NewDuration(120 * time.Second),
 	WriteTimeout: config.NewDuration(30 * time.Second),
 	IdleTimeout:  config.NewDuration(900 * time.Second),
+	Mailer:       mailerFastmail,
+
+	RateLimitPages:        10,
+	RateLimitPagesBurst:   30,
+	RateLimitContact:      0.1,
+	RateLimitContactBurst: 3,
+	RateLimitAPI:          1,
+	RateLimitAPIBurst:     5,
+	ClientIPStrategy:      string(middlewares.ClientIPForwardedFor),
 }
 
 // Config contains the server configuration options.
@@ -35,6 +52,39 @@ type Config struct {
 	ReadTimeout  config.Duration `koanf:"readtimeout"  validate:"omitempty"`
 	WriteTimeout config.Duration `koanf:"writetimeout" validate:"omitempty"`
 	IdleTimeout  config.Duration `koanf:"idletimeout"  validate:"omitempty"`
+	Mailer       string          `koanf:"mailer"       validate:"omitempty,oneof=fastmail smtp log"`
+	SigningKey   string          `koanf:"signingkey"   validate:"omitempty,min=32"`
+	AutoReply    string          `koanf:"autoreply"    validate:"omitempty,email"`
+	Categories   string          `koanf:"categories"   validate:"omitempty,file"`
+	APIKeys      string          `koanf:"apikeys"      validate:"omitempty"`
+	// AdminKeys is a list of API keys, in the same form as APIKeys, that are allowed to export the stored contact
+	// submissions.
+	AdminKeys string `koanf:"adminkeys" validate:"omitempty"`
+
+	// RateLimitPages and RateLimitPagesBurst are the requests per second and burst size allowed for each client to the
+	// pages of the site. A rate of 0 disables the limit. Rate limits only apply in production.
+	RateLimitPages      float64 `koanf:"ratelimitpages"      validate:"min=0"`
+	RateLimitPagesBurst int     `koanf:"ratelimitpagesburst" validate:"min=0"`
+	// RateLimitContact and RateLimitContactBurst are the requests per second and burst size allowed for each client
+	// submitting the contact form.
+	RateLimitContact      float64 `koanf:"ratelimitcontact"      validate:"min=0"`
+	RateLimitContactBurst int     `koanf:"ratelimitcontactburst" validate:"min=0"`
+	// RateLimitAPI and RateLimitAPIBurst are the requests per second and burst size allowed for each client of the
+	// API.
+	RateLimitAPI      float64 `koanf:"ratelimitapi"      validate:"min=0"`
+	RateLimitAPIBurst int     `koanf:"ratelimitapiburst" validate:"min=0"`
+	// RateLimitExempt is a comma-separated list of paths that are not rate limited. A path ending in "/*" exempts all
+	// paths beneath it.
+	RateLimitExempt string `koanf:"ratelimitexempt" validate:"omitempty"`
+	// ClientIPStrategy is how the IP address of the client is determined; from the address of the connection
+	// (remoteaddr), the X-Forwarded-For header (xforwardedfor), the RFC 7239 Forwarded header (forwarded) or the
+	// CF-Connecting-IP header set by Cloudflare (cfconnectingip).
+	ClientIPStrategy string `koanf:"clientipstrategy" validate:"omitempty,oneof=remoteaddr xforwardedfor forwarded cfconnectingip"`
+	// TrustedProxies is a comma-separated list of the addresses or CIDRs of proxies in front of the server, such as
+	// the ranges of Cloudflare. The client is the address that forwarded the request to the rightmost trusted proxy
+	// in the client IP headers. It is required in production, unless the strategy is remoteaddr, as otherwise the
+	// address of a proxy in front of the platform would be taken as that of every client.
+	TrustedProxies string `koanf:"trustedproxies" validate:"omitempty"`
 }
 
 // loadConfigOnce loads the server configuration and ensures this is only done
@@ -50,3 +100,57 @@ var loadConfigOnce = sync.OnceValue(func() error {
 	}
 	return nil
 })
+
+// ErrNoSigningKey is returned when no signing key has been configured in production.
+var ErrNoSigningKey = errors.New("no signing key configured")
+
+// signingKey returns the key used for signing values sent to clients. In production, a key must be configured, so
+// that the signed values remain valid across restarts and between instances of the server. In development, a random
+// key is generated if none has been configured.
+func signingKey(logger *slog.Logger) ([]byte, error) {
+	if cfg.SigningKey != "" {
+		return []byte(cfg.SigningKey), nil
+	}
+	if config.IsProduction() {
+		return nil, ErrNoSigningKey
+	}
+	logger.Warn("No signing key configured, using a random key.")
+	return []byte(rand.Text() + rand.Text()), nil
+}
+
+// rateLimit returns the limit with the given rate and burst. Outside of production, rate limiting is skipped and the
+// limit is disabled.
+func rateLimit(rate float64, burst int) middlewares.RateLimit {
+	if !config.IsProduction() {
+		return middlewares.RateLimit{}
+	}
+	return middlewares.RateLimit{Rate: rate, Burst: burst}
+}
+
+// clientIPResolver creates the resolver of client IP addresses from the config.
+func (c *Config) clientIPResolver() (*middlewares.ClientIPResolver, error) {
+	trustedProxies := splitList(c.TrustedProxies)
+	if config.IsProduction() && len(trustedProxies) == 0 &&
+		middlewares.ClientIPStrategy(c.ClientIPStrategy) != middlewares.ClientIPRemoteAddr {
+		return nil, fmt.Errorf("%w: trusted proxies are required in production", middlewares.ErrNoTrustedProxies)
+	}
+	resolver, err := middlewares.NewClientIPResolver(
+		middlewares.ClientIPStrategy(c.ClientIPStrategy),
+		trustedProxies,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create client ip resolver: %w", err)
+	}
+	return resolver, nil
+}
+
+// splitList splits a comma-separated list, ignoring any empty entries.
+func splitList(list string) []string {
+	var entries []string
+	for entry := range strings.SplitSeq(list, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package forms
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldErrors maps the names of form fields to a message describing why the submitted value of the field is invalid.
+type FieldErrors map[string]string
+
+// NewFieldErrors translates the validation errors in err, from validating obj, into FieldErrors. Each error is keyed
+// by the form tag of the field that failed validation, falling back to the field name if it has no form tag. Only the
+// first error for each field is kept. If err contains no validation errors, nil is returned.
+func NewFieldErrors(obj any, err error) FieldErrors {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil
+	}
+	fieldErrs := make(FieldErrors, len(validationErrs))
+	for _, validationErr := range validationErrs {
+		name := formName(obj, validationErr.StructField())
+		if _, found := fieldErrs[name]; !found {
+			fieldErrs[name] = message(validationErr)
+		}
+	}
+	return fieldErrs
+}
+
+// Add adds an error message for the given field, if the field does not already have one.
+func (e FieldErrors) Add(field, message string) {
+	if _, found := e[field]; !found {
+		e[field] = message
+	}
+}
+
+// formName returns the form tag of the named field of obj, or the field name if the field has no form tag.
+func formName(obj any, field string) string {
+	objType := reflect.TypeOf(obj)
+	for objType != nil && objType.Kind() == reflect.Pointer {
+		objType = objType.Elem()
+	}
+	if objType == nil || objType.Kind() != reflect.Struct {
+		return field
+	}
+	structField, found := objType.FieldByName(field)
+	if !found {
+		return field
+	}
+	if name := structField.Tag.Get("form"); name != "" && name != "-" {
+		return name
+	}
+	return field
+}
+
+// message returns a human-readable message for the validation error.
+func message(err validator.FieldError) string {
+	switch err.Tag() {
+	case "required":
+		return "This field is required."
+	case "email":
+		return "Enter a valid email address."
+	case "url", "http_url":
+		return "Enter a valid URL."
+	case "min":
+		return fmt.Sprintf("Enter at least %s characters.", err.Param())
+	case "max":
+		return fmt.Sprintf("Enter no more than %s characters.", err.Param())
+	case "oneof":
+		return "Choose one of the available options."
+	default:
+		return "This value is invalid."
+	}
+}
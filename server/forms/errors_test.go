@@ -0,0 +1,105 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package forms_test
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/immanent-tech/www-immanent-tech/server/forms"
+)
+
+var validate = validator.New()
+
+type testInput struct {
+	Name    string `form:"name"    json:"name"    validate:"required,min=2,max=5"`
+	Email   string `form:"email"   json:"email"   validate:"required,email"`
+	Website string `form:"website" json:"website" validate:"omitempty,url"`
+	Choice  string `form:"-"       json:"choice"  validate:"omitempty,oneof=a b"`
+	Other   string `               json:"other"   validate:"omitempty,alpha"`
+}
+
+func (i *testInput) Valid() error {
+	if err := validate.Struct(i); err != nil {
+		return fmt.Errorf("test input invalid: %w", err)
+	}
+	return nil
+}
+
+func (i *testInput) Sanitise() error {
+	return nil
+}
+
+func TestNewFieldErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input *testInput
+		want  forms.FieldErrors
+	}{
+		{
+			name:  "valid",
+			input: &testInput{Name: "Ann", Email: "ann@example.com"},
+		},
+		{
+			name:  "required",
+			input: &testInput{},
+			want: forms.FieldErrors{
+				"name":  "This field is required.",
+				"email": "This field is required.",
+			},
+		},
+		{
+			name:  "messages by tag",
+			input: &testInput{Name: "A", Email: "ann", Website: "example", Choice: "c", Other: "1"},
+			want: forms.FieldErrors{
+				"name":    "Enter at least 2 characters.",
+				"email":   "Enter a valid email address.",
+				"website": "Enter a valid URL.",
+				"Choice":  "Choose one of the available options.",
+				"Other":   "This value is invalid.",
+			},
+		},
+		{
+			name:  "max",
+			input: &testInput{Name: "Annabel", Email: "ann@example.com"},
+			want:  forms.FieldErrors{"name": "Enter no more than 5 characters."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := forms.NewFieldErrors(tt.input, tt.input.Valid())
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("NewFieldErrors() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewFieldErrorsNotValidation(t *testing.T) {
+	if got := forms.NewFieldErrors(&testInput{}, errors.New("decode failed")); got != nil {
+		t.Errorf("NewFieldErrors() = %v, want nil", got)
+	}
+	if got := forms.NewFieldErrors(&testInput{}, nil); got != nil {
+		t.Errorf("NewFieldErrors() = %v, want nil", got)
+	}
+}
+
+func TestFieldErrorsAdd(t *testing.T) {
+	fieldErrs := make(forms.FieldErrors)
+	fieldErrs.Add("email", "Enter a valid email address.")
+	fieldErrs.Add("email", "This field is required.")
+	fieldErrs.Add("category", "Choose one of the available options.")
+
+	want := forms.FieldErrors{
+		"email":    "Enter a valid email address.",
+		"category": "Choose one of the available options.",
+	}
+	if !maps.Equal(fieldErrs, want) {
+		t.Errorf("FieldErrors = %v, want %v", fieldErrs, want)
+	}
+}
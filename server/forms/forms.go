@@ -5,9 +5,12 @@
 package forms
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"mime"
 	"net/http"
+	"reflect"
 
 	"github.com/go-playground/form/v4"
 )
@@ -23,6 +26,8 @@ var (
 	ErrNoFormData = errors.New("no form data")
 	// ErrSanitise indicates an error occurred during sanitisation.
 	ErrSanitise = errors.New("sanitisation failed")
+	// ErrNotJSON indicates a request body was expected to be JSON but had a different content type.
+	ErrNotJSON = errors.New("content type is not application/json")
 )
 
 var (
@@ -54,10 +59,19 @@ func DecodeForm[T FormInput](req *http.Request) (T, bool, error) {
 	return obj, true, nil
 }
 
-func DecodeMultiPartForm[T FormInput](req *http.Request) (T, bool, error) {
+// ParseMultiPartForm will parse a multipart form submission, so that its fields can be read before it is decoded. It
+// is safe to decode the submission with DecodeMultiPartForm afterwards.
+func ParseMultiPartForm(req *http.Request) error {
 	if err := req.ParseMultipartForm(defaultMaxSize); err != nil {
+		return fmt.Errorf("%w: %w", ErrDecode, err)
+	}
+	return nil
+}
+
+func DecodeMultiPartForm[T FormInput](req *http.Request) (T, bool, error) {
+	if err := ParseMultiPartForm(req); err != nil {
 		var obj T
-		return obj, false, fmt.Errorf("%w: %w", ErrDecode, err)
+		return obj, false, err
 	}
 	obj, err := decodeObject[T](req)
 	if err != nil {
@@ -66,19 +80,52 @@ func DecodeMultiPartForm[T FormInput](req *http.Request) (T, bool, error) {
 	return obj, true, nil
 }
 
+// DecodeJSON will decode a JSON request body into the passed in type. Unknown fields are rejected. Like DecodeForm,
+// it will sanitise and validate the type and will return the type and a boolean true if it is valid. If decoding the
+// body fails, a non-nil error is returned.
+func DecodeJSON[T FormInput](req *http.Request) (T, bool, error) {
+	var obj T
+	if mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type")); err != nil ||
+		mediaType != "application/json" {
+		return obj, false, fmt.Errorf("%w: %w", ErrDecode, ErrNotJSON)
+	}
+	decoder := json.NewDecoder(req.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&obj); err != nil {
+		return obj, false, fmt.Errorf("%w: %w", ErrDecode, err)
+	}
+	// Reject trailing data after the object.
+	if decoder.More() {
+		return obj, false, fmt.Errorf("%w: unexpected data after object", ErrDecode)
+	}
+	// Reject a null body.
+	if value := reflect.ValueOf(obj); !value.IsValid() || (value.Kind() == reflect.Pointer && value.IsNil()) {
+		return obj, false, fmt.Errorf("%w: %w", ErrDecode, ErrNoFormData)
+	}
+	if err := prepareObject(obj); err != nil {
+		return obj, false, fmt.Errorf("%w: %w", ErrDecode, err)
+	}
+	return obj, true, nil
+}
+
 func decodeObject[T FormInput](req *http.Request) (T, error) {
 	var obj T
 	// Decode the form values.
 	if err := decoder.Decode(&obj, req.Form); err != nil {
 		return obj, fmt.Errorf("%w: %w", ErrDecode, err)
 	}
+	return obj, prepareObject(obj)
+}
+
+// prepareObject sanitises and then validates the decoded object.
+func prepareObject[T FormInput](obj T) error {
 	// Sanitise the object.
 	if err := obj.Sanitise(); err != nil {
-		return obj, fmt.Errorf("%w: %w", ErrSanitise, err)
+		return fmt.Errorf("%w: %w", ErrSanitise, err)
 	}
 	// Validate the object.
 	if err := obj.Valid(); err != nil {
-		return obj, fmt.Errorf("%w: %w", ErrValidation, err)
+		return fmt.Errorf("%w: %w", ErrValidation, err)
 	}
-	return obj, nil
+	return nil
 }
@@ -0,0 +1,84 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package forms_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/immanent-tech/www-immanent-tech/server/forms"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantValid   bool
+		wantErr     error
+	}{
+		{
+			name:        "valid",
+			contentType: "application/json",
+			body:        `{"name": "Ann", "email": "ann@example.com"}`,
+			wantValid:   true,
+		},
+		{
+			name:        "content type with charset",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"name": "Ann", "email": "ann@example.com"}`,
+			wantValid:   true,
+		},
+		{
+			name:        "not json",
+			contentType: "application/x-www-form-urlencoded",
+			body:        `name=Ann`,
+			wantErr:     forms.ErrNotJSON,
+		},
+		{
+			name:        "unknown field",
+			contentType: "application/json",
+			body:        `{"name": "Ann", "email": "ann@example.com", "admin": true}`,
+			wantErr:     forms.ErrDecode,
+		},
+		{
+			name:        "trailing data",
+			contentType: "application/json",
+			body:        `{"name": "Ann", "email": "ann@example.com"} {}`,
+			wantErr:     forms.ErrDecode,
+		},
+		{
+			name:        "null",
+			contentType: "application/json",
+			body:        `null`,
+			wantErr:     forms.ErrNoFormData,
+		},
+		{
+			name:        "invalid",
+			contentType: "application/json",
+			body:        `{"name": "Ann", "email": "ann"}`,
+			wantErr:     forms.ErrValidation,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+
+			got, valid, err := forms.DecodeJSON[*testInput](req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("DecodeJSON() error = %v, want %v", err, tt.wantErr)
+			}
+			if valid != tt.wantValid {
+				t.Errorf("DecodeJSON() valid = %v, want %v", valid, tt.wantValid)
+			}
+			if valid && (got.Name != "Ann" || got.Email != "ann@example.com") {
+				t.Errorf("DecodeJSON() = %+v, want the decoded input", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package forms
+
+import (
+	"bytes"
+	"crypto/rand"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	slogchi "github.com/samber/slog-chi"
+	slogctx "github.com/veqryn/slog-context"
+
+	"github.com/immanent-tech/www-immanent-tech/models"
+)
+
+const (
+	// HeaderIdempotencyKey is the request header containing the idempotency key of a submission.
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed is the response header set when a response is replayed for a repeated submission.
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+	// DefaultIdempotencyTTL is the default time for which the response to a submission is kept for replaying.
+	DefaultIdempotencyTTL = 10 * time.Minute
+	// maxIdempotencyKeyLength is the maximum length of an idempotency key.
+	maxIdempotencyKeyLength = 255
+)
+
+// replayedHeaders are the response headers recorded for replaying. Other headers, such as Set-Cookie and
+// Content-Security-Policy, belong to the original response and are not replayed; middlewares set them afresh for the
+// repeated request.
+var replayedHeaders = []string{
+	"Content-Type",
+	"Content-Language",
+	"Location",
+	"Hx-Location",
+	"Hx-Push-Url",
+	"Hx-Redirect",
+	"Hx-Refresh",
+	"Hx-Replace-Url",
+	"Hx-Reselect",
+	"Hx-Reswap",
+	"Hx-Retarget",
+	"Hx-Trigger",
+	"Hx-Trigger-After-Settle",
+	"Hx-Trigger-After-Swap",
+}
+
+// NewIdempotencyKey generates a new idempotency key, for rendering into a form.
+func NewIdempotencyKey() string {
+	return rand.Text()
+}
+
+// IdempotencyStore is a short-lived, in-memory store of responses to submissions, keyed by the idempotency key of the
+// submission. It allows a repeated submission, such as from a double-click or a client retry, to be given the
+// original response rather than being processed again. As the store is in-memory, it is not shared between instances
+// of the server.
+type IdempotencyStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*idempotentResponse
+}
+
+// idempotentResponse is the recorded response to a submission. The done channel is closed once the response has been
+// recorded, or the submission failed and its key was released.
+type idempotentResponse struct {
+	done     chan struct{}
+	expires  time.Time
+	recorded bool
+	status   int
+	header   http.Header
+	body     []byte
+}
+
+// NewIdempotencyStore creates a new IdempotencyStore, keeping responses for the given time.
+func NewIdempotencyStore(ttl time.Duration) *IdempotencyStore {
+	return &IdempotencyStore{
+		ttl:     ttl,
+		entries: make(map[string]*idempotentResponse),
+	}
+}
+
+// Middleware makes the handler idempotent for requests with an idempotency key in the Idempotency-Key header. The
+// first request with a key is processed and, if successful (2xx), its status, body and a limited set of headers are
+// recorded. Repeated requests with the same key are given the recorded response, marked with the Idempotent-Replayed
+// header. A repeated request made while the first is still being processed waits for it to complete. If the first
+// request does not succeed, its key is released and repeated requests are processed as normal. Keys are scoped to the
+// path and, for API requests, the client, so that keys cannot collide between routes or clients.
+func (s *IdempotencyStore) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		key := req.Header.Get(HeaderIdempotencyKey)
+		if key == "" {
+			next.ServeHTTP(res, req)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			http.Error(res, "Invalid Idempotency-Key", http.StatusBadRequest)
+			return
+		}
+		key = models.APIClientFromCtx(req.Context()) + "|" + req.Method + " " + req.URL.Path + "|" + key
+
+		entry, claimed := s.claim(key)
+		if !claimed {
+			select {
+			case <-entry.done:
+			case <-req.Context().Done():
+				return
+			}
+			if entry.recorded {
+				slogchi.AddCustomAttributes(req, slog.Bool("idempotent_replay", true))
+				entry.replay(res, req)
+				return
+			}
+		}
+
+		recorder := &responseRecorder{ResponseWriter: res}
+		if claimed {
+			// Complete the entry even if the handler panics, so that repeated requests are not left waiting.
+			defer s.complete(key, entry, recorder)
+		}
+		next.ServeHTTP(recorder, req)
+	})
+}
+
+// claim returns the entry for the key. If there is no unexpired entry for the key, a new entry is created and claimed
+// is true, indicating the caller should process the request and complete the entry. Expired entries are pruned as a
+// side-effect.
+func (s *IdempotencyStore) claim(key string) (entry *idempotentResponse, claimed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for existingKey, existing := range s.entries {
+		if existing.recorded && now.After(existing.expires) {
+			delete(s.entries, existingKey)
+		}
+	}
+
+	if existing, found := s.entries[key]; found {
+		return existing, false
+	}
+	entry = &idempotentResponse{done: make(chan struct{})}
+	s.entries[key] = entry
+	return entry, true
+}
+
+// complete records the response in the entry if it was successful, otherwise the key is released. A response that was
+// never written, such as when the handler panics, is not considered successful.
+func (s *IdempotencyStore) complete(key string, entry *idempotentResponse, recorder *responseRecorder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if recorder.status >= http.StatusOK && recorder.status < http.StatusMultipleChoices {
+		entry.recorded = true
+		entry.status = recorder.status
+		entry.header = recorder.header
+		entry.body = recorder.body.Bytes()
+		entry.expires = time.Now().Add(s.ttl)
+	} else {
+		delete(s.entries, key)
+	}
+	close(entry.done)
+}
+
+// replay writes the recorded response.
+func (r *idempotentResponse) replay(res http.ResponseWriter, req *http.Request) {
+	for key, values := range r.header {
+		res.Header()[key] = values
+	}
+	res.Header().Set(HeaderIdempotentReplayed, "true")
+	res.WriteHeader(r.status)
+	if _, err := res.Write(r.body); err != nil {
+		slogctx.FromCtx(req.Context()).Error("Could not replay response.",
+			slog.Any("error", err),
+		)
+	}
+}
+
+// responseRecorder records a response as it is written. The headers to replay are captured when the status is
+// written, before any middlewares further up the chain (such as compression) modify them.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+		r.header = make(http.Header, len(replayedHeaders))
+		for _, key := range replayedHeaders {
+			if values := r.Header().Values(key); len(values) > 0 {
+				r.header[key] = slices.Clone(values)
+			}
+		}
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.WriteHeader(http.StatusOK)
+	}
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+// Unwrap returns the underlying ResponseWriter, for use by http.ResponseController.
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,195 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package forms_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/immanent-tech/www-immanent-tech/server/forms"
+)
+
+// countingHandler responds with the number of requests it has handled, along with headers that should and should not
+// be replayed.
+func countingHandler(calls *atomic.Int32, status func(call int32) int) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+		call := calls.Add(1)
+		res.Header().Set("Content-Type", "text/html; charset=utf-8")
+		res.Header().Set("Hx-Retarget", "#contact-form")
+		res.Header().Set("Set-Cookie", "session="+strconv.Itoa(int(call)))
+		res.Header().Set("Content-Security-Policy", "script-src 'nonce-"+strconv.Itoa(int(call))+"'")
+		res.WriteHeader(status(call))
+		res.Write([]byte("response " + strconv.Itoa(int(call))))
+	})
+}
+
+func newIdempotentRequest(t *testing.T, path, key string) *http.Request {
+	t.Helper()
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodPost, path, nil)
+	if key != "" {
+		req.Header.Set(forms.HeaderIdempotencyKey, key)
+	}
+	return req
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	var calls atomic.Int32
+	handler := forms.NewIdempotencyStore(time.Minute).Middleware(
+		countingHandler(&calls, func(int32) int { return http.StatusCreated }),
+	)
+
+	first := httptest.NewRecorder()
+	handler.ServeHTTP(first, newIdempotentRequest(t, "/contact", "key"))
+	replayed := httptest.NewRecorder()
+	handler.ServeHTTP(replayed, newIdempotentRequest(t, "/contact", "key"))
+
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("handler called %d times, want 1", got)
+	}
+	if replayed.Code != http.StatusCreated || replayed.Body.String() != "response 1" {
+		t.Errorf("replayed response = %d %q, want %d %q",
+			replayed.Code, replayed.Body, http.StatusCreated, "response 1")
+	}
+	if got := replayed.Header().Get(forms.HeaderIdempotentReplayed); got != "true" {
+		t.Errorf("%s = %q, want true", forms.HeaderIdempotentReplayed, got)
+	}
+	for _, key := range []string{"Content-Type", "Hx-Retarget"} {
+		if got, want := replayed.Header().Get(key), first.Header().Get(key); got != want {
+			t.Errorf("replayed %s = %q, want %q", key, got, want)
+		}
+	}
+	for _, key := range []string{"Set-Cookie", "Content-Security-Policy"} {
+		if got := replayed.Header().Get(key); got != "" {
+			t.Errorf("replayed %s = %q, want it not replayed", key, got)
+		}
+	}
+}
+
+func TestIdempotencyScope(t *testing.T) {
+	tests := []struct {
+		name      string
+		path      string
+		key       string
+		wantCalls int32
+	}{
+		{name: "same key and path", path: "/contact", key: "key", wantCalls: 1},
+		{name: "different key", path: "/contact", key: "other", wantCalls: 2},
+		{name: "different path", path: "/api/v1/contact", key: "key", wantCalls: 2},
+		{name: "no key", path: "/contact", wantCalls: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			handler := forms.NewIdempotencyStore(time.Minute).Middleware(
+				countingHandler(&calls, func(int32) int { return http.StatusOK }),
+			)
+			firstKey := "key"
+			if tt.key == "" {
+				firstKey = ""
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), newIdempotentRequest(t, "/contact", firstKey))
+			handler.ServeHTTP(httptest.NewRecorder(), newIdempotentRequest(t, tt.path, tt.key))
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("handler called %d times, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestIdempotencyNotRecorded(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+	}{
+		{name: "client error", status: http.StatusUnprocessableEntity},
+		{name: "server error", status: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			// The first request fails and the retry succeeds.
+			handler := forms.NewIdempotencyStore(time.Minute).Middleware(
+				countingHandler(&calls, func(call int32) int {
+					if call == 1 {
+						return tt.status
+					}
+					return http.StatusOK
+				}),
+			)
+
+			handler.ServeHTTP(httptest.NewRecorder(), newIdempotentRequest(t, "/contact", "key"))
+			retry := httptest.NewRecorder()
+			handler.ServeHTTP(retry, newIdempotentRequest(t, "/contact", "key"))
+
+			if got := calls.Load(); got != 2 {
+				t.Fatalf("handler called %d times, want 2", got)
+			}
+			if retry.Code != http.StatusOK || retry.Header().Get(forms.HeaderIdempotentReplayed) != "" {
+				t.Errorf("retry = %d, replayed %q, want it processed again",
+					retry.Code, retry.Header().Get(forms.HeaderIdempotentReplayed))
+			}
+		})
+	}
+}
+
+func TestIdempotencyConcurrent(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	started := make(chan struct{})
+	handler := forms.NewIdempotencyStore(time.Minute).Middleware(
+		http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+			calls.Add(1)
+			close(started)
+			<-release
+			res.WriteHeader(http.StatusAccepted)
+			res.Write([]byte("processed"))
+		}),
+	)
+
+	const requests = 5
+	responses := make([]*httptest.ResponseRecorder, requests)
+	var wg sync.WaitGroup
+	// Start the first request, and wait for it to be processing before making the duplicates.
+	responses[0] = httptest.NewRecorder()
+	wg.Go(func() {
+		handler.ServeHTTP(responses[0], newIdempotentRequest(t, "/contact", "key"))
+	})
+	<-started
+	for i := 1; i < requests; i++ {
+		responses[i] = httptest.NewRecorder()
+		wg.Go(func() {
+			handler.ServeHTTP(responses[i], newIdempotentRequest(t, "/contact", "key"))
+		})
+	}
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("handler called %d times, want 1", got)
+	}
+	for i, res := range responses {
+		if res.Code != http.StatusAccepted || res.Body.String() != "processed" {
+			t.Errorf("response %d = %d %q, want %d %q", i, res.Code, res.Body, http.StatusAccepted, "processed")
+		}
+	}
+}
+
+func TestIdempotencyKeyTooLong(t *testing.T) {
+	var calls atomic.Int32
+	handler := forms.NewIdempotencyStore(time.Minute).Middleware(
+		countingHandler(&calls, func(int32) int { return http.StatusOK }),
+	)
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, newIdempotentRequest(t, "/contact", strings.Repeat("k", 256)))
+	if res.Code != http.StatusBadRequest || calls.Load() != 0 {
+		t.Errorf("status = %d after %d calls, want %d without calling the handler",
+			res.Code, calls.Load(), http.StatusBadRequest)
+	}
+}
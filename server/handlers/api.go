@@ -0,0 +1,104 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	slogctx "github.com/veqryn/slog-context"
+
+	"github.com/immanent-tech/www-immanent-tech/models"
+	"github.com/immanent-tech/www-immanent-tech/providers/mailer"
+	"github.com/immanent-tech/www-immanent-tech/server/categories"
+	"github.com/immanent-tech/www-immanent-tech/server/forms"
+	"github.com/immanent-tech/www-immanent-tech/server/respond"
+	"github.com/immanent-tech/www-immanent-tech/server/submissions"
+)
+
+// ContactResponse is the response to a contact submission made through the API.
+type ContactResponse struct {
+	// ID is the ID of the stored submission.
+	ID string `json:"id,omitempty"`
+	// Reference is the reference number of the submission, which can be quoted in any further correspondence.
+	Reference string `json:"reference"`
+}
+
+// HandleAPISubmitContact handles a contact submission made through the API as JSON, sending the details as an email
+// with the given mailer to the recipient of the chosen enquiry category. The request is validated the same way as the
+// contact form, though as API clients are authenticated, the form specific checks (Turnstile and spam heuristics) are
+// not performed. As there is no spam verdict, no auto-reply is sent. Options should be the same as those given to
+// HandleSubmitContact, so that the same store and webhooks are used.
+func HandleAPISubmitContact(
+	sender mailer.Mailer,
+	categories *categories.Categories,
+	options ...ContactOption,
+) http.HandlerFunc {
+	return newContactHandler(sender, categories, options...).submitAPI
+}
+
+func (h *contactHandler) submitAPI(res http.ResponseWriter, req *http.Request) {
+	req.Body = http.MaxBytesReader(res, req.Body, maxFieldsSize)
+
+	request, valid, err := forms.DecodeJSON[*ContactRequest](req)
+	if err != nil || !valid {
+		slogctx.FromCtx(req.Context()).Warn("Could not decode contact submission.",
+			slog.Any("error", err),
+		)
+		var maxBytesErr *http.MaxBytesError
+		switch fieldErrs := forms.NewFieldErrors(request, err); {
+		case len(fieldErrs) > 0:
+			respond.APIError(res, req, http.StatusUnprocessableEntity, &models.APIError{
+				Code:    "validation_failed",
+				Message: "One or more fields are invalid.",
+				Fields:  fieldErrs,
+			})
+		case errors.As(err, &maxBytesErr):
+			respond.APIError(res, req, http.StatusRequestEntityTooLarge, &models.APIError{
+				Code:    "payload_too_large",
+				Message: "The request body is too large.",
+			})
+		case errors.Is(err, forms.ErrNotJSON):
+			respond.APIError(res, req, http.StatusUnsupportedMediaType, &models.APIError{
+				Code:    "unsupported_media_type",
+				Message: "The request body must be application/json.",
+			})
+		default:
+			respond.APIError(res, req, http.StatusBadRequest, &models.APIError{
+				Code:    "invalid_json",
+				Message: "The request body could not be decoded.",
+			})
+		}
+		return
+	}
+
+	submission := &contactSubmission{
+		request: request,
+		source:  submissions.SourceAPI,
+		client:  models.APIClientFromCtx(req.Context()),
+	}
+	if fieldErrs := h.validate(req, submission); fieldErrs != nil {
+		respond.APIError(res, req, http.StatusUnprocessableEntity, &models.APIError{
+			Code:    "validation_failed",
+			Message: "One or more fields are invalid.",
+			Fields:  fieldErrs,
+		})
+		return
+	}
+
+	result, err := h.process(req, submission)
+	if err != nil {
+		respond.APIError(res, req, http.StatusInternalServerError, &models.APIError{
+			Code:    "internal_error",
+			Message: "The submission could not be processed.",
+		})
+		return
+	}
+
+	respond.JSON(res, req, http.StatusAccepted, &ContactResponse{
+		ID:        result.SubmissionID,
+		Reference: result.Reference,
+	})
+}
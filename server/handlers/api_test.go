@@ -0,0 +1,126 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/immanent-tech/www-immanent-tech/models"
+	"github.com/immanent-tech/www-immanent-tech/server/handlers"
+)
+
+func TestAPISubmitContact(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+		wantCode    string
+		wantFields  []string
+		wantSent    int
+	}{
+		{
+			name:        "accepted",
+			contentType: "application/json",
+			body:        `{"category": "general", "contact_email": "visitor@example.com", "details": "Hello"}`,
+			wantStatus:  http.StatusAccepted,
+			wantSent:    1,
+		},
+		{
+			name:        "not json",
+			contentType: "text/plain",
+			body:        `hello`,
+			wantStatus:  http.StatusUnsupportedMediaType,
+			wantCode:    "unsupported_media_type",
+		},
+		{
+			name:        "malformed json",
+			contentType: "application/json",
+			body:        `{"category": `,
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    "invalid_json",
+		},
+		{
+			name:        "unknown field",
+			contentType: "application/json",
+			body:        `{"category": "general", "contact_email": "visitor@example.com", "details": "Hello", "x": 1}`,
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    "invalid_json",
+		},
+		{
+			name:        "missing fields",
+			contentType: "application/json",
+			body:        `{"category": "general"}`,
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantCode:    "validation_failed",
+			wantFields:  []string{"contact_email", "details"},
+		},
+		{
+			name:        "unknown category",
+			contentType: "application/json",
+			body:        `{"category": "unknown", "contact_email": "visitor@example.com", "details": "Hello"}`,
+			wantStatus:  http.StatusUnprocessableEntity,
+			wantCode:    "validation_failed",
+			wantFields:  []string{"category"},
+		},
+		{
+			name:        "too large",
+			contentType: "application/json",
+			body: `{"category": "general", "contact_email": "visitor@example.com", "details": "` +
+				strings.Repeat("a", 2<<20) + `"}`,
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantCode:   "payload_too_large",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := &testMailer{}
+			handler := handlers.HandleAPISubmitContact(sender, newTestCategories(t))
+
+			req := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/api/v1/contact",
+				strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			if res.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", res.Code, tt.wantStatus)
+			}
+			if got := res.Header().Get("Content-Type"); !strings.HasPrefix(got, "application/json") {
+				t.Errorf("Content-Type = %q, want application/json", got)
+			}
+			if got := len(sender.messages()); got != tt.wantSent {
+				t.Errorf("sent %d messages, want %d", got, tt.wantSent)
+			}
+
+			if tt.wantStatus == http.StatusAccepted {
+				var response handlers.ContactResponse
+				if err := json.Unmarshal(res.Body.Bytes(), &response); err != nil {
+					t.Fatalf("decode response: %v", err)
+				}
+				if response.Reference == "" || !strings.Contains(sender.messages()[0].Subject, response.Reference) {
+					t.Errorf("reference = %q, want the reference in the email subject", response.Reference)
+				}
+				return
+			}
+
+			var response models.APIErrorResponse
+			if err := json.Unmarshal(res.Body.Bytes(), &response); err != nil {
+				t.Fatalf("decode error response: %v", err)
+			}
+			if response.Error.Code != tt.wantCode {
+				t.Errorf("error code = %q, want %q", response.Error.Code, tt.wantCode)
+			}
+			for _, field := range tt.wantFields {
+				if _, found := response.Error.Fields[field]; !found {
+					t.Errorf("error fields = %v, want an error for %s", response.Error.Fields, field)
+				}
+			}
+		})
+	}
+}
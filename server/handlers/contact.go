@@ -4,45 +4,140 @@
 package handlers
 
 import (
+	"context"
+	"crypto/rand"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"net/mail"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/a-h/templ"
+	"github.com/angelofallars/htmx-go"
+	"github.com/didip/tollbooth/v8"
+	"github.com/didip/tollbooth/v8/limiter"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/immanent-tech/go-base/validation"
-	"github.com/immanent-tech/www-immanent-tech/providers/fastmail"
+	"github.com/immanent-tech/www-immanent-tech/models"
+	"github.com/immanent-tech/www-immanent-tech/providers/mailer"
+	"github.com/immanent-tech/www-immanent-tech/providers/turnstile"
+	"github.com/immanent-tech/www-immanent-tech/providers/webhook"
+	"github.com/immanent-tech/www-immanent-tech/server/attachments"
+	"github.com/immanent-tech/www-immanent-tech/server/categories"
 	"github.com/immanent-tech/www-immanent-tech/server/forms"
+	"github.com/immanent-tech/www-immanent-tech/server/respond"
+	"github.com/immanent-tech/www-immanent-tech/server/spam"
+	"github.com/immanent-tech/www-immanent-tech/server/submissions"
 	"github.com/immanent-tech/www-immanent-tech/web/templates"
+	"github.com/immanent-tech/www-immanent-tech/web/templates/email"
+	slogchi "github.com/samber/slog-chi"
 	slogctx "github.com/veqryn/slog-context"
 )
 
+const (
+	// maxFieldsSize is the maximum size of a contact form submission, excluding any attachments.
+	maxFieldsSize = 1 << 20
+	// attachmentsField is the name of the contact form field containing attachments.
+	attachmentsField = "attachments"
+	// autoReplyBurst is the number of auto-replies that can be sent to an address in quick succession.
+	autoReplyBurst = 2
+	// autoReplyInterval is the interval after which another auto-reply can be sent to an address, once the burst has
+	// been used.
+	autoReplyInterval = time.Hour
+	// autoReplyGlobalLimit is the number of auto-replies that can be sent to all addresses combined each
+	// autoReplyInterval, so that the form cannot be used to send email to many addresses.
+	autoReplyGlobalLimit = 50
+)
+
+// ContactPageInfo describes the contact page for the sitemap.
+var ContactPageInfo = PageInfo{
+	Path:            "/contact",
+	LastModified:    time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC),
+	ChangeFrequency: ChangeFrequencyYearly,
+	Priority:        0.5,
+}
+
 type ContactPage struct {
-	template templ.Component
+	filter      *spam.Filter
+	categories  *categories.Categories
+	attachments *attachments.Policy
 }
 
 func (p *ContactPage) FullResponse(w http.ResponseWriter, r *http.Request) {
-	templ.Handler(p.template).ServeHTTP(w, r)
+	w.Header().Set("Cache-Control", "no-cache")
+	templ.Handler(p.template()).ServeHTTP(w, r)
 }
 
 func (p *ContactPage) PartialResponse(w http.ResponseWriter, r *http.Request) {
-	templ.Handler(p.template, templ.WithFragments(templates.BodyFragment)).ServeHTTP(w, r)
+	w.Header().Set("Cache-Control", "no-cache")
+	templ.Handler(p.template(), templ.WithFragments(templates.BodyFragment)).ServeHTTP(w, r)
+}
+
+// template renders the contact page. As the form contains per-render values, it is rendered for each request.
+func (p *ContactPage) template() templ.Component {
+	form := newContactForm(p.categories, p.attachments)
+	if p.filter != nil {
+		form.Stamp = p.filter.Stamp(time.Now())
+	}
+	return templates.Page(templates.Contact(form))
+}
+
+// newContactForm creates the contact form with the given categories and attachment limits.
+func newContactForm(categories *categories.Categories, policy *attachments.Policy) *templates.ContactForm {
+	form := &templates.ContactForm{
+		IdempotencyKey: forms.NewIdempotencyKey(),
+	}
+	for _, category := range categories.All() {
+		form.Categories = append(form.Categories, templates.ContactCategory{
+			ID:   category.ID,
+			Name: category.Name,
+		})
+	}
+	if policy != nil && policy.Enabled() {
+		form.Attachments = &templates.ContactAttachments{
+			Field:    attachmentsField,
+			Accept:   policy.Accept(),
+			MaxCount: policy.MaxCount(),
+			MaxSize:  formatSize(policy.MaxSize()),
+		}
+	}
+	return form
 }
 
-func Contact() http.HandlerFunc {
+// Contact handles showing the contact page, with a choice of the given enquiry categories. If a spam filter is
+// provided, the form will include a signed render timestamp for the filter to check on submission. If an attachment
+// policy is provided, the form will allow attaching files within its limits.
+func Contact(
+	filter *spam.Filter,
+	categories *categories.Categories,
+	attachments *attachments.Policy,
+) http.HandlerFunc {
 	page := &ContactPage{
-		template: templates.Page(templates.Contact()),
+		filter:      filter,
+		categories:  categories,
+		attachments: attachments,
 	}
-	return RenderPage(page)
+	return respond.RenderPage(page)
 }
 
 type ContactRequest struct {
+	// Category is the ID of the enquiry category chosen.
+	Category string `form:"category" json:"category" validate:"required"`
+
 	// ContactEmail is the email address the entered for getting in touch about the issue.
 	ContactEmail string `form:"contact_email" json:"contact_email" validate:"required,email"`
 
 	// Details is the text about the issue.
 	Details string `form:"details" json:"details" validate:"required"`
+
+	// Website is a honeypot field that is hidden from humans.
+	Website string `form:"website" json:"-"`
+
+	// Stamp is the signed render timestamp of the form.
+	Stamp string `form:"stamp" json:"-"`
 }
 
 func (r *ContactRequest) Valid() error {
@@ -53,60 +148,622 @@ func (r *ContactRequest) Valid() error {
 }
 
 func (r *ContactRequest) Sanitise() error {
+	r.Category = validation.SanitizeString(r.Category)
 	r.ContactEmail = validation.SanitizeString(r.ContactEmail)
 	r.Details = validation.SanitizeString(r.Details)
 	return nil
 }
 
-func HandleSubmitContact() http.HandlerFunc {
-	return func(res http.ResponseWriter, req *http.Request) {
-		// Validate the subscription issue request.
-		request, valid, err := forms.DecodeMultiPartForm[*ContactRequest](req)
-		if err != nil || !valid {
-			slogctx.FromCtx(req.Context()).Error("Could not decode contact form submission.",
+// ContactOption is a functional option for configuring the contact form submission handler.
+type ContactOption func(*contactHandler)
+
+// WithTurnstile option will verify the Cloudflare Turnstile token of each submission with the given verifier before
+// it is decoded.
+func WithTurnstile(verifier *turnstile.Verifier) ContactOption {
+	return func(h *contactHandler) {
+		h.verifier = verifier
+	}
+}
+
+// WithSpamFilter option will check each submission with the given spam filter. Suspected spam is stored in the
+// quarantine rather than sent, though the submitter is shown the same response as for a successful submission.
+func WithSpamFilter(filter *spam.Filter, quarantine spam.Quarantine) ContactOption {
+	return func(h *contactHandler) {
+		h.filter = filter
+		h.quarantine = quarantine
+	}
+}
+
+// WithSubmissionStore option will record each submission, along with its spam verdict and delivery status, in the
+// given store.
+func WithSubmissionStore(store *submissions.Store) ContactOption {
+	return func(h *contactHandler) {
+		h.store = store
+	}
+}
+
+// WithAutoReply option will send an email from the given address to the submitter, acknowledging their submission
+// with a reference number. The submitted message is not quoted, so that the form cannot be used to send arbitrary
+// content. Auto-replies are only sent for submissions given a clean spam verdict, and are rate-limited both for each
+// address and overall, so that the form cannot be used to send large volumes of email.
+func WithAutoReply(from *mail.Address) ContactOption {
+	// The limiters are shared by all handlers the option is applied to, so that the limits apply across the form and
+	// the API.
+	perAddress := tollbooth.NewLimiter(
+		1/autoReplyInterval.Seconds(),
+		&limiter.ExpirableOptions{DefaultExpirationTTL: autoReplyBurst * autoReplyInterval},
+	)
+	perAddress.SetBurst(autoReplyBurst)
+	global := tollbooth.NewLimiter(
+		autoReplyGlobalLimit/autoReplyInterval.Seconds(),
+		&limiter.ExpirableOptions{DefaultExpirationTTL: autoReplyInterval},
+	)
+	global.SetBurst(autoReplyGlobalLimit)
+	replier := &autoReplier{
+		from:       from,
+		perAddress: perAddress,
+		global:     global,
+	}
+	return func(h *contactHandler) {
+		h.autoReply = replier
+	}
+}
+
+// WithWebhooks option will notify the configured webhooks of each submission with the given notifier, along with the
+// webhook of the category of the submission, if it has one. Suspected spam is not sent to webhooks.
+func WithWebhooks(notifier *webhook.Notifier) ContactOption {
+	return func(h *contactHandler) {
+		h.webhooks = notifier
+	}
+}
+
+// WithAttachments option will accept files attached to each submission, within the limits of the given policy, and
+// forward them with the email.
+func WithAttachments(policy *attachments.Policy) ContactOption {
+	return func(h *contactHandler) {
+		h.attachments = policy
+	}
+}
+
+type contactHandler struct {
+	mailer      mailer.Mailer
+	categories  *categories.Categories
+	verifier    *turnstile.Verifier
+	filter      *spam.Filter
+	quarantine  spam.Quarantine
+	store       *submissions.Store
+	autoReply   *autoReplier
+	webhooks    *webhook.Notifier
+	attachments *attachments.Policy
+}
+
+type autoReplier struct {
+	from       *mail.Address
+	perAddress *limiter.Limiter
+	global     *limiter.Limiter
+}
+
+// autoReplyGlobalKey is the key under which auto-replies to all addresses are counted.
+const autoReplyGlobalKey = "*"
+
+// HandleSubmitContact handles a contact form submission, sending the details as an email with the given mailer to the
+// recipient of the chosen enquiry category.
+func HandleSubmitContact(
+	sender mailer.Mailer,
+	categories *categories.Categories,
+	options ...ContactOption,
+) http.HandlerFunc {
+	return newContactHandler(sender, categories, options...).submit
+}
+
+func newContactHandler(
+	sender mailer.Mailer,
+	categories *categories.Categories,
+	options ...ContactOption,
+) *contactHandler {
+	handler := &contactHandler{
+		mailer:     sender,
+		categories: categories,
+	}
+	for option := range slices.Values(options) {
+		option(handler)
+	}
+	return handler
+}
+
+func (h *contactHandler) submit(res http.ResponseWriter, req *http.Request) {
+	// Limit the size of the submission.
+	maxSize := int64(maxFieldsSize)
+	if h.attachments != nil && h.attachments.Enabled() {
+		maxSize += h.attachments.MaxTotalSize()
+	}
+	req.Body = http.MaxBytesReader(res, req.Body, maxSize)
+
+	// Verify the submission came from a human before it is decoded. As a token can only be verified once, the widget
+	// is reset for any further submission from the page.
+	if h.verifier != nil {
+		if err := forms.ParseMultiPartForm(req); err != nil {
+			slogctx.FromCtx(req.Context()).Warn("Could not read contact form submission.",
 				slog.Any("error", err),
 			)
-			http.Error(res, "Invalid Params", http.StatusUnprocessableEntity)
+			respond.Error(http.StatusUnprocessableEntity,
+				"Unable to read your submission.",
+				"Please check the form and try submitting it again.",
+			).ServeHTTP(res, req)
 			return
 		}
-
-		sender, err := mail.ParseAddress(request.ContactEmail)
-		if err != nil {
-			slogctx.FromCtx(req.Context()).Error("Could not parse email address.",
+		res.Header().Set(htmx.HeaderTrigger, templates.TurnstileResetEvent)
+		if err := h.verifyTurnstile(req); err != nil {
+			slogctx.FromCtx(req.Context()).Warn("Contact form submission failed turnstile verification.",
 				slog.Any("error", err),
 			)
-			http.Error(res, "Invalid Params", http.StatusUnprocessableEntity)
+			h.renderTurnstileError(res, req, err)
+			return
+		}
+	}
+
+	// Validate the subscription issue request.
+	request, valid, err := forms.DecodeMultiPartForm[*ContactRequest](req)
+	if err != nil || !valid {
+		slogctx.FromCtx(req.Context()).Warn("Could not decode contact form submission.",
+			slog.Any("error", err),
+		)
+		if fieldErrs := forms.NewFieldErrors(request, err); len(fieldErrs) > 0 {
+			h.renderFormErrors(res, req, request, fieldErrs)
 			return
 		}
+		respond.Error(http.StatusUnprocessableEntity,
+			"Unable to read your submission.",
+			"Please check the form and try submitting it again.",
+		).ServeHTTP(res, req)
+		return
+	}
 
-		// Build issue body.
-		var bodyBuilder strings.Builder
-		bodyBuilder.WriteString("Contact Email: ")
-		bodyBuilder.WriteString(request.ContactEmail)
-		bodyBuilder.WriteRune('\n')
-		bodyBuilder.WriteString("Details:")
-		bodyBuilder.WriteRune('\n')
-		bodyBuilder.WriteString(request.Details)
-		bodyBuilder.WriteRune('\n')
-
-		if err := fastmail.SendEmail(sender, "Contact Form Submission", bodyBuilder.String()); err != nil {
-			slogctx.FromCtx(req.Context()).Error("Could not send email.",
+	// Read any attachments.
+	var files []*mailer.Attachment
+	if h.attachments != nil && h.attachments.Enabled() {
+		files, err = h.attachments.Read(req.MultipartForm.File[attachmentsField])
+		if err != nil {
+			slogctx.FromCtx(req.Context()).Warn("Could not read contact form attachments.",
 				slog.Any("error", err),
 			)
-			http.Error(res, "Request Failed", http.StatusInternalServerError)
+			h.renderFormErrors(res, req, request, forms.FieldErrors{
+				attachmentsField: h.attachmentError(err),
+			})
 			return
 		}
+	}
+
+	submission := &contactSubmission{
+		request: request,
+		files:   files,
+		source:  submissions.SourceForm,
+	}
+	if fieldErrs := h.validate(req, submission); fieldErrs != nil {
+		h.renderFormErrors(res, req, request, fieldErrs)
+		return
+	}
+
+	// Check for spam.
+	if h.filter != nil {
+		submission.verdict = h.filter.Check(&spam.Submission{
+			Honeypot: request.Website,
+			Stamp:    request.Stamp,
+			Sender:   request.ContactEmail,
+			Content:  request.Details,
+		})
+		slogchi.AddCustomAttributes(req, slog.Int("spam_score", submission.verdict.Score))
+	}
+
+	if _, err := h.process(req, submission); err != nil {
+		respond.Error(http.StatusInternalServerError,
+			"Unable to send your submission.",
+			"Something went wrong on our end. Please try again later.",
+		).ServeHTTP(res, req)
+		return
+	}
+	if h.filter != nil {
+		h.filter.Record(request.ContactEmail)
+	}
+
+	h.renderSuccess(res, req)
+}
+
+// contactSubmission is a decoded contact submission, from either the form or the API.
+type contactSubmission struct {
+	request *ContactRequest
+	files   []*mailer.Attachment
+	// source identifies where the submission was made.
+	source submissions.Source
+	// client is the name of the API client that made the submission, if it was made through the API.
+	client string
+	// verdict is the spam verdict of the submission, if it was checked.
+	verdict *spam.Verdict
+	// from and category are resolved from the request when the submission is validated.
+	from     *mail.Address
+	category *categories.Category
+}
+
+// contactResult is the outcome of processing a contact submission.
+type contactResult struct {
+	Reference    string
+	SubmissionID string
+}
+
+// validate checks the fields of a decoded contact submission that cannot be checked by decoding alone, resolving the
+// sender address and category of the submission. If any fields are invalid, the errors for each are returned.
+func (h *contactHandler) validate(req *http.Request, submission *contactSubmission) forms.FieldErrors {
+	logger := slogctx.FromCtx(req.Context())
+	request := submission.request
+	fieldErrs := make(forms.FieldErrors)
+
+	from, err := mail.ParseAddress(request.ContactEmail)
+	if err != nil {
+		logger.Warn("Could not parse email address.",
+			slog.Any("error", err),
+		)
+		fieldErrs.Add("contact_email", "Enter a valid email address.")
+	}
+
+	category, found := h.categories.Get(request.Category)
+	if !found {
+		logger.Warn("Unknown contact category.",
+			slog.String("category", request.Category),
+		)
+		fieldErrs.Add("category", "Choose one of the available options.")
+	}
+
+	if len(fieldErrs) > 0 {
+		return fieldErrs
+	}
+	slogchi.AddCustomAttributes(req, slog.String("category", category.ID))
 
-		// Show notification of issue reported.
-		RenderPartial(&Notification{
-			notification: &templates.Notification{
+	submission.from, submission.category = from, category
+	return nil
+}
+
+// process processes a contact submission that has been validated, recording it and sending it to the recipient of its
+// category. The submitter is sent an auto-reply and webhooks are notified, if configured. Suspected spam is
+// quarantined, though the result is otherwise the same as a successful submission, so that spammers get no signal.
+func (h *contactHandler) process(req *http.Request, submission *contactSubmission) (*contactResult, error) {
+	logger := slogctx.FromCtx(req.Context())
+	request, from, category := submission.request, submission.from, submission.category
+
+	result := &contactResult{Reference: newReference()}
+
+	// Render the notification email.
+	notification := &email.ContactNotification{
+		Reference:    result.Reference,
+		Category:     category.Name,
+		ContactEmail: request.ContactEmail,
+		Details:      request.Details,
+		Attachments:  attachmentNames(submission.files),
+	}
+	body, err := email.Render(req.Context(), email.ContactNotificationEmail(notification))
+	if err != nil {
+		logger.Error("Could not render email.",
+			slog.Any("error", err),
+		)
+		return nil, fmt.Errorf("render email: %w", err)
+	}
+
+	msg := &mailer.Message{
+		From:        from,
+		To:          []*mail.Address{category.Address()},
+		Subject:     strings.TrimSpace(category.SubjectPrefix + " " + email.ContactNotificationSubject(notification)),
+		Text:        body.Text,
+		HTML:        body.HTML,
+		Attachments: submission.files,
+	}
+
+	// Record the submission.
+	record := h.recordSubmission(req, submission, result.Reference)
+	if record != nil {
+		result.SubmissionID = record.ID
+		msg.Headers = map[string]string{submissions.HeaderID: record.ID}
+	}
+
+	if submission.verdict != nil && submission.verdict.Spam {
+		h.quarantineSpam(req, msg, submission.verdict)
+		h.updateSubmission(req, record, submissions.StatusQuarantined)
+		return result, nil
+	}
+
+	if err := h.mailer.Send(req.Context(), msg); err != nil {
+		logger.Error("Could not send email.",
+			slog.Any("error", err),
+		)
+		h.updateSubmission(req, record, submissions.StatusFailed)
+		return nil, fmt.Errorf("send email: %w", err)
+	}
+	h.updateSubmission(req, record, submissions.StatusQueued)
+
+	if h.webhooks != nil {
+		h.notifyWebhooks(req, category, submission, result.Reference)
+	}
+
+	if h.autoReply != nil && submission.verdict.Clean() {
+		h.sendAutoReply(req, from, result.Reference)
+	}
+
+	return result, nil
+}
+
+// renderSuccess shows a notification that the submission was received, and replaces the submitted form with a fresh
+// one, so that another submission from the page gets a new idempotency key.
+func (h *contactHandler) renderSuccess(res http.ResponseWriter, req *http.Request) {
+	if !htmx.IsHTMX(req) {
+		res.WriteHeader(http.StatusNotAcceptable)
+		return
+	}
+	form := newContactForm(h.categories, h.attachments)
+	form.SwapOOB = true
+	if h.filter != nil {
+		form.Stamp = h.filter.Stamp(time.Now())
+	}
+	templ.Handler(
+		templ.Join(
+			templates.ShowNotification(&templates.Notification{
 				Title: "Thanks for contacting us!",
 				Description: new(
 					"If we need to reach out to discuss, we will send you an email to the address that was submitted.",
 				),
 				Status: http.StatusOK,
-			},
-		}).ServeHTTP(res, req)
+			}),
+			templates.ContactFormFragment(form),
+		),
+	).ServeHTTP(res, req)
+}
+
+// renderFormErrors re-renders the contact form in place of the submitted form, with the submitted values and the
+// given errors shown alongside each field.
+func (h *contactHandler) renderFormErrors(
+	res http.ResponseWriter,
+	req *http.Request,
+	request *ContactRequest,
+	fieldErrs forms.FieldErrors,
+) {
+	form := newContactForm(h.categories, h.attachments)
+	form.Errors = fieldErrs
+	if request != nil {
+		form.Stamp = request.Stamp
+		form.Values = templates.ContactValues{
+			Category:     request.Category,
+			ContactEmail: request.ContactEmail,
+			Details:      request.Details,
+		}
+	}
+	if err := htmx.NewResponse().
+		StatusCode(http.StatusUnprocessableEntity).
+		Retarget("#"+templates.ContactFormID).
+		Reswap(htmx.SwapOuterHTML).
+		RenderTempl(req.Context(), res, templates.ContactFormFragment(form)); err != nil {
+		slogctx.FromCtx(req.Context()).Error("Could not render contact form.",
+			slog.Any("error", err),
+		)
+	}
+}
+
+// attachmentError returns a message explaining why the attachments were not accepted.
+func (h *contactHandler) attachmentError(err error) string {
+	switch {
+	case errors.Is(err, attachments.ErrTooMany):
+		return fmt.Sprintf("Attach no more than %d files.", h.attachments.MaxCount())
+	case errors.Is(err, attachments.ErrTooLarge):
+		return fmt.Sprintf("Attach files no larger than %s each, and no more than %s in total.",
+			formatSize(h.attachments.MaxSize()), formatSize(h.attachments.MaxTotalSize()))
+	case errors.Is(err, attachments.ErrTypeNotAllowed):
+		return "Attach only images (PNG, JPEG, GIF or WebP), PDFs or text files."
+	default:
+		return "Try attaching the files again."
+	}
+}
+
+// recordSubmission stores the submission, if there is a store. Failing to store the submission is logged but does not
+// prevent the submission from being sent.
+func (h *contactHandler) recordSubmission(
+	req *http.Request,
+	contact *contactSubmission,
+	reference string,
+) *submissions.Submission {
+	if h.store == nil {
+		return nil
+	}
+	submission := &submissions.Submission{
+		Reference:    reference,
+		Source:       contact.source,
+		Client:       contact.client,
+		Category:     contact.request.Category,
+		RequestID:    middleware.GetReqID(req.Context()),
+		ClientIPHash: h.store.HashClientIP(models.ClientIPFromCtx(req.Context())),
+		ContactEmail: contact.request.ContactEmail,
+		Details:      contact.request.Details,
+		Attachments:  attachmentNames(contact.files),
+		Spam:         contact.verdict,
 	}
+	if err := h.store.Add(req.Context(), submission); err != nil {
+		slogctx.FromCtx(req.Context()).Error("Could not store contact submission.",
+			slog.Any("error", err),
+		)
+		return nil
+	}
+	slogchi.AddCustomAttributes(req, slog.String("submission_id", submission.ID))
+	return submission
+}
 
+// updateSubmission updates the delivery status of a stored submission.
+func (h *contactHandler) updateSubmission(
+	req *http.Request,
+	submission *submissions.Submission,
+	status submissions.Status,
+) {
+	if submission == nil {
+		return
+	}
+	if err := h.store.SetStatus(submission.ID, status); err != nil {
+		slogctx.FromCtx(req.Context()).Error("Could not update contact submission.",
+			slog.String("submission_id", submission.ID),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// sendAutoReply sends an acknowledgement of the submission to the submitter, unless too many have recently been sent to
+// their address or overall. Failing to send the auto-reply is logged but does not fail the submission.
+func (h *contactHandler) sendAutoReply(req *http.Request, to *mail.Address, reference string) {
+	logger := slogctx.FromCtx(req.Context())
+
+	if h.autoReply.perAddress.LimitReached(strings.ToLower(to.Address)) {
+		logger.Warn("Auto-reply rate-limited.")
+		return
+	}
+	if h.autoReply.global.LimitReached(autoReplyGlobalKey) {
+		logger.Warn("Auto-reply rate-limited globally.")
+		return
+	}
+
+	reply := &email.AutoReply{
+		Reference: reference,
+	}
+	body, err := email.Render(req.Context(), email.AutoReplyEmail(reply))
+	if err != nil {
+		logger.Error("Could not render auto-reply.",
+			slog.Any("error", err),
+		)
+		return
+	}
+	if err := h.mailer.Send(req.Context(), &mailer.Message{
+		From:    h.autoReply.from,
+		To:      []*mail.Address{to},
+		Subject: email.AutoReplySubject(reply),
+		Text:    body.Text,
+		HTML:    body.HTML,
+		Headers: map[string]string{
+			// Identify the message as an automatic response, to prevent auto-reply loops (RFC 3834).
+			"Auto-Submitted":           "auto-replied",
+			"X-Auto-Response-Suppress": "All",
+		},
+	}); err != nil {
+		logger.Error("Could not send auto-reply.",
+			slog.Any("error", err),
+		)
+	}
+}
+
+// notifyWebhooks notifies the webhooks of the submission. Webhooks are notified in the background, so that a slow
+// webhook does not delay the response, and are waited for when the server shuts down. Failing to notify a webhook is
+// logged but does not fail the submission.
+func (h *contactHandler) notifyWebhooks(
+	req *http.Request,
+	category *categories.Category,
+	submission *contactSubmission,
+	reference string,
+) {
+	event := &webhook.Event{
+		Type:         webhook.EventSubmission,
+		Reference:    reference,
+		Category:     category.Name,
+		ContactEmail: submission.request.ContactEmail,
+		Details:      submission.request.Details,
+		Attachments:  attachmentNames(submission.files),
+		CreatedAt:    time.Now().UTC(),
+	}
+	var endpoints []*webhook.Endpoint
+	if category.Webhook != nil {
+		endpoints = append(endpoints, category.Webhook)
+	}
+	ctx := context.WithoutCancel(req.Context())
+	h.webhooks.NotifyInBackground(ctx, event, func(err error) {
+		if err != nil {
+			slogctx.FromCtx(ctx).Error("Could not notify webhooks.",
+				slog.String("reference", reference),
+				slog.Any("error", err),
+			)
+		}
+	}, endpoints...)
+}
+
+// attachmentNames returns the filenames of the attachments.
+func attachmentNames(files []*mailer.Attachment) []string {
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, file.Filename)
+	}
+	return names
+}
+
+// formatSize formats a size in bytes for display.
+func formatSize(size int64) string {
+	if size >= 1<<20 {
+		return fmt.Sprintf("%d MB", size>>20)
+	}
+	return fmt.Sprintf("%d KB", size>>10)
+}
+
+// newReference generates a reference number for a submission, which can be quoted by the submitter in any further
+// correspondence.
+func newReference() string {
+	return "IT-" + rand.Text()[:10]
+}
+
+// quarantineSpam stores a submission identified as spam in the quarantine, if there is one.
+func (h *contactHandler) quarantineSpam(req *http.Request, msg *mailer.Message, verdict *spam.Verdict) {
+	logger := slogctx.FromCtx(req.Context())
+	logger.Warn("Contact form submission suspected as spam.",
+		slog.Int("score", verdict.Score),
+		slog.Any("reasons", verdict.Reasons),
+	)
+	if h.quarantine == nil {
+		return
+	}
+	if err := h.quarantine.Quarantine(req.Context(), msg, verdict); err != nil {
+		logger.Error("Could not quarantine spam.",
+			slog.Any("error", err),
+		)
+	}
+}
+
+// renderTurnstileError shows a notification that the submission could not be verified. If the token was rejected,
+// the submitter is asked to complete the challenge again. Otherwise, verification itself failed and the submitter is
+// asked to try again later.
+func (h *contactHandler) renderTurnstileError(res http.ResponseWriter, req *http.Request, err error) {
+	notification := &templates.Notification{
+		Title:       "Unable to verify your submission.",
+		Description: new("Please complete the challenge and try submitting the form again."),
+		Status:      http.StatusForbidden,
+	}
+	if !errors.Is(err, turnstile.ErrMissingToken) &&
+		!errors.Is(err, turnstile.ErrInvalidToken) &&
+		!errors.Is(err, turnstile.ErrReplayedToken) {
+		notification.Description = new("Something went wrong on our end. Please try again later.")
+		notification.Status = http.StatusServiceUnavailable
+	}
+	respond.RenderPartial(respond.NewNotification(notification, 0)).ServeHTTP(res, req)
+}
+
+// verifyTurnstile verifies the Turnstile token submitted with the form. The outcome of the verification is recorded in
+// the request log.
+func (h *contactHandler) verifyTurnstile(req *http.Request) error {
+	_, err := h.verifier.Verify(req.Context(), req.PostFormValue(turnstile.ResponseField),
+		models.ClientIPFromCtx(req.Context()))
+
+	var outcome string
+	switch {
+	case err == nil:
+		outcome = "success"
+	case errors.Is(err, turnstile.ErrMissingToken):
+		outcome = "missing"
+	case errors.Is(err, turnstile.ErrReplayedToken):
+		outcome = "replayed"
+	case errors.Is(err, turnstile.ErrInvalidToken):
+		outcome = "invalid"
+	default:
+		outcome = "error"
+	}
+	slogchi.AddCustomAttributes(req, slog.String("turnstile", outcome))
+
+	if err != nil {
+		return fmt.Errorf("verify turnstile token: %w", err)
+	}
+	return nil
 }
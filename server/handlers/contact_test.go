@@ -0,0 +1,579 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/angelofallars/htmx-go"
+	"github.com/immanent-tech/go-base/config"
+
+	"github.com/immanent-tech/www-immanent-tech/providers/mailer"
+	"github.com/immanent-tech/www-immanent-tech/providers/turnstile"
+	"github.com/immanent-tech/www-immanent-tech/server/attachments"
+	"github.com/immanent-tech/www-immanent-tech/server/categories"
+	"github.com/immanent-tech/www-immanent-tech/server/handlers"
+	"github.com/immanent-tech/www-immanent-tech/server/spam"
+	"github.com/immanent-tech/www-immanent-tech/web/templates"
+)
+
+// testMailer records the messages it is asked to send.
+type testMailer struct {
+	mu   sync.Mutex
+	sent []*mailer.Message
+}
+
+func (m *testMailer) Send(_ context.Context, msg *mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func (m *testMailer) messages() []*mailer.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.sent
+}
+
+func newTestCategories(t *testing.T) *categories.Categories {
+	t.Helper()
+	list, err := categories.New([]*categories.Category{
+		{ID: "general", Name: "General enquiry", Recipient: "hello@example.com", SubjectPrefix: "[General]"},
+	})
+	if err != nil {
+		t.Fatalf("categories.New() error = %v", err)
+	}
+	return list
+}
+
+// newContactRequest creates an htmx contact form submission with the given fields and attached files, keyed by
+// filename.
+func newContactRequest(t *testing.T, fields map[string]string, files ...map[string][]byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			t.Fatalf("write field: %v", err)
+		}
+	}
+	for _, attached := range files {
+		for filename, data := range attached {
+			part, err := writer.CreateFormFile("attachments", filename)
+			if err != nil {
+				t.Fatalf("create file: %v", err)
+			}
+			part.Write(data)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close form: %v", err)
+	}
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/contact", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Hx-Request", "true")
+	return req
+}
+
+// testQuarantine records the messages quarantined as spam.
+type testQuarantine struct {
+	testMailer
+}
+
+func (q *testQuarantine) Quarantine(ctx context.Context, msg *mailer.Message, _ *spam.Verdict) error {
+	return q.Send(ctx, msg)
+}
+
+func newTestFilter(t *testing.T) *spam.Filter {
+	t.Helper()
+	filter, err := spam.NewWithConfig([]byte("test-key"), spam.Config{
+		Cooldown:  config.NewDuration(time.Minute),
+		Threshold: 5,
+	})
+	if err != nil {
+		t.Fatalf("spam.NewWithConfig() error = %v", err)
+	}
+	return filter
+}
+
+func validContactFields() map[string]string {
+	return map[string]string{
+		"category":              "general",
+		"contact_email":         "visitor@example.com",
+		"details":               "I would like to know more.",
+		turnstile.ResponseField: "token",
+	}
+}
+
+// newTestTurnstile creates a verifier backed by a stub siteverify endpoint that responds with the given status and
+// body, returning the verifier and a count of the verification requests made.
+func newTestTurnstile(t *testing.T, status int, body string) (*turnstile.Verifier, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		res.WriteHeader(status)
+		res.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	verifier, err := turnstile.NewWithConfig(turnstile.Config{Secret: "secret", VerifyURL: server.URL})
+	if err != nil {
+		t.Fatalf("turnstile.NewWithConfig() error = %v", err)
+	}
+	return verifier, &requests
+}
+
+func TestSubmitContactTurnstile(t *testing.T) {
+	tests := []struct {
+		name         string
+		fields       map[string]string
+		status       int
+		body         string
+		wantStatus   int
+		wantRequests int32
+		wantSent     int
+	}{
+		{
+			name:         "verified",
+			fields:       validContactFields(),
+			status:       http.StatusOK,
+			body:         `{"success":true}`,
+			wantStatus:   http.StatusOK,
+			wantRequests: 1,
+			wantSent:     1,
+		},
+		{
+			name: "invalid field is checked after the token",
+			fields: func() map[string]string {
+				fields := validContactFields()
+				fields["category"] = "unknown"
+				return fields
+			}(),
+			status:       http.StatusOK,
+			body:         `{"success":true}`,
+			wantStatus:   http.StatusUnprocessableEntity,
+			wantRequests: 1,
+		},
+		{
+			name: "missing field is checked after the token",
+			fields: func() map[string]string {
+				fields := validContactFields()
+				delete(fields, "details")
+				return fields
+			}(),
+			status:       http.StatusOK,
+			body:         `{"success":true}`,
+			wantStatus:   http.StatusUnprocessableEntity,
+			wantRequests: 1,
+		},
+		{
+			name: "invalid token is rejected before the fields are checked",
+			fields: func() map[string]string {
+				fields := validContactFields()
+				fields["category"] = "unknown"
+				return fields
+			}(),
+			status:       http.StatusOK,
+			body:         `{"success":false,"error-codes":["invalid-input-response"]}`,
+			wantStatus:   http.StatusForbidden,
+			wantRequests: 1,
+		},
+		{
+			name: "missing token",
+			fields: func() map[string]string {
+				fields := validContactFields()
+				delete(fields, turnstile.ResponseField)
+				return fields
+			}(),
+			status:     http.StatusOK,
+			body:       `{"success":true}`,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:         "invalid token",
+			fields:       validContactFields(),
+			status:       http.StatusOK,
+			body:         `{"success":false,"error-codes":["invalid-input-response"]}`,
+			wantStatus:   http.StatusForbidden,
+			wantRequests: 1,
+		},
+		{
+			name:         "replayed token",
+			fields:       validContactFields(),
+			status:       http.StatusOK,
+			body:         `{"success":false,"error-codes":["timeout-or-duplicate"]}`,
+			wantStatus:   http.StatusForbidden,
+			wantRequests: 1,
+		},
+		{
+			name:         "siteverify unavailable",
+			fields:       validContactFields(),
+			status:       http.StatusBadGateway,
+			body:         `{}`,
+			wantStatus:   http.StatusServiceUnavailable,
+			wantRequests: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier, requests := newTestTurnstile(t, tt.status, tt.body)
+			sender := &testMailer{}
+			handler := handlers.HandleSubmitContact(sender, newTestCategories(t), handlers.WithTurnstile(verifier))
+
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, newContactRequest(t, tt.fields))
+
+			if res.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", res.Code, tt.wantStatus)
+			}
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("siteverify received %d requests, want %d", got, tt.wantRequests)
+			}
+			if got := len(sender.messages()); got != tt.wantSent {
+				t.Errorf("mailer sent %d messages, want %d", got, tt.wantSent)
+			}
+			// The token has been used, so the widget must be reset for another submission.
+			if got := res.Header().Get(htmx.HeaderTrigger); got != templates.TurnstileResetEvent {
+				t.Errorf("%s = %q, want %q", htmx.HeaderTrigger, got, templates.TurnstileResetEvent)
+			}
+		})
+	}
+}
+
+func TestSubmitContactSpamCooldown(t *testing.T) {
+	sender, quarantine := &testMailer{}, &testQuarantine{}
+	filter := newTestFilter(t)
+	handler := handlers.HandleSubmitContact(sender, newTestCategories(t), handlers.WithSpamFilter(filter, quarantine))
+
+	fields := validContactFields()
+	fields["stamp"] = filter.Stamp(time.Now().Add(-time.Minute))
+
+	// A submission with an invalid field is rejected without counting towards the cooldown.
+	invalid := maps.Clone(fields)
+	invalid["category"] = "unknown"
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, newContactRequest(t, invalid))
+	if res.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("invalid submission status = %d, want %d", res.Code, http.StatusUnprocessableEntity)
+	}
+
+	// The corrected submission is delivered.
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, newContactRequest(t, fields))
+	if res.Code != http.StatusOK {
+		t.Fatalf("corrected submission status = %d, want %d", res.Code, http.StatusOK)
+	}
+	if len(sender.messages()) != 1 || len(quarantine.messages()) != 0 {
+		t.Fatalf("corrected submission sent %d and quarantined %d messages, want it sent",
+			len(sender.messages()), len(quarantine.messages()))
+	}
+
+	// Another submission within the cooldown is quarantined.
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, newContactRequest(t, fields))
+	if res.Code != http.StatusOK {
+		t.Fatalf("repeated submission status = %d, want %d", res.Code, http.StatusOK)
+	}
+	if len(sender.messages()) != 1 || len(quarantine.messages()) != 1 {
+		t.Errorf("repeated submission sent %d and quarantined %d messages, want it quarantined",
+			len(sender.messages())-1, len(quarantine.messages()))
+	}
+}
+
+func TestSubmitContactAutoReply(t *testing.T) {
+	filter, err := spam.NewWithConfig([]byte("test-key"), spam.Config{Keywords: "casino", Threshold: 5})
+	if err != nil {
+		t.Fatalf("spam.NewWithConfig() error = %v", err)
+	}
+	from := &mail.Address{Address: "noreply@example.com"}
+
+	tests := []struct {
+		name        string
+		details     string
+		filter      *spam.Filter
+		wantReplies int
+	}{
+		{
+			name:        "clean verdict",
+			details:     "I would like to know more.",
+			filter:      filter,
+			wantReplies: 1,
+		},
+		{
+			name:    "suspicious but not spam",
+			details: "I would like to know more about the casino.",
+			filter:  filter,
+		},
+		{
+			name:    "not checked for spam",
+			details: "I would like to know more.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := &testMailer{}
+			options := []handlers.ContactOption{handlers.WithAutoReply(from)}
+			if tt.filter != nil {
+				options = append(options, handlers.WithSpamFilter(tt.filter, &testQuarantine{}))
+			}
+			handler := handlers.HandleSubmitContact(sender, newTestCategories(t), options...)
+
+			fields := validContactFields()
+			fields["details"] = tt.details
+			fields["stamp"] = filter.Stamp(time.Now().Add(-time.Minute))
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, newContactRequest(t, fields))
+			if res.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", res.Code, http.StatusOK)
+			}
+
+			replies := autoReplies(sender, from)
+			if got := len(replies); got != tt.wantReplies {
+				t.Fatalf("sent %d auto-replies, want %d", got, tt.wantReplies)
+			}
+			if tt.wantReplies == 0 {
+				return
+			}
+			reply := replies[0]
+			if len(reply.To) != 1 || reply.To[0].Address != "visitor@example.com" {
+				t.Errorf("auto-reply sent to %v, want visitor@example.com", reply.To)
+			}
+			if strings.Contains(reply.Text, tt.details) || strings.Contains(reply.HTML, tt.details) {
+				t.Error("auto-reply quotes the submitted details")
+			}
+			if got := reply.Headers["Auto-Submitted"]; got != "auto-replied" {
+				t.Errorf("Auto-Submitted = %q, want auto-replied", got)
+			}
+		})
+	}
+}
+
+func TestSubmitContactAutoReplyLimits(t *testing.T) {
+	filter, err := spam.NewWithConfig([]byte("test-key"), spam.Config{Threshold: 5})
+	if err != nil {
+		t.Fatalf("spam.NewWithConfig() error = %v", err)
+	}
+	from := &mail.Address{Address: "noreply@example.com"}
+	sender := &testMailer{}
+	handler := handlers.HandleSubmitContact(sender, newTestCategories(t),
+		handlers.WithSpamFilter(filter, &testQuarantine{}),
+		handlers.WithAutoReply(from),
+	)
+	submit := func(address string) {
+		t.Helper()
+		fields := validContactFields()
+		fields["contact_email"] = address
+		fields["stamp"] = filter.Stamp(time.Now().Add(-time.Minute))
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, newContactRequest(t, fields))
+		if res.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", res.Code, http.StatusOK)
+		}
+	}
+
+	// Each address gets a limited number of auto-replies.
+	for range 3 {
+		submit("Visitor@example.com")
+	}
+	if got := len(autoReplies(sender, from)); got != 2 {
+		t.Fatalf("sent %d auto-replies to one address, want 2", got)
+	}
+
+	// Auto-replies to all addresses combined are also limited.
+	for i := range 100 {
+		submit(fmt.Sprintf("visitor%d@example.com", i))
+	}
+	if got := len(autoReplies(sender, from)); got != 50 {
+		t.Errorf("sent %d auto-replies in total, want 50", got)
+	}
+}
+
+// autoReplies returns the messages sent from the auto-reply address.
+func autoReplies(sender *testMailer, from *mail.Address) []*mailer.Message {
+	var replies []*mailer.Message
+	for _, msg := range sender.messages() {
+		if msg.From.Address == from.Address {
+			replies = append(replies, msg)
+		}
+	}
+	return replies
+}
+
+func TestSubmitContactRouting(t *testing.T) {
+	list, err := categories.New([]*categories.Category{
+		{ID: "general", Name: "General enquiry", Recipient: "hello@example.com", SubjectPrefix: "[General]"},
+		{ID: "press", Name: "Press", Recipient: "Press Office <press@example.com>", SubjectPrefix: "[Press]"},
+	})
+	if err != nil {
+		t.Fatalf("categories.New() error = %v", err)
+	}
+
+	tests := []struct {
+		category      string
+		wantRecipient string
+		wantPrefix    string
+	}{
+		{category: "general", wantRecipient: "hello@example.com", wantPrefix: "[General] "},
+		{category: "press", wantRecipient: "press@example.com", wantPrefix: "[Press] "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.category, func(t *testing.T) {
+			sender := &testMailer{}
+			handler := handlers.HandleSubmitContact(sender, list)
+
+			fields := validContactFields()
+			fields["category"] = tt.category
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, newContactRequest(t, fields))
+			if res.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", res.Code, http.StatusOK)
+			}
+
+			sent := sender.messages()
+			if len(sent) != 1 {
+				t.Fatalf("sent %d messages, want 1", len(sent))
+			}
+			if to := sent[0].To; len(to) != 1 || to[0].Address != tt.wantRecipient {
+				t.Errorf("sent to %v, want %s", to, tt.wantRecipient)
+			}
+			if !strings.HasPrefix(sent[0].Subject, tt.wantPrefix) {
+				t.Errorf("subject = %q, want prefix %q", sent[0].Subject, tt.wantPrefix)
+			}
+		})
+	}
+}
+
+func TestSubmitContactAttachments(t *testing.T) {
+	policy, err := attachments.NewWithConfig(attachments.Config{MaxCount: 1, MaxSize: 1 << 10, MaxTotalSize: 1 << 10})
+	if err != nil {
+		t.Fatalf("attachments.NewWithConfig() error = %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		files           map[string][]byte
+		wantStatus      int
+		wantAttachments []string
+		wantError       string
+	}{
+		{
+			name:            "forwarded",
+			files:           map[string][]byte{"brief.pdf": []byte("%PDF-1.7\n")},
+			wantStatus:      http.StatusOK,
+			wantAttachments: []string{"brief.pdf"},
+		},
+		{
+			name:       "type not allowed",
+			files:      map[string][]byte{"archive.zip": []byte("PK\x03\x04\x14\x00")},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  "Attach only images (PNG, JPEG, GIF or WebP), PDFs or text files.",
+		},
+		{
+			name:       "too large",
+			files:      map[string][]byte{"notes.txt": bytes.Repeat([]byte("a"), 2<<10)},
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  "Attach files no larger than",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := &testMailer{}
+			handler := handlers.HandleSubmitContact(sender, newTestCategories(t), handlers.WithAttachments(policy))
+
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, newContactRequest(t, validContactFields(), tt.files))
+			if res.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", res.Code, tt.wantStatus)
+			}
+			if tt.wantError != "" && !strings.Contains(res.Body.String(), tt.wantError) {
+				t.Errorf("body does not contain %q", tt.wantError)
+			}
+
+			sent := sender.messages()
+			if len(tt.wantAttachments) == 0 {
+				if len(sent) != 0 {
+					t.Errorf("sent %d messages, want none", len(sent))
+				}
+				return
+			}
+			if len(sent) != 1 {
+				t.Fatalf("sent %d messages, want 1", len(sent))
+			}
+			if got := sent[0].Attachments; len(got) != 1 || got[0].Filename != tt.wantAttachments[0] {
+				t.Errorf("attachments = %v, want %v", got, tt.wantAttachments)
+			}
+		})
+	}
+}
+
+func TestSubmitContactFieldErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		fields     map[string]string
+		wantErrors []string
+	}{
+		{
+			name: "missing fields",
+			fields: map[string]string{
+				"category": "general",
+			},
+			wantErrors: []string{"This field is required."},
+		},
+		{
+			name: "invalid address",
+			fields: map[string]string{
+				"category":      "general",
+				"contact_email": "visitor",
+				"details":       "I would like to know more.",
+			},
+			wantErrors: []string{"Enter a valid email address."},
+		},
+		{
+			name: "unknown category",
+			fields: map[string]string{
+				"category":      "unknown",
+				"contact_email": "visitor@example.com",
+				"details":       "I would like to know more.",
+			},
+			wantErrors: []string{"Choose one of the available options."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := &testMailer{}
+			handler := handlers.HandleSubmitContact(sender, newTestCategories(t))
+
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, newContactRequest(t, tt.fields))
+			if res.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("status = %d, want %d", res.Code, http.StatusUnprocessableEntity)
+			}
+			for _, want := range tt.wantErrors {
+				if !strings.Contains(res.Body.String(), want) {
+					t.Errorf("body does not contain %q", want)
+				}
+			}
+			// The submitted values are kept, so they can be corrected.
+			if details := tt.fields["details"]; details != "" && !strings.Contains(res.Body.String(), details) {
+				t.Errorf("body does not contain the submitted details %q", details)
+			}
+			if len(sender.messages()) != 0 {
+				t.Errorf("sent %d messages, want none", len(sender.messages()))
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+// encodings are the content encodings in which encoded content is served, in order of preference.
+var encodings = []string{"br", "zstd", "gzip"}
+
+// brotliLevel is the brotli compression level, which compresses better than gzip while still being fast enough to
+// compress all of the content at startup.
+const brotliLevel = 5
+
+// encodedContent is content stored with a strong ETag and, if compressible, compressed once with each supported
+// encoding, rather than for each request.
+type encodedContent struct {
+	etag        string
+	contentType string
+	content     []byte
+	// bodies are the content compressed with each encoding. Encodings that do not make the content smaller are
+	// omitted.
+	bodies map[string][]byte
+}
+
+// newContent stores the content without any encoding, for content that is not compressible.
+func newContent(content []byte, contentType string) *encodedContent {
+	hash := sha256.Sum256(content)
+	return &encodedContent{
+		etag:        base64.RawURLEncoding.EncodeToString(hash[:16]),
+		contentType: contentType,
+		content:     content,
+	}
+}
+
+// newEncodedContent stores the content compressed with each supported encoding. The encodings are compressed
+// concurrently, to shorten startup.
+func newEncodedContent(content []byte, contentType string) (*encodedContent, error) {
+	bodies := make([][]byte, len(encodings))
+	errs := make([]error, len(encodings))
+	var wg sync.WaitGroup
+	for i, encoding := range encodings {
+		wg.Go(func() {
+			if bodies[i], errs[i] = encode(encoding, content); errs[i] != nil {
+				errs[i] = fmt.Errorf("encode %s: %w", encoding, errs[i])
+			}
+		})
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	encoded := newContent(content, contentType)
+	encoded.bodies = make(map[string][]byte, len(encodings))
+	for i, encoding := range encodings {
+		// Serve the content without an encoding if compressing it does not make it smaller.
+		if len(bodies[i]) < len(content) {
+			encoded.bodies[encoding] = bodies[i]
+		}
+	}
+	return encoded, nil
+}
+
+// ServeHTTP serves the content in the encoding preferred by the client. Conditional requests with a matching ETag
+// receive a 304: Not Modified response. Range and HEAD requests are also handled.
+func (c *encodedContent) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	body := c.content
+	etag := c.etag
+	if c.bodies != nil {
+		res.Header().Add("Vary", "Accept-Encoding")
+		if encoding := negotiateEncoding(req.Header.Get("Accept-Encoding")); encoding != "" {
+			if encoded, found := c.bodies[encoding]; found {
+				// Each encoding is a different representation of the content, so requires its own strong ETag.
+				body = encoded
+				etag += "-" + encoding
+				res.Header().Set("Content-Encoding", encoding)
+			}
+		}
+	}
+	res.Header().Set("ETag", `"`+etag+`"`)
+	res.Header().Set("Content-Type", c.contentType)
+	// The content has no modification time, so conditional requests are handled with the ETag alone.
+	http.ServeContent(res, req, "", time.Time{}, bytes.NewReader(body))
+}
+
+// encode compresses the content with the given encoding. Moderate compression levels are used, so that compressing all
+// of the content does not hold up startup.
+func encode(encoding string, content []byte) ([]byte, error) {
+	var (
+		buf     bytes.Buffer
+		encoder io.WriteCloser
+		err     error
+	)
+	switch encoding {
+	case "br":
+		encoder = brotli.NewWriterLevel(&buf, brotliLevel)
+	case "zstd":
+		encoder, err = zstd.NewWriter(&buf, zstd.WithEncoderLevel(zstd.SpeedDefault))
+	case "gzip":
+		encoder, err = gzip.NewWriterLevel(&buf, gzip.DefaultCompression)
+	default:
+		return nil, fmt.Errorf("unknown encoding %q", encoding)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create encoder: %w", err)
+	}
+	if _, err := encoder.Write(content); err != nil {
+		return nil, fmt.Errorf("write content: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("close encoder: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// negotiateEncoding returns the most preferred encoding accepted by the client, according to its Accept-Encoding
+// header, or an empty string if the client does not accept any encoding.
+func negotiateEncoding(header string) string {
+	accepted := make(map[string]bool)
+	for entry := range strings.SplitSeq(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(entry), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		// An encoding with a quality of 0 is explicitly not accepted.
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if quality, err := strconv.ParseFloat(q, 64); err == nil && quality == 0 {
+				accepted[name] = false
+				continue
+			}
+		}
+		accepted[name] = true
+	}
+	for _, encoding := range encodings {
+		if ok, found := accepted[encoding]; (found && ok) || (!found && accepted["*"]) {
+			return encoding
+		}
+	}
+	return ""
+}
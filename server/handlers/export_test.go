@@ -0,0 +1,37 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package handlers
+
+import "net/http"
+
+// NegotiateEncoding exposes negotiateEncoding for tests.
+var NegotiateEncoding = negotiateEncoding
+
+// RequestedImageWidth exposes requestedImageWidth for tests.
+var RequestedImageWidth = requestedImageWidth
+
+// NegotiateImageFormat exposes negotiateImageFormat for tests, returning the media type of the format, or an empty
+// string if the image itself should be served.
+func NegotiateImageFormat(header, mediaType string) string {
+	if format := negotiateImageFormat(header, mediaType); format != nil {
+		return format.mediaType
+	}
+	return ""
+}
+
+// GenerateSitemaps exposes generateSitemaps for tests, as registering enough pages for a sitemap index is slow.
+func GenerateSitemaps(baseURL string, pages []PageInfo) (map[string]http.Handler, error) {
+	sitemaps, err := generateSitemaps(baseURL, pages)
+	if err != nil {
+		return nil, err
+	}
+	handlers := make(map[string]http.Handler, len(sitemaps))
+	for urlPath, sitemap := range sitemaps {
+		handlers[urlPath] = sitemap
+	}
+	return handlers, nil
+}
+
+// MaxSitemapURLs exposes maxSitemapURLs for tests.
+const MaxSitemapURLs = maxSitemapURLs
@@ -4,52 +4,100 @@
 package handlers
 
 import (
+	"bytes"
 	"fmt"
+	"io/fs"
 	"log/slog"
+	"mime"
 	"net/http"
-	"strings"
+	"path"
+	"slices"
 	"sync"
 
-	"github.com/angelofallars/htmx-go"
+	"github.com/immanent-tech/go-base/config"
 	"github.com/immanent-tech/www-immanent-tech/web"
 	slogctx "github.com/veqryn/slog-context"
 )
 
+// compressibleExtensions are the extensions of static content that is compressed. Other content, such as images and
+// woff2 fonts, is already compressed.
+var compressibleExtensions = []string{".css", ".js", ".svg", ".ico", ".txt", ".json", ".webmanifest"}
+
 // StaticFileHandler handles serving content from the embedded filesystem containing static assets (i.e., images,
-// etc.).
-func StaticFileHandler(fs http.FileSystem) http.HandlerFunc {
+// etc.). Assets requested by their fingerprinted URL (see web.AssetURL) are cached for 1 year, as the URL changes
+// whenever the content does. Other requests are only cached briefly, as the content may change between releases.
+// Stylesheets are served with their url() references rewritten to fingerprinted URLs (see web.RewriteAssetURLs), so
+// that the fonts and images they load are cached as long as they are.
+//
+// All files are loaded when the handler is created, with a strong ETag computed from their content, as the embedded
+// filesystem has no modification times. Compressible files are also compressed with each supported content encoding,
+// so that no compression is done while serving requests. Conditional, range and HEAD requests are supported. Only
+// files are served; directories, which have no listings, and missing files receive a 404: Not Found response.
+func StaticFileHandler(fsys fs.FS) (http.HandlerFunc, error) {
+	files, err := loadStaticContent(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("load static content: %w", err)
+	}
+	notFound := NotFound()
+
 	return func(res http.ResponseWriter, req *http.Request) {
-		// Check, if the requested file is existing.
-		if _, err := fs.Open(req.URL.Path); err != nil {
-			// If file is not found, return HTTP 404 error.
-			http.NotFound(res, req)
+		urlPath := req.URL.Path
+		// Other assets are cached for 1 hour.
+		cacheControl := "public, max-age=3600"
+		if assetPath, found := web.ResolveAsset(urlPath); found {
+			// Fingerprinted assets are cached for 1 year.
+			urlPath = assetPath
+			cacheControl = "public, max-age=31536000, immutable"
+		}
+		file, found := files[urlPath]
+		if !found {
+			notFound(res, req)
 			return
 		}
-		switch {
-		case strings.HasSuffix(req.URL.Path, "js"):
-			// JS files are cached for 1 week.
-			res.Header().Set("Cache-Control", "public, max-age=604800")
-		case strings.HasSuffix(req.URL.Path, "css"):
-			// CSS files are cached for 1 week.
-			res.Header().Set("Cache-Control", "public, max-age=604800")
-		case strings.HasSuffix(req.URL.Path, "woff2"):
-			// Fonts are cached for 1 year.
-			res.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
-		case strings.HasSuffix(req.URL.Path, "png"):
-			fallthrough
-		case strings.HasSuffix(req.URL.Path, "jpg"):
-			fallthrough
-		case strings.HasSuffix(req.URL.Path, "webp"):
-			fallthrough
-		case strings.HasSuffix(req.URL.Path, "svg"):
-			res.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
-		default:
-			// Default is to cache for 1 week.
-			res.Header().Set("Cache-Control", "public, max-age=604800, s-maxage=43200")
+		res.Header().Set("Cache-Control", cacheControl)
+		file.ServeHTTP(res, req)
+	}, nil
+}
+
+// loadStaticContent loads the files of the filesystem, compressing those that are compressible, returning the content
+// keyed by URL path.
+func loadStaticContent(fsys fs.FS) (map[string]*encodedContent, error) {
+	files := make(map[string]*encodedContent)
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
 		}
-		// File is found, return to standard http.FileServer.
-		http.FileServer(fs).ServeHTTP(res, req)
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", name, err)
+		}
+		ext := path.Ext(name)
+		if ext == ".css" {
+			// Serve stylesheets with the fingerprinted URLs of the assets they reference, as they were fingerprinted.
+			data = web.RewriteAssetURLs("/"+name, data)
+		}
+		contentType := mime.TypeByExtension(ext)
+		if contentType == "" {
+			contentType = http.DetectContentType(data)
+		}
+		if !slices.Contains(compressibleExtensions, ext) {
+			files["/"+name] = newContent(data, contentType)
+			return nil
+		}
+		content, err := newEncodedContent(data, contentType)
+		if err != nil {
+			return fmt.Errorf("compress %s: %w", name, err)
+		}
+		files["/"+name] = content
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk static content: %w", err)
 	}
+	return files, nil
 }
 
 var robotsTxt []byte
@@ -59,6 +107,10 @@ var loadRobotsTxt = sync.OnceValue(func() error {
 	if err != nil {
 		return fmt.Errorf("read robots.txt: %w", err)
 	}
+	// Point crawlers at the sitemap, unless robots.txt already does.
+	if !bytes.Contains(robotsTxt, []byte("Sitemap:")) {
+		robotsTxt = append(bytes.TrimRight(robotsTxt, "\n"), "\n\nSitemap: "+config.GetBaseURL()+SitemapPath+"\n"...)
+	}
 	return nil
 })
 
@@ -79,48 +131,3 @@ func RobotsHandler() http.HandlerFunc {
 		}
 	}
 }
-
-type PartialResponseHandler interface {
-	PartialResponse(w http.ResponseWriter, r *http.Request)
-}
-
-type FullResponseHandler interface {
-	PartialResponseHandler
-	FullResponse(w http.ResponseWriter, r *http.Request)
-}
-
-func RenderPage(content FullResponseHandler) http.HandlerFunc {
-	return func(res http.ResponseWriter, req *http.Request) {
-		if content == nil {
-			// If there is no response, return 204: No Content.
-			res.WriteHeader(http.StatusNoContent)
-			return
-		}
-		switch {
-		case !htmx.IsHTMX(req) || htmx.IsHistoryRestoreRequest(req): // Non-HTMX or HistoryRestoreRequests render a full-page.
-			if htmx.IsHistoryRestoreRequest(req) {
-				res.Header().Set("Cache-Control", "private, max-age=0, must-revalidate")
-			}
-			content.FullResponse(res, req)
-		default: // HTMX request renders partial content.
-			content.PartialResponse(res, req)
-		}
-	}
-}
-
-func RenderPartial(content PartialResponseHandler) http.HandlerFunc {
-	return func(res http.ResponseWriter, req *http.Request) {
-		if content == nil {
-			// If there is no response, return 204: No Content.
-			res.WriteHeader(http.StatusNoContent)
-			return
-		}
-		if !htmx.IsHTMX(req) {
-			// If the request is not a HTMX request, return 406: Not Acceptable.
-			res.WriteHeader(http.StatusNotAcceptable)
-			return
-		}
-
-		content.PartialResponse(res, req)
-	}
-}
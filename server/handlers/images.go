@@ -0,0 +1,264 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HugoSmits86/nativewebp"
+	slogctx "github.com/veqryn/slog-context"
+	"golang.org/x/image/draw"
+
+	"github.com/immanent-tech/www-immanent-tech/server/respond"
+	"github.com/immanent-tech/www-immanent-tech/web"
+)
+
+const (
+	// maxImageDPR is the largest device pixel ratio by which a requested image width is scaled.
+	maxImageDPR = 4
+	// jpegQuality is the quality of JPEG variants of lossy images.
+	jpegQuality = 80
+)
+
+// imageFormat is a format in which variants of images are generated.
+type imageFormat struct {
+	mediaType string
+	encode    func(w io.Writer, img image.Image) error
+}
+
+// imageFormats are the formats in which variants of lossless images are generated for clients that accept them, in
+// order of preference. WebP variants are lossless, as there is no pure Go lossy encoder. For the same reason, AVIF
+// variants are not generated.
+var imageFormats = []*imageFormat{
+	{
+		mediaType: "image/webp",
+		encode: func(w io.Writer, img image.Image) error {
+			return nativewebp.Encode(w, img, nil)
+		},
+	},
+}
+
+// pngImageFormat is the format of variants of PNG images for clients that do not accept any of imageFormats.
+var pngImageFormat = &imageFormat{
+	mediaType: "image/png",
+	encode:    (&png.Encoder{CompressionLevel: png.BestCompression}).Encode,
+}
+
+// jpegImageFormat is the format of variants of lossy images, which is accepted by all clients. As with lossless WebP,
+// JPEG is the only lossy format with a pure Go encoder.
+var jpegImageFormat = &imageFormat{
+	mediaType: "image/jpeg",
+	encode: func(w io.Writer, img image.Image) error {
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: jpegQuality})
+	},
+}
+
+// imageVariantKey identifies a variant of an image.
+type imageVariantKey struct {
+	name      string
+	width     int
+	mediaType string
+}
+
+// imageVariant is a generated variant of an image. It is generated once, on first request, by whichever request
+// arrives first, with other requests waiting for it.
+type imageVariant struct {
+	once      sync.Once
+	etag      string
+	mediaType string
+	body      []byte
+	err       error
+}
+
+// imageVariants is the cache of generated image variants. As requested widths are rounded to one of a few variant
+// widths, the cache is bounded by the number of images.
+type imageVariants struct {
+	mu       sync.Mutex
+	variants map[imageVariantKey]*imageVariant
+}
+
+// ImageHandler handles serving resized variants of the images in the static content (see web.ImageURL). The width of
+// the variant is taken from the w query parameter, or from the client hints of the request, and the format is
+// negotiated from the Accept header for lossless images. Lossy images have JPEG variants. Images that are not resized
+// (see web.ImageResizable) are served as they are. Variants are generated on first request and cached in memory. Like
+// static content, fingerprinted URLs are cached for 1 year and other requests only briefly.
+func ImageHandler() http.HandlerFunc {
+	cache := &imageVariants{
+		variants: make(map[imageVariantKey]*imageVariant),
+	}
+	notFound := NotFound()
+	return func(res http.ResponseWriter, req *http.Request) {
+		name, fingerprinted, found := web.ResolveImage(req.URL.Path)
+		if !found {
+			notFound(res, req)
+			return
+		}
+		requested, hinted := requestedImageWidth(req)
+		var format *imageFormat
+		switch {
+		case !web.ImageResizable(name):
+		case web.ImageLossless(name):
+			format = negotiateImageFormat(req.Header.Get("Accept"), mime.TypeByExtension(path.Ext(name)))
+		default:
+			format = jpegImageFormat
+		}
+
+		// Images that are not resized, or have no format the client accepts, are served as they are.
+		key := imageVariantKey{name: name}
+		if format != nil {
+			key.width = web.ImageVariantWidth(name, requested)
+			key.mediaType = format.mediaType
+		}
+		variant := cache.get(key, format)
+		if variant.err != nil {
+			slogctx.FromCtx(req.Context()).Error("Unable to generate image variant.",
+				slog.String("image", name),
+				slog.Any("error", variant.err),
+			)
+			respond.Error(http.StatusInternalServerError,
+				"Unable to load image.",
+				"Please try again later.",
+			).ServeHTTP(res, req)
+			return
+		}
+
+		res.Header().Add("Vary", "Accept")
+		if hinted {
+			res.Header().Add("Vary", "Width, Viewport-Width, DPR")
+		}
+		if fingerprinted {
+			// Fingerprinted images are cached for 1 year.
+			res.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		} else {
+			// Other images are cached for 1 hour.
+			res.Header().Set("Cache-Control", "public, max-age=3600")
+		}
+		res.Header().Set("Content-Type", variant.mediaType)
+		res.Header().Set("ETag", variant.etag)
+		http.ServeContent(res, req, "", time.Time{}, bytes.NewReader(variant.body))
+	}
+}
+
+// get returns the variant of the image, generating it if it has not been. A nil format returns the image itself.
+func (c *imageVariants) get(key imageVariantKey, format *imageFormat) *imageVariant {
+	c.mu.Lock()
+	variant, found := c.variants[key]
+	if !found {
+		variant = &imageVariant{}
+		c.variants[key] = variant
+	}
+	c.mu.Unlock()
+
+	variant.once.Do(func() {
+		variant.body, variant.mediaType, variant.err = generateImageVariant(key.name, key.width, format)
+		if variant.err == nil {
+			hash := sha256.Sum256(variant.body)
+			variant.etag = `"` + base64.RawURLEncoding.EncodeToString(hash[:16]) + `"`
+		}
+	})
+	return variant
+}
+
+// generateImageVariant resizes the named image to the given width, preserving its aspect ratio, and encodes it in the
+// format. If the variant would be no smaller than the image itself, or the format is nil, the image itself is
+// returned.
+func generateImageVariant(name string, width int, format *imageFormat) ([]byte, string, error) {
+	source, err := web.ReadImage(name)
+	if err != nil {
+		return nil, "", fmt.Errorf("read image: %w", err)
+	}
+	sourceType := mime.TypeByExtension(path.Ext(name))
+	if format == nil {
+		return source, sourceType, nil
+	}
+	img, _, err := image.Decode(bytes.NewReader(source))
+	if err != nil {
+		return nil, "", fmt.Errorf("decode image: %w", err)
+	}
+
+	bounds := img.Bounds()
+	if width > 0 && width < bounds.Dx() {
+		height := max(1, (bounds.Dy()*width+bounds.Dx()/2)/bounds.Dx())
+		resized := image.NewNRGBA(image.Rect(0, 0, width, height))
+		draw.CatmullRom.Scale(resized, resized.Bounds(), img, bounds, draw.Src, nil)
+		img = resized
+	}
+
+	var buf bytes.Buffer
+	if err := format.encode(&buf, img); err != nil {
+		return nil, "", fmt.Errorf("encode image as %s: %w", format.mediaType, err)
+	}
+	if buf.Len() >= len(source) {
+		return source, sourceType, nil
+	}
+	return buf.Bytes(), format.mediaType, nil
+}
+
+// requestedImageWidth returns the width of the image, in device pixels, requested by the client. The width is taken
+// from the w query parameter, scaled by the dpr query parameter. Without a w query parameter, the width is taken from
+// the Width client hint, or the Viewport-Width client hint scaled by the DPR client hint, and hinted will be true. A
+// width of 0 requests the full-size image.
+func requestedImageWidth(req *http.Request) (width int, hinted bool) {
+	query := req.URL.Query()
+	if query.Has("w") {
+		width, _ = strconv.Atoi(query.Get("w"))
+		return scaleImageWidth(width, query.Get("dpr")), false
+	}
+	if width, err := strconv.Atoi(req.Header.Get("Width")); err == nil {
+		return width, true
+	}
+	width, _ = strconv.Atoi(req.Header.Get("Viewport-Width"))
+	return scaleImageWidth(width, req.Header.Get("DPR")), true
+}
+
+// scaleImageWidth scales the width by the device pixel ratio, if it is valid.
+func scaleImageWidth(width int, dpr string) int {
+	ratio, err := strconv.ParseFloat(dpr, 64)
+	if err != nil || ratio <= 0 {
+		return max(0, width)
+	}
+	return max(0, int(float64(width)*min(ratio, maxImageDPR)))
+}
+
+// negotiateImageFormat returns the most preferred image format accepted by the client, according to its Accept
+// header, for the variants of a lossless image of the given media type. Only formats explicitly accepted are
+// considered, as wildcards do not indicate support for newer formats. Clients that accept none of them are served PNG
+// variants of PNG images. For other images, nil is returned and the image itself should be served.
+func negotiateImageFormat(header, mediaType string) *imageFormat {
+	accepted := make(map[string]bool)
+	for entry := range strings.SplitSeq(header, ",") {
+		acceptedType, params, _ := strings.Cut(strings.TrimSpace(entry), ";")
+		// A media type with a quality of 0 is explicitly not accepted.
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if quality, err := strconv.ParseFloat(q, 64); err == nil && quality == 0 {
+				continue
+			}
+		}
+		accepted[strings.ToLower(strings.TrimSpace(acceptedType))] = true
+	}
+	for _, format := range imageFormats {
+		if accepted[format.mediaType] {
+			return format
+		}
+	}
+	if mediaType == pngImageFormat.mediaType {
+		return pngImageFormat
+	}
+	return nil
+}
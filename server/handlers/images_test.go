@@ -0,0 +1,260 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package handlers_test
+
+import (
+	"bytes"
+	"image"
+	_ "image/jpeg" // Register the JPEG decoder.
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/immanent-tech/www-immanent-tech/server/handlers"
+	"github.com/immanent-tech/www-immanent-tech/web"
+)
+
+func TestRequestedImageWidth(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		headers    map[string]string
+		wantWidth  int
+		wantHinted bool
+	}{
+		{name: "none", wantWidth: 0, wantHinted: true},
+		{name: "query", query: "?w=320", wantWidth: 320},
+		{name: "query with dpr", query: "?w=320&dpr=2", wantWidth: 640},
+		{name: "query with fractional dpr", query: "?w=300&dpr=1.5", wantWidth: 450},
+		{name: "query with dpr above maximum", query: "?w=100&dpr=10", wantWidth: 400},
+		{name: "query with invalid dpr", query: "?w=320&dpr=x", wantWidth: 320},
+		{name: "query with negative dpr", query: "?w=320&dpr=-2", wantWidth: 320},
+		{name: "negative query", query: "?w=-5", wantWidth: 0},
+		{name: "invalid query", query: "?w=wide", wantWidth: 0},
+		{
+			name:    "query over hints",
+			query:   "?w=320",
+			headers: map[string]string{"Width": "1000"},
+			// The query is the same for every client, so the response does not vary by the hints.
+			wantWidth: 320,
+		},
+		{
+			name:       "width hint",
+			headers:    map[string]string{"Width": "750", "DPR": "2"},
+			wantWidth:  750,
+			wantHinted: true,
+		},
+		{
+			name:       "viewport width hint",
+			headers:    map[string]string{"Viewport-Width": "400", "DPR": "2"},
+			wantWidth:  800,
+			wantHinted: true,
+		},
+		{
+			name:       "viewport width hint without dpr",
+			headers:    map[string]string{"Viewport-Width": "400"},
+			wantWidth:  400,
+			wantHinted: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/images/logo.png"+tt.query, nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			width, hinted := handlers.RequestedImageWidth(req)
+			if width != tt.wantWidth || hinted != tt.wantHinted {
+				t.Errorf("RequestedImageWidth() = %d, %t, want %d, %t", width, hinted, tt.wantWidth, tt.wantHinted)
+			}
+		})
+	}
+}
+
+func TestNegotiateImageFormat(t *testing.T) {
+	tests := []struct {
+		name      string
+		accept    string
+		mediaType string
+		want      string
+	}{
+		{
+			name:      "webp accepted",
+			accept:    "image/avif,image/webp,image/apng,image/svg+xml,image/*,*/*;q=0.8",
+			mediaType: "image/png",
+			want:      "image/webp",
+		},
+		{
+			name:      "webp accepted for a webp image",
+			accept:    "image/webp,*/*",
+			mediaType: "image/webp",
+			want:      "image/webp",
+		},
+		{
+			name:      "case insensitive",
+			accept:    "IMAGE/WEBP",
+			mediaType: "image/jpeg",
+			want:      "image/webp",
+		},
+		{
+			name:      "webp not accepted",
+			accept:    "image/webp;q=0, image/png",
+			mediaType: "image/png",
+			want:      "image/png",
+		},
+		{
+			name:      "wildcard for a png image",
+			accept:    "image/*",
+			mediaType: "image/png",
+			want:      "image/png",
+		},
+		{
+			name:      "wildcard for a webp image",
+			accept:    "*/*",
+			mediaType: "image/webp",
+		},
+		{
+			name:      "no accept header for a jpeg image",
+			mediaType: "image/jpeg",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := handlers.NegotiateImageFormat(tt.accept, tt.mediaType); got != tt.want {
+				t.Errorf("NegotiateImageFormat(%q, %q) = %q, want %q", tt.accept, tt.mediaType, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImageHandler(t *testing.T) {
+	screenshot, err := web.ReadImage("foragd-screenshot.webp")
+	if err != nil {
+		t.Fatalf("read image: %v", err)
+	}
+	logo, err := web.ReadImage("logo.png")
+	if err != nil {
+		t.Fatalf("read image: %v", err)
+	}
+	transparent, err := web.ReadImage("logo-512.webp")
+	if err != nil {
+		t.Fatalf("read image: %v", err)
+	}
+	handler := handlers.ImageHandler()
+
+	tests := []struct {
+		name        string
+		path        string
+		accept      string
+		wantStatus  int
+		wantType    string
+		wantSource  []byte
+		wantSmaller []byte
+	}{
+		{
+			name:        "lossy image is resized",
+			path:        web.ImageURL("foragd-screenshot.webp", 320),
+			accept:      "image/webp",
+			wantStatus:  http.StatusOK,
+			wantType:    "image/jpeg",
+			wantSmaller: screenshot,
+		},
+		{
+			name:        "lossy image is resized without an accept header",
+			path:        web.ImageURL("foragd-screenshot.webp", 320),
+			wantStatus:  http.StatusOK,
+			wantType:    "image/jpeg",
+			wantSmaller: screenshot,
+		},
+		{
+			name:       "lossy image with transparency is served as it is",
+			path:       web.ImageURL("logo-512.webp", 320),
+			accept:     "image/webp",
+			wantStatus: http.StatusOK,
+			wantType:   "image/webp",
+			wantSource: transparent,
+		},
+		{
+			name:        "lossless image is resized",
+			path:        web.ImageURL("logo.png", 320),
+			accept:      "image/webp",
+			wantStatus:  http.StatusOK,
+			wantType:    "image/webp",
+			wantSmaller: logo,
+		},
+		{
+			name:       "missing image",
+			path:       "/images/missing.png",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, tt.path, nil)
+			req.Header.Set("Accept", tt.accept)
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			if res.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", res.Code, tt.wantStatus)
+			}
+			if tt.wantType != "" && res.Header().Get("Content-Type") != tt.wantType {
+				t.Errorf("Content-Type = %q, want %q", res.Header().Get("Content-Type"), tt.wantType)
+			}
+			if tt.wantSource != nil && !bytes.Equal(res.Body.Bytes(), tt.wantSource) {
+				t.Error("body is not the image itself")
+			}
+			if tt.wantSmaller != nil && res.Body.Len() >= len(tt.wantSmaller) {
+				t.Errorf("variant is %d bytes, want fewer than the %d of the image",
+					res.Body.Len(), len(tt.wantSmaller))
+			}
+		})
+	}
+}
+
+// TestImageHandlerSrcset checks that every variant in the srcset of the screenshot on the work page, which is a lossy
+// image, is served resized.
+func TestImageHandlerSrcset(t *testing.T) {
+	const name = "foragd-screenshot.webp"
+	source, err := web.ReadImage(name)
+	if err != nil {
+		t.Fatalf("read image: %v", err)
+	}
+	srcset := web.ImageSrcset(name)
+	if srcset == "" {
+		t.Fatalf("ImageSrcset(%q) is empty", name)
+	}
+	handler := handlers.ImageHandler()
+
+	for candidate := range strings.SplitSeq(srcset, ", ") {
+		url, descriptor, _ := strings.Cut(candidate, " ")
+		t.Run(descriptor, func(t *testing.T) {
+			req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, url, nil)
+			req.Header.Set("Accept", "image/avif,image/webp,*/*")
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			if res.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", res.Code, http.StatusOK)
+			}
+			width, err := strconv.Atoi(strings.TrimSuffix(descriptor, "w"))
+			if err != nil {
+				t.Fatalf("parse descriptor %q: %v", descriptor, err)
+			}
+			body := res.Body.Bytes()
+			img, _, err := image.DecodeConfig(bytes.NewReader(body))
+			if err != nil {
+				t.Fatalf("decode variant: %v", err)
+			}
+			if img.Width != width {
+				t.Errorf("variant is %d pixels wide, want %d", img.Width, width)
+			}
+			if len(body) > len(source) {
+				t.Errorf("variant is %d bytes, want no more than the %d of the image", len(body), len(source))
+			}
+		})
+	}
+}
@@ -4,27 +4,22 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
+	"time"
 
-	"github.com/a-h/templ"
 	"github.com/immanent-tech/www-immanent-tech/web/templates"
 )
 
-type LandingPage struct {
-	template templ.Component
+// LandingPageInfo describes the landing page for the sitemap.
+var LandingPageInfo = PageInfo{
+	Path:            "/",
+	LastModified:    time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC),
+	ChangeFrequency: ChangeFrequencyMonthly,
+	Priority:        1,
 }
 
-func NewLandingPage() http.HandlerFunc {
-	page := &LandingPage{
-		template: templates.Page(templates.Landing()),
-	}
-	return RenderPage(page)
-}
-
-func (p *LandingPage) FullResponse(w http.ResponseWriter, r *http.Request) {
-	templ.Handler(p.template).ServeHTTP(w, r)
-}
-
-func (p *LandingPage) PartialResponse(w http.ResponseWriter, r *http.Request) {
-	templ.Handler(p.template, templ.WithFragments(templates.BodyFragment)).ServeHTTP(w, r)
+// NewLandingPage handles showing the landing page, which is pre-rendered.
+func NewLandingPage(ctx context.Context) (http.HandlerFunc, error) {
+	return NewPrerenderedPage(ctx, templates.Page(templates.Landing()))
 }
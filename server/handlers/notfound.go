@@ -7,6 +7,7 @@ import (
 	"net/http"
 
 	"github.com/a-h/templ"
+	"github.com/immanent-tech/www-immanent-tech/server/respond"
 	"github.com/immanent-tech/www-immanent-tech/web/templates"
 )
 
@@ -14,7 +15,7 @@ type NotFoundPage struct{}
 
 // NotFound handles showing a page for a 404 response.
 func NotFound() http.HandlerFunc {
-	return RenderPage(&NotFoundPage{})
+	return respond.RenderPage(&NotFoundPage{})
 }
 
 func (p *NotFoundPage) FullResponse(w http.ResponseWriter, r *http.Request) {
@@ -1,24 +0,0 @@
-// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
-// SPDX-License-Identifier: 	AGPL-3.0-or-later
-
-package handlers
-
-import (
-	"net/http"
-	"time"
-
-	"github.com/a-h/templ"
-	"github.com/immanent-tech/www-immanent-tech/web/templates"
-)
-
-// Notification contains a message that will be displayed to the user as a notification.
-type Notification struct {
-	notification *templates.Notification
-	timeout      time.Duration
-}
-
-// PartialResponse renders the notification into the notification container on the page as an OOB response.
-func (n *Notification) PartialResponse(res http.ResponseWriter, req *http.Request) {
-	templ.Handler(templates.ShowNotification(n.notification, templates.WithNotificationTimeout(n.timeout))).
-		ServeHTTP(res, req)
-}
@@ -0,0 +1,93 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/immanent-tech/go-base/validation"
+)
+
+// ChangeFrequency is how often the content of a page is expected to change, as a hint for search engines.
+type ChangeFrequency string
+
+const (
+	ChangeFrequencyAlways  ChangeFrequency = "always"
+	ChangeFrequencyHourly  ChangeFrequency = "hourly"
+	ChangeFrequencyDaily   ChangeFrequency = "daily"
+	ChangeFrequencyWeekly  ChangeFrequency = "weekly"
+	ChangeFrequencyMonthly ChangeFrequency = "monthly"
+	ChangeFrequencyYearly  ChangeFrequency = "yearly"
+	ChangeFrequencyNever   ChangeFrequency = "never"
+)
+
+var (
+	// ErrDuplicatePage is returned when a page is registered more than once.
+	ErrDuplicatePage = errors.New("page already registered")
+	// ErrUnroutedPage is returned when a registered page has no GET route.
+	ErrUnroutedPage = errors.New("page not routed")
+)
+
+// PageInfo describes a public page, for listing in the sitemap. LastModified should be updated whenever the content of
+// the page changes. A zero LastModified, ChangeFrequency or Priority is omitted from the sitemap, where search engines
+// treat the priority as 0.5.
+type PageInfo struct {
+	Path            string          `validate:"required,startswith=/"`
+	LastModified    time.Time       `validate:"omitempty"`
+	ChangeFrequency ChangeFrequency `validate:"omitempty,oneof=always hourly daily weekly monthly yearly never"`
+	Priority        float64         `validate:"min=0,max=1"`
+}
+
+// PageRegistry is the registry of the public pages of the site, from which the sitemap is generated.
+type PageRegistry struct {
+	pages []PageInfo
+}
+
+// NewPageRegistry creates a new PageRegistry of the given pages. Each page must have a unique, absolute path.
+func NewPageRegistry(pages ...PageInfo) (*PageRegistry, error) {
+	registry := &PageRegistry{}
+	for page := range slices.Values(pages) {
+		if err := validation.Validate.Struct(page); err != nil {
+			return nil, fmt.Errorf("validate page %s: %w", page.Path, err)
+		}
+		if slices.ContainsFunc(registry.pages, func(registered PageInfo) bool {
+			return registered.Path == page.Path
+		}) {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicatePage, page.Path)
+		}
+		registry.pages = append(registry.pages, page)
+	}
+	return registry, nil
+}
+
+// Pages returns the registered pages, in the order they were registered.
+func (r *PageRegistry) Pages() []PageInfo {
+	return slices.Clone(r.pages)
+}
+
+// Verify checks that each registered page has a GET route in the route table, so that the sitemap does not list pages
+// that are not served.
+func (r *PageRegistry) Verify(routes chi.Routes) error {
+	routed := make(map[string]bool)
+	err := chi.Walk(routes, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if method == http.MethodGet {
+			routed[route] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("walk routes: %w", err)
+	}
+	for page := range slices.Values(r.pages) {
+		if !routed[page.Path] {
+			return fmt.Errorf("%w: %s", ErrUnroutedPage, page.Path)
+		}
+	}
+	return nil
+}
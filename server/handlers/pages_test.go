@@ -0,0 +1,130 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package handlers_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/immanent-tech/www-immanent-tech/server/handlers"
+)
+
+func TestNewPageRegistry(t *testing.T) {
+	tests := []struct {
+		name    string
+		pages   []handlers.PageInfo
+		wantErr bool
+		is      error
+	}{
+		{
+			name:  "valid",
+			pages: []handlers.PageInfo{handlers.LandingPageInfo, handlers.WorkPageInfo, handlers.ContactPageInfo},
+		},
+		{
+			name:  "only path",
+			pages: []handlers.PageInfo{{Path: "/about"}},
+		},
+		{
+			name:    "duplicate",
+			pages:   []handlers.PageInfo{{Path: "/about"}, {Path: "/about", Priority: 0.5}},
+			wantErr: true,
+			is:      handlers.ErrDuplicatePage,
+		},
+		{
+			name:    "missing path",
+			pages:   []handlers.PageInfo{{Priority: 0.5}},
+			wantErr: true,
+		},
+		{
+			name:    "relative path",
+			pages:   []handlers.PageInfo{{Path: "about"}},
+			wantErr: true,
+		},
+		{
+			name:    "priority above 1",
+			pages:   []handlers.PageInfo{{Path: "/about", Priority: 1.5}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown change frequency",
+			pages:   []handlers.PageInfo{{Path: "/about", ChangeFrequency: "fortnightly"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry, err := handlers.NewPageRegistry(tt.pages...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewPageRegistry() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if tt.is != nil && !errors.Is(err, tt.is) {
+				t.Errorf("NewPageRegistry() error = %v, want %v", err, tt.is)
+			}
+			if err == nil && len(registry.Pages()) != len(tt.pages) {
+				t.Errorf("registered %d pages, want %d", len(registry.Pages()), len(tt.pages))
+			}
+		})
+	}
+}
+
+func TestPageRegistryPages(t *testing.T) {
+	registry, err := handlers.NewPageRegistry(handlers.PageInfo{Path: "/b"}, handlers.PageInfo{Path: "/a"})
+	if err != nil {
+		t.Fatalf("NewPageRegistry() error = %v", err)
+	}
+	pages := registry.Pages()
+	if len(pages) != 2 || pages[0].Path != "/b" || pages[1].Path != "/a" {
+		t.Fatalf("Pages() = %v, want the pages in the order they were registered", pages)
+	}
+	// The registered pages cannot be changed through the returned pages.
+	pages[0].Path = "/changed"
+	if registry.Pages()[0].Path != "/b" {
+		t.Error("changing the returned pages changed the registry")
+	}
+}
+
+func TestPageRegistryVerify(t *testing.T) {
+	handler := func(http.ResponseWriter, *http.Request) {}
+	router := chi.NewRouter()
+	router.Get("/", handler)
+	router.Post("/contact", handler)
+	router.Route("/docs", func(r chi.Router) {
+		r.Get("/guide", handler)
+	})
+
+	tests := []struct {
+		name    string
+		pages   []handlers.PageInfo
+		wantErr error
+	}{
+		{
+			name:  "routed",
+			pages: []handlers.PageInfo{{Path: "/"}, {Path: "/docs/guide"}},
+		},
+		{
+			name:    "not routed",
+			pages:   []handlers.PageInfo{{Path: "/"}, {Path: "/work"}},
+			wantErr: handlers.ErrUnroutedPage,
+		},
+		{
+			name:    "only routed for POST",
+			pages:   []handlers.PageInfo{{Path: "/contact"}},
+			wantErr: handlers.ErrUnroutedPage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry, err := handlers.NewPageRegistry(tt.pages...)
+			if err != nil {
+				t.Fatalf("NewPageRegistry() error = %v", err)
+			}
+			if err := registry.Verify(router); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/a-h/templ"
+
+	"github.com/immanent-tech/www-immanent-tech/server/respond"
+	"github.com/immanent-tech/www-immanent-tech/web/templates"
+)
+
+// pageContentType is the content type of pre-rendered pages.
+const pageContentType = "text/html; charset=utf-8"
+
+// PrerenderedPage is a page that does not depend on any request data, so it is rendered once, rather than for each
+// request. The full page and the partial body fragment are each stored with a strong ETag and compressed with every
+// supported content encoding, so that requests are served without rendering, hashing or compressing.
+type PrerenderedPage struct {
+	full    *encodedContent
+	partial *encodedContent
+}
+
+// NewPrerenderedPage renders the page template once, returning a handler that serves the rendered page. The template
+// must not depend on any request data, as it is rendered without a request. As such, it is rendered without a CSP
+// nonce or CSRF token, so it must only load scripts and styles from sources allowed by the content security policy.
+func NewPrerenderedPage(ctx context.Context, template templ.Component) (http.HandlerFunc, error) {
+	// Any nonce in the context would be rendered into the page, where it would not match the nonce of later requests.
+	ctx = templ.WithNonce(ctx, "")
+
+	var full, partial bytes.Buffer
+	if err := template.Render(ctx, &full); err != nil {
+		return nil, fmt.Errorf("render page: %w", err)
+	}
+	if err := templ.RenderFragments(ctx, &partial, template, templates.BodyFragment); err != nil {
+		return nil, fmt.Errorf("render page fragment: %w", err)
+	}
+
+	page := &PrerenderedPage{}
+	var err error
+	if page.full, err = newEncodedContent(full.Bytes(), pageContentType); err != nil {
+		return nil, fmt.Errorf("prerender page: %w", err)
+	}
+	if page.partial, err = newEncodedContent(partial.Bytes(), pageContentType); err != nil {
+		return nil, fmt.Errorf("prerender page fragment: %w", err)
+	}
+	return respond.RenderPage(page), nil
+}
+
+func (p *PrerenderedPage) FullResponse(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache")
+	p.full.ServeHTTP(w, r)
+}
+
+func (p *PrerenderedPage) PartialResponse(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache")
+	p.partial.ServeHTTP(w, r)
+}
@@ -0,0 +1,112 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+
+	"github.com/immanent-tech/www-immanent-tech/server/handlers"
+)
+
+func TestPrerenderedPageCaching(t *testing.T) {
+	pages := map[string]func() (http.HandlerFunc, error){
+		"landing": func() (http.HandlerFunc, error) { return handlers.NewLandingPage(t.Context()) },
+		"work":    func() (http.HandlerFunc, error) { return handlers.NewWorkPage(t.Context()) },
+	}
+	for name, newPage := range pages {
+		t.Run(name, func(t *testing.T) {
+			page, err := newPage()
+			if err != nil {
+				t.Fatalf("create page: %v", err)
+			}
+			// The page is rendered without a nonce, so the nonce of a request is never served to another.
+			req := httptest.NewRequestWithContext(templ.WithNonce(t.Context(), "NONCE"), http.MethodGet, "/", nil)
+			res := httptest.NewRecorder()
+			page.ServeHTTP(res, req)
+
+			if res.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", res.Code, http.StatusOK)
+			}
+			if body := res.Body.String(); strings.Contains(body, "nonce") {
+				t.Error("page contains a nonce")
+			}
+			etag := res.Header().Get("ETag")
+			if !strings.HasPrefix(etag, `"`) {
+				t.Errorf("ETag = %q, want a strong ETag", etag)
+			}
+			if got := res.Header().Get("Cache-Control"); got != "no-cache" {
+				t.Errorf("Cache-Control = %q, want %q", got, "no-cache")
+			}
+
+			// The page is revalidated with its ETag.
+			req = httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+			req.Header.Set("If-None-Match", etag)
+			res = httptest.NewRecorder()
+			page.ServeHTTP(res, req)
+			if res.Code != http.StatusNotModified {
+				t.Errorf("revalidation status = %d, want %d", res.Code, http.StatusNotModified)
+			}
+		})
+	}
+}
+
+func TestPrerenderedPageResponses(t *testing.T) {
+	page, err := handlers.NewLandingPage(t.Context())
+	if err != nil {
+		t.Fatalf("create page: %v", err)
+	}
+	tests := []struct {
+		name     string
+		method   string
+		htmx     bool
+		wantPage bool
+		wantBody bool
+	}{
+		{
+			name:     "full page",
+			method:   http.MethodGet,
+			wantPage: true,
+			wantBody: true,
+		},
+		{
+			name:     "htmx partial",
+			method:   http.MethodGet,
+			htmx:     true,
+			wantBody: true,
+		},
+		{
+			name:   "head",
+			method: http.MethodHead,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequestWithContext(templ.WithNonce(t.Context(), "NONCE"), tt.method, "/", nil)
+			if tt.htmx {
+				req.Header.Set("HX-Request", "true")
+			}
+			res := httptest.NewRecorder()
+			page.ServeHTTP(res, req)
+
+			if res.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", res.Code, http.StatusOK)
+			}
+			if got := res.Header().Get("Content-Type"); got != "text/html; charset=utf-8" {
+				t.Errorf("Content-Type = %q", got)
+			}
+			body := res.Body.String()
+			if hasBody := body != ""; hasBody != tt.wantBody {
+				t.Errorf("has body = %t, want %t", hasBody, tt.wantBody)
+			}
+			if isPage := strings.Contains(body, "<html"); isPage != tt.wantPage {
+				t.Errorf("rendered full page = %t, want %t", isPage, tt.wantPage)
+			}
+		})
+	}
+}
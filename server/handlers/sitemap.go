@@ -0,0 +1,158 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/immanent-tech/go-base/config"
+)
+
+const (
+	// SitemapPath is the URL path of the sitemap, or the sitemap index when the pages do not fit in one sitemap.
+	SitemapPath = "/sitemap.xml"
+	// SitemapPartPattern is the route pattern of the sitemaps listed in the sitemap index.
+	SitemapPartPattern = "/sitemap-{part:[0-9]+}.xml"
+	// maxSitemapURLs is the maximum number of URLs in a sitemap, as defined by the sitemap protocol.
+	maxSitemapURLs = 50000
+	// sitemapNamespace is the XML namespace of sitemaps and sitemap indexes.
+	sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	// sitemapContentType is the content type of sitemaps and sitemap indexes.
+	sitemapContentType = "application/xml; charset=utf-8"
+)
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod,omitempty"`
+	ChangeFreq string `xml:"changefreq,omitempty"`
+	Priority   string `xml:"priority,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name         `xml:"sitemapindex"`
+	XMLNS    string           `xml:"xmlns,attr"`
+	Sitemaps []sitemapPointer `xml:"sitemap"`
+}
+
+type sitemapPointer struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// SitemapHandler handles serving the sitemap of the registered pages, with absolute URLs from the base URL of the
+// site. The sitemap is generated once, when the handler is created, and served with a strong ETag and compressed. If
+// there are more pages than fit in one sitemap, the sitemap path serves a sitemap index instead, listing sitemaps at
+// /sitemap-1.xml, /sitemap-2.xml, etc. (see SitemapPartPattern).
+func SitemapHandler(registry *PageRegistry) (http.HandlerFunc, error) {
+	sitemaps, err := generateSitemaps(config.GetBaseURL(), registry.Pages())
+	if err != nil {
+		return nil, fmt.Errorf("generate sitemap: %w", err)
+	}
+	notFound := NotFound()
+
+	return func(res http.ResponseWriter, req *http.Request) {
+		sitemap, found := sitemaps[req.URL.Path]
+		if !found {
+			notFound(res, req)
+			return
+		}
+		res.Header().Set("Cache-Control", "public, max-age=86400")
+		sitemap.ServeHTTP(res, req)
+	}, nil
+}
+
+// generateSitemaps generates the sitemaps of the pages, keyed by URL path.
+func generateSitemaps(baseURL string, pages []PageInfo) (map[string]*encodedContent, error) {
+	sitemaps := make(map[string]*encodedContent)
+
+	parts := slices.Collect(slices.Chunk(pages, maxSitemapURLs))
+	if len(parts) <= 1 {
+		content, err := encodeSitemap(newSitemapURLSet(baseURL, pages))
+		if err != nil {
+			return nil, err
+		}
+		sitemaps[SitemapPath] = content
+		return sitemaps, nil
+	}
+
+	index := sitemapIndex{XMLNS: sitemapNamespace}
+	for i, part := range parts {
+		partPath := "/sitemap-" + strconv.Itoa(i+1) + ".xml"
+		content, err := encodeSitemap(newSitemapURLSet(baseURL, part))
+		if err != nil {
+			return nil, err
+		}
+		sitemaps[partPath] = content
+		index.Sitemaps = append(index.Sitemaps, sitemapPointer{
+			Loc:     baseURL + partPath,
+			LastMod: formatLastModified(latestModified(part)),
+		})
+	}
+	content, err := encodeSitemap(index)
+	if err != nil {
+		return nil, err
+	}
+	sitemaps[SitemapPath] = content
+	return sitemaps, nil
+}
+
+// newSitemapURLSet creates a sitemap listing the pages.
+func newSitemapURLSet(baseURL string, pages []PageInfo) sitemapURLSet {
+	urlSet := sitemapURLSet{XMLNS: sitemapNamespace}
+	for page := range slices.Values(pages) {
+		url := sitemapURL{
+			Loc:        baseURL + page.Path,
+			LastMod:    formatLastModified(page.LastModified),
+			ChangeFreq: string(page.ChangeFrequency),
+		}
+		if page.Priority > 0 {
+			url.Priority = strconv.FormatFloat(page.Priority, 'f', 1, 64)
+		}
+		urlSet.URLs = append(urlSet.URLs, url)
+	}
+	return urlSet
+}
+
+// encodeSitemap encodes the sitemap or sitemap index as XML, stored with each supported content encoding.
+func encodeSitemap(sitemap any) (*encodedContent, error) {
+	body, err := xml.MarshalIndent(sitemap, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal sitemap: %w", err)
+	}
+	content, err := newEncodedContent(append([]byte(xml.Header), body...), sitemapContentType)
+	if err != nil {
+		return nil, fmt.Errorf("encode sitemap: %w", err)
+	}
+	return content, nil
+}
+
+// latestModified returns the latest last modified time of the pages.
+func latestModified(pages []PageInfo) time.Time {
+	var latest time.Time
+	for page := range slices.Values(pages) {
+		if page.LastModified.After(latest) {
+			latest = page.LastModified
+		}
+	}
+	return latest
+}
+
+// formatLastModified formats the last modified time as a W3C date, or an empty string for the zero time.
+func formatLastModified(lastModified time.Time) string {
+	if lastModified.IsZero() {
+		return ""
+	}
+	return lastModified.UTC().Format(time.DateOnly)
+}
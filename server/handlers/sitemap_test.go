@@ -0,0 +1,145 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package handlers_test
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/immanent-tech/go-base/config"
+
+	"github.com/immanent-tech/www-immanent-tech/server/handlers"
+)
+
+type testURLSet struct {
+	XMLName xml.Name `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []struct {
+		Loc        string `xml:"loc"`
+		LastMod    string `xml:"lastmod"`
+		ChangeFreq string `xml:"changefreq"`
+		Priority   string `xml:"priority"`
+	} `xml:"url"`
+}
+
+type testSitemapIndex struct {
+	XMLName  xml.Name `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 sitemapindex"`
+	Sitemaps []struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod"`
+	} `xml:"sitemap"`
+}
+
+// getSitemap requests the path from the handler and decodes the response into sitemap.
+func getSitemap(t *testing.T, handler http.Handler, urlPath string, sitemap any) *httptest.ResponseRecorder {
+	t.Helper()
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequestWithContext(t.Context(), http.MethodGet, urlPath, nil))
+	if res.Code != http.StatusOK {
+		t.Fatalf("GET %s status = %d, want %d", urlPath, res.Code, http.StatusOK)
+	}
+	if err := xml.Unmarshal(res.Body.Bytes(), sitemap); err != nil {
+		t.Fatalf("decode %s: %v", urlPath, err)
+	}
+	return res
+}
+
+func TestSitemapHandler(t *testing.T) {
+	registry, err := handlers.NewPageRegistry(
+		handlers.PageInfo{
+			Path:            "/",
+			LastModified:    time.Date(2026, time.October, 17, 9, 30, 0, 0, time.UTC),
+			ChangeFrequency: handlers.ChangeFrequencyMonthly,
+			Priority:        1,
+		},
+		handlers.PageInfo{Path: "/about"},
+	)
+	if err != nil {
+		t.Fatalf("NewPageRegistry() error = %v", err)
+	}
+	handler, err := handlers.SitemapHandler(registry)
+	if err != nil {
+		t.Fatalf("SitemapHandler() error = %v", err)
+	}
+
+	var sitemap testURLSet
+	res := getSitemap(t, handler, handlers.SitemapPath, &sitemap)
+	if got := res.Header().Get("Content-Type"); got != "application/xml; charset=utf-8" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if len(sitemap.URLs) != 2 {
+		t.Fatalf("sitemap lists %d pages, want 2", len(sitemap.URLs))
+	}
+	landing := sitemap.URLs[0]
+	if landing.Loc != config.GetBaseURL()+"/" || landing.LastMod != "2026-10-17" ||
+		landing.ChangeFreq != "monthly" || landing.Priority != "1.0" {
+		t.Errorf("landing page = %+v", landing)
+	}
+	// Zero values are omitted.
+	about := sitemap.URLs[1]
+	if about.Loc != config.GetBaseURL()+"/about" ||
+		about.LastMod != "" || about.ChangeFreq != "" || about.Priority != "" {
+		t.Errorf("about page = %+v", about)
+	}
+
+	// Sitemaps are served with a strong ETag.
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, handlers.SitemapPath, nil)
+	req.Header.Set("If-None-Match", res.Header().Get("ETag"))
+	conditional := httptest.NewRecorder()
+	handler.ServeHTTP(conditional, req)
+	if conditional.Code != http.StatusNotModified {
+		t.Errorf("conditional status = %d, want %d", conditional.Code, http.StatusNotModified)
+	}
+
+	// Without a sitemap index, there are no parts.
+	missing := httptest.NewRecorder()
+	handler.ServeHTTP(missing, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/sitemap-1.xml", nil))
+	if missing.Code != http.StatusNotFound {
+		t.Errorf("part status = %d, want %d", missing.Code, http.StatusNotFound)
+	}
+}
+
+func TestSitemapIndex(t *testing.T) {
+	const baseURL = "https://example.com"
+	pages := make([]handlers.PageInfo, handlers.MaxSitemapURLs+1)
+	for i := range pages {
+		pages[i] = handlers.PageInfo{Path: "/page-" + strconv.Itoa(i)}
+	}
+	pages[len(pages)-1].LastModified = time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	sitemaps, err := handlers.GenerateSitemaps(baseURL, pages)
+	if err != nil {
+		t.Fatalf("GenerateSitemaps() error = %v", err)
+	}
+	if len(sitemaps) != 3 {
+		t.Fatalf("generated %d sitemaps, want an index and 2 parts", len(sitemaps))
+	}
+
+	var index testSitemapIndex
+	getSitemap(t, sitemaps[handlers.SitemapPath], handlers.SitemapPath, &index)
+	if len(index.Sitemaps) != 2 {
+		t.Fatalf("index lists %d sitemaps, want 2", len(index.Sitemaps))
+	}
+	for i, want := range []struct {
+		loc     string
+		lastMod string
+		urls    int
+	}{
+		{loc: baseURL + "/sitemap-1.xml", urls: handlers.MaxSitemapURLs},
+		{loc: baseURL + "/sitemap-2.xml", lastMod: "2026-10-01", urls: 1},
+	} {
+		if got := index.Sitemaps[i]; got.Loc != want.loc || got.LastMod != want.lastMod {
+			t.Errorf("index sitemap %d = %+v, want %s, %q", i, got, want.loc, want.lastMod)
+		}
+		partPath := "/sitemap-" + strconv.Itoa(i+1) + ".xml"
+		var part testURLSet
+		getSitemap(t, sitemaps[partPath], partPath, &part)
+		if len(part.URLs) != want.urls {
+			t.Errorf("%s lists %d pages, want %d", partPath, len(part.URLs), want.urls)
+		}
+	}
+}
@@ -0,0 +1,356 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package handlers_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/immanent-tech/www-immanent-tech/server/handlers"
+	"github.com/immanent-tech/www-immanent-tech/web"
+)
+
+var (
+	// testScript is compressible content, which is smaller once compressed.
+	testScript = []byte(strings.Repeat("console.log('compressible');\n", 100))
+	// testPNG is content that is not compressible.
+	testPNG = []byte("\x89PNG\r\n\x1a\n" + strings.Repeat("\x00", 64))
+)
+
+// newStaticHandler creates a StaticFileHandler serving a test filesystem.
+func newStaticHandler(t *testing.T) http.HandlerFunc {
+	t.Helper()
+	handler, err := handlers.StaticFileHandler(fstest.MapFS{
+		"content/scripts.js":  {Data: testScript},
+		"content/tiny.txt":    {Data: []byte("a")},
+		"content/large.svg":   {Data: bytes.Repeat([]byte("<path/>"), 2<<20/7)},
+		"content/logo.png":    {Data: testPNG},
+		"content/fonts/a.txt": {Data: []byte("font")},
+		"content/empty/.keep": {Data: nil},
+	})
+	if err != nil {
+		t.Fatalf("create handler: %v", err)
+	}
+	return handler
+}
+
+// serveStatic makes a request to the handler with the given method, path and headers.
+func serveStatic(
+	t *testing.T,
+	handler http.Handler,
+	method, urlPath string,
+	headers map[string]string,
+) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequestWithContext(t.Context(), method, urlPath, nil)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	return res
+}
+
+func TestStaticFileHandlerEncoding(t *testing.T) {
+	handler := newStaticHandler(t)
+	tests := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		wantEncoding   string
+		wantVary       bool
+	}{
+		{
+			name:           "brotli preferred",
+			path:           "/content/scripts.js",
+			acceptEncoding: "gzip, deflate, br, zstd",
+			wantEncoding:   "br",
+			wantVary:       true,
+		},
+		{
+			name:           "gzip",
+			path:           "/content/scripts.js",
+			acceptEncoding: "gzip",
+			wantEncoding:   "gzip",
+			wantVary:       true,
+		},
+		{
+			name:     "no encoding accepted",
+			path:     "/content/scripts.js",
+			wantVary: true,
+		},
+		{
+			name:           "not smaller when compressed",
+			path:           "/content/tiny.txt",
+			acceptEncoding: "br, gzip",
+			wantVary:       true,
+		},
+		{
+			name:           "large content",
+			path:           "/content/large.svg",
+			acceptEncoding: "br, gzip",
+			wantEncoding:   "br",
+			wantVary:       true,
+		},
+		{
+			name:           "not compressible",
+			path:           "/content/logo.png",
+			acceptEncoding: "br, gzip",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := serveStatic(t, handler, http.MethodGet, tt.path, map[string]string{
+				"Accept-Encoding": tt.acceptEncoding,
+			})
+
+			if res.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", res.Code, http.StatusOK)
+			}
+			if got := res.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Errorf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+			}
+			if vary := res.Header().Get("Vary") == "Accept-Encoding"; vary != tt.wantVary {
+				t.Errorf("varies by Accept-Encoding = %t, want %t", vary, tt.wantVary)
+			}
+			etag := res.Header().Get("ETag")
+			if !strings.HasPrefix(etag, `"`) || !strings.HasSuffix(etag, `"`) {
+				t.Errorf("ETag = %s, want a strong ETag", etag)
+			}
+			if tt.wantEncoding != "" && !strings.HasSuffix(etag, "-"+tt.wantEncoding+`"`) {
+				t.Errorf("ETag = %s, want one for the %s encoding", etag, tt.wantEncoding)
+			}
+		})
+	}
+}
+
+func TestStaticFileHandlerGzipBody(t *testing.T) {
+	res := serveStatic(t, newStaticHandler(t), http.MethodGet, "/content/scripts.js",
+		map[string]string{"Accept-Encoding": "gzip"})
+
+	reader, err := gzip.NewReader(res.Body)
+	if err != nil {
+		t.Fatalf("read gzip body: %v", err)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("read gzip body: %v", err)
+	}
+	if !bytes.Equal(body, testScript) {
+		t.Error("decompressed body does not match the content")
+	}
+}
+
+func TestStaticFileHandlerConditional(t *testing.T) {
+	handler := newStaticHandler(t)
+	identity := serveStatic(t, handler, http.MethodGet, "/content/scripts.js", nil).Header().Get("ETag")
+	brotli := serveStatic(t, handler, http.MethodGet, "/content/scripts.js",
+		map[string]string{"Accept-Encoding": "br"}).Header().Get("ETag")
+	if identity == brotli {
+		t.Fatalf("encodings share the ETag %s", identity)
+	}
+
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		wantStatus int
+	}{
+		{
+			name:       "matching",
+			headers:    map[string]string{"If-None-Match": identity},
+			wantStatus: http.StatusNotModified,
+		},
+		{
+			name:       "matching one of many",
+			headers:    map[string]string{"If-None-Match": `"other", ` + identity},
+			wantStatus: http.StatusNotModified,
+		},
+		{
+			name:       "matching encoding",
+			headers:    map[string]string{"If-None-Match": brotli, "Accept-Encoding": "br"},
+			wantStatus: http.StatusNotModified,
+		},
+		{
+			name:       "ETag of another encoding",
+			headers:    map[string]string{"If-None-Match": brotli},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "not matching",
+			headers:    map[string]string{"If-None-Match": `"other"`},
+			wantStatus: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := serveStatic(t, handler, http.MethodGet, "/content/scripts.js", tt.headers)
+			if res.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", res.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusNotModified && res.Body.Len() != 0 {
+				t.Error("not modified response has a body")
+			}
+		})
+	}
+}
+
+func TestStaticFileHandlerRange(t *testing.T) {
+	handler := newStaticHandler(t)
+	etag := serveStatic(t, handler, http.MethodGet, "/content/logo.png", nil).Header().Get("ETag")
+
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		wantStatus int
+		wantBody   []byte
+	}{
+		{
+			name:       "range",
+			headers:    map[string]string{"Range": "bytes=0-3"},
+			wantStatus: http.StatusPartialContent,
+			wantBody:   testPNG[:4],
+		},
+		{
+			name:       "suffix range",
+			headers:    map[string]string{"Range": "bytes=-2"},
+			wantStatus: http.StatusPartialContent,
+			wantBody:   testPNG[len(testPNG)-2:],
+		},
+		{
+			name:       "unsatisfiable",
+			headers:    map[string]string{"Range": "bytes=1000-"},
+			wantStatus: http.StatusRequestedRangeNotSatisfiable,
+		},
+		{
+			name:       "if-range matching",
+			headers:    map[string]string{"Range": "bytes=0-3", "If-Range": etag},
+			wantStatus: http.StatusPartialContent,
+			wantBody:   testPNG[:4],
+		},
+		{
+			name:       "if-range not matching",
+			headers:    map[string]string{"Range": "bytes=0-3", "If-Range": `"other"`},
+			wantStatus: http.StatusOK,
+			wantBody:   testPNG,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := serveStatic(t, handler, http.MethodGet, "/content/logo.png", tt.headers)
+			if res.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", res.Code, tt.wantStatus)
+			}
+			if tt.wantBody != nil && !bytes.Equal(res.Body.Bytes(), tt.wantBody) {
+				t.Errorf("body = %q, want %q", res.Body.Bytes(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestStaticFileHandlerHead(t *testing.T) {
+	handler := newStaticHandler(t)
+	get := serveStatic(t, handler, http.MethodGet, "/content/scripts.js", map[string]string{"Accept-Encoding": "br"})
+	head := serveStatic(t, handler, http.MethodHead, "/content/scripts.js", map[string]string{"Accept-Encoding": "br"})
+
+	if head.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", head.Code, http.StatusOK)
+	}
+	if head.Body.Len() != 0 {
+		t.Error("HEAD response has a body")
+	}
+	for _, header := range []string{"Content-Length", "Content-Type", "Content-Encoding", "ETag", "Cache-Control"} {
+		if got, want := head.Header().Get(header), get.Header().Get(header); got != want {
+			t.Errorf("%s = %q, want %q as for GET", header, got, want)
+		}
+	}
+}
+
+func TestStaticFileHandlerNotFound(t *testing.T) {
+	handler := newStaticHandler(t)
+	for _, urlPath := range []string{"/content/missing.js", "/content/fonts", "/content/fonts/", "/content/empty"} {
+		t.Run(urlPath, func(t *testing.T) {
+			res := serveStatic(t, handler, http.MethodGet, urlPath, nil)
+			if res.Code != http.StatusNotFound {
+				t.Errorf("status = %d, want %d", res.Code, http.StatusNotFound)
+			}
+		})
+	}
+}
+
+func TestStaticFileHandlerCaching(t *testing.T) {
+	handler, err := handlers.StaticFileHandler(web.StaticContentFS)
+	if err != nil {
+		t.Fatalf("create handler: %v", err)
+	}
+	stylesheet := web.AssetURL("fonts/inter/inter.css")
+
+	tests := []struct {
+		name             string
+		path             string
+		wantCacheControl string
+	}{
+		{
+			name:             "fingerprinted",
+			path:             stylesheet,
+			wantCacheControl: "public, max-age=31536000, immutable",
+		},
+		{
+			name:             "fingerprinted font",
+			path:             web.AssetURL("fonts/inter/InterVariable.woff2"),
+			wantCacheControl: "public, max-age=31536000, immutable",
+		},
+		{
+			name:             "not fingerprinted",
+			path:             "/content/fonts/inter/inter.css",
+			wantCacheControl: "public, max-age=3600",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := serveStatic(t, handler, http.MethodGet, tt.path, nil)
+			if res.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", res.Code, http.StatusOK)
+			}
+			if got := res.Header().Get("Cache-Control"); got != tt.wantCacheControl {
+				t.Errorf("Cache-Control = %q, want %q", got, tt.wantCacheControl)
+			}
+		})
+	}
+
+	// Stylesheets are served with the fingerprinted URLs of the fonts they load.
+	body := serveStatic(t, handler, http.MethodGet, stylesheet, nil).Body.String()
+	if !strings.Contains(body, web.AssetURL("fonts/inter/InterVariable.woff2")) {
+		t.Error("stylesheet does not load the fingerprinted fonts")
+	}
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "", want: ""},
+		{header: "identity", want: ""},
+		{header: "gzip", want: "gzip"},
+		{header: "gzip, deflate, br, zstd", want: "br"},
+		{header: "GZIP, ZSTD", want: "zstd"},
+		{header: "br;q=0, gzip;q=0.5", want: "gzip"},
+		{header: "*", want: "br"},
+		{header: "*, br;q=0", want: "zstd"},
+		{header: "br;q=0, zstd;q=0, gzip;q=0", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := handlers.NegotiateEncoding(tt.header); got != tt.want {
+				t.Errorf("NegotiateEncoding(%q) = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	slogctx "github.com/veqryn/slog-context"
+
+	"github.com/immanent-tech/www-immanent-tech/models"
+	"github.com/immanent-tech/www-immanent-tech/server/respond"
+	"github.com/immanent-tech/www-immanent-tech/server/submissions"
+)
+
+// exportDateFormat is the format of the dates bounding an export of submissions.
+const exportDateFormat = "2006-01-02"
+
+// HandleExportSubmissions handles exporting the stored contact submissions. The format (csv or json) is chosen with
+// the format query parameter, defaulting to csv. The from and to query parameters (YYYY-MM-DD) limit the export to
+// submissions made on or after and on or before the given dates. Only the submissions stored by the instance of the
+// server handling the request are exported.
+func HandleExportSubmissions(store *submissions.Store) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+
+		format := submissions.Format(query.Get("format"))
+		switch format {
+		case "":
+			format = submissions.FormatCSV
+		case submissions.FormatCSV, submissions.FormatJSON:
+		default:
+			respond.APIError(res, req, http.StatusBadRequest, &models.APIError{
+				Code:    "invalid_format",
+				Message: "The format must be csv or json.",
+			})
+			return
+		}
+
+		from, fromErr := parseExportDate(query.Get("from"))
+		to, toErr := parseExportDate(query.Get("to"))
+		if fromErr != nil || toErr != nil {
+			respond.APIError(res, req, http.StatusBadRequest, &models.APIError{
+				Code:    "invalid_date",
+				Message: "The from and to dates must be in the form YYYY-MM-DD.",
+			})
+			return
+		}
+		// The to date is inclusive, so export up to the start of the following day.
+		if !to.IsZero() {
+			to = to.AddDate(0, 0, 1)
+		}
+
+		// Export into a buffer, so that an error can still be returned if the export fails part way through.
+		var buf bytes.Buffer
+		if err := store.Export(&buf, format, from, to); err != nil {
+			slogctx.FromCtx(req.Context()).Error("Could not export submissions.",
+				slog.Any("error", err),
+			)
+			respond.APIError(res, req, http.StatusInternalServerError, &models.APIError{
+				Code:    "internal_error",
+				Message: "The submissions could not be exported.",
+			})
+			return
+		}
+
+		res.Header().Set("Content-Type", format.ContentType())
+		res.Header().Set("Content-Disposition", `attachment; filename="submissions.`+string(format)+`"`)
+		res.Header().Set("Cache-Control", "no-store")
+		if _, err := buf.WriteTo(res); err != nil {
+			slogctx.FromCtx(req.Context()).Error("Could not write response.",
+				slog.Any("error", err),
+			)
+		}
+	}
+}
+
+// parseExportDate parses a date bounding an export. An empty date is the zero time, which leaves that end of the
+// export unbounded.
+func parseExportDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.Parse(exportDateFormat, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse date: %w", err)
+	}
+	return date, nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/immanent-tech/www-immanent-tech/server/handlers"
+	"github.com/immanent-tech/www-immanent-tech/server/submissions"
+)
+
+func newTestStore(t *testing.T) *submissions.Store {
+	t.Helper()
+	store, err := submissions.NewWithConfig([]byte("test-key"), submissions.Config{
+		Path: filepath.Join(t.TempDir(), "submissions.db"),
+	})
+	if err != nil {
+		t.Fatalf("submissions.NewWithConfig() error = %v", err)
+	}
+	t.Cleanup(func() {
+		store.Close()
+	})
+	return store
+}
+
+func TestExportSubmissions(t *testing.T) {
+	store := newTestStore(t)
+	if err := store.Add(t.Context(), &submissions.Submission{
+		Reference:    "IT-ABCDEFGHIJ",
+		ContactEmail: "visitor@example.com",
+		Details:      "=1+1",
+	}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	today := time.Now().UTC().Format("2006-01-02")
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+
+	tests := []struct {
+		name            string
+		query           string
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+		wantMissing     string
+	}{
+		{
+			name:            "csv by default",
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/csv; charset=utf-8",
+			wantBody:        "'=1+1",
+		},
+		{
+			name:            "json",
+			query:           "format=json",
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json",
+			wantBody:        `"details": "=1+1"`,
+		},
+		{
+			name:            "to date is inclusive",
+			query:           "from=" + today + "&to=" + today,
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/csv; charset=utf-8",
+			wantBody:        "IT-ABCDEFGHIJ",
+		},
+		{
+			name:            "outside the date range",
+			query:           "from=" + tomorrow,
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/csv; charset=utf-8",
+			wantMissing:     "IT-ABCDEFGHIJ",
+		},
+		{
+			name:       "unknown format",
+			query:      "format=xml",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid_format",
+		},
+		{
+			name:       "invalid date",
+			query:      "from=17/10/2026",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   "invalid_date",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/api/v1/submissions?"+tt.query, nil)
+			res := httptest.NewRecorder()
+			handlers.HandleExportSubmissions(store).ServeHTTP(res, req)
+
+			if res.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", res.Code, tt.wantStatus)
+			}
+			if tt.wantContentType != "" {
+				if got := res.Header().Get("Content-Type"); got != tt.wantContentType {
+					t.Errorf("Content-Type = %q, want %q", got, tt.wantContentType)
+				}
+				if got := res.Header().Get("Cache-Control"); got != "no-store" {
+					t.Errorf("Cache-Control = %q, want no-store", got)
+				}
+			}
+			if !strings.Contains(res.Body.String(), tt.wantBody) {
+				t.Errorf("body = %q, want it to contain %q", res.Body.String(), tt.wantBody)
+			}
+			if tt.wantMissing != "" && strings.Contains(res.Body.String(), tt.wantMissing) {
+				t.Errorf("body = %q, want it not to contain %q", res.Body.String(), tt.wantMissing)
+			}
+			if tt.wantStatus != http.StatusOK && !json.Valid(res.Body.Bytes()) {
+				t.Errorf("error body = %q, want a JSON error", res.Body.String())
+			}
+		})
+	}
+}
@@ -4,27 +4,22 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
+	"time"
 
-	"github.com/a-h/templ"
 	"github.com/immanent-tech/www-immanent-tech/web/templates"
 )
 
-type WorkPage struct {
-	template templ.Component
+// WorkPageInfo describes the work page for the sitemap.
+var WorkPageInfo = PageInfo{
+	Path:            "/work",
+	LastModified:    time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC),
+	ChangeFrequency: ChangeFrequencyMonthly,
+	Priority:        0.8,
 }
 
-func NewWorkPage() http.HandlerFunc {
-	page := &WorkPage{
-		template: templates.Page(templates.Work()),
-	}
-	return RenderPage(page)
-}
-
-func (p *WorkPage) FullResponse(w http.ResponseWriter, r *http.Request) {
-	templ.Handler(p.template).ServeHTTP(w, r)
-}
-
-func (p *WorkPage) PartialResponse(w http.ResponseWriter, r *http.Request) {
-	templ.Handler(p.template, templ.WithFragments(templates.BodyFragment)).ServeHTTP(w, r)
+// NewWorkPage handles showing the work page, which is pre-rendered.
+func NewWorkPage(ctx context.Context) (http.HandlerFunc, error) {
+	return NewPrerenderedPage(ctx, templates.Page(templates.Work()))
 }
@@ -0,0 +1,72 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	slogctx "github.com/veqryn/slog-context"
+
+	"github.com/immanent-tech/www-immanent-tech/providers/fastmail"
+	"github.com/immanent-tech/www-immanent-tech/providers/mailer"
+	"github.com/immanent-tech/www-immanent-tech/providers/smtp"
+	"github.com/immanent-tech/www-immanent-tech/server/outbox"
+	"github.com/immanent-tech/www-immanent-tech/server/submissions"
+)
+
+const (
+	mailerFastmail = "fastmail"
+	mailerSMTP     = "smtp"
+	mailerLog      = "log"
+)
+
+// newMailer creates the mailer.Mailer for the provider selected in the server config.
+func newMailer(provider string) (mailer.Mailer, error) {
+	switch provider {
+	case mailerFastmail:
+		m, err := fastmail.New()
+		if err != nil {
+			return nil, fmt.Errorf("create fastmail mailer: %w", err)
+		}
+		return m, nil
+	case mailerSMTP:
+		m, err := smtp.New()
+		if err != nil {
+			return nil, fmt.Errorf("create smtp mailer: %w", err)
+		}
+		return m, nil
+	case mailerLog:
+		return mailer.NewLogMailer(), nil
+	default:
+		return nil, fmt.Errorf("unknown mail provider %q", provider)
+	}
+}
+
+// trackDelivery returns an outbox.DeliveryHook that updates the delivery status of the submission (if any) the
+// delivered message was sent for.
+func trackDelivery(store *submissions.Store) outbox.DeliveryHook {
+	return func(ctx context.Context, entry *outbox.Entry, result outbox.Result) {
+		id, found := entry.Message.Headers[submissions.HeaderID]
+		if !found {
+			return
+		}
+		var status submissions.Status
+		switch result {
+		case outbox.ResultDelivered:
+			status = submissions.StatusDelivered
+		case outbox.ResultDeadLettered:
+			status = submissions.StatusDeadLettered
+		default:
+			return
+		}
+		if err := store.SetStatus(id, status); err != nil {
+			slogctx.FromCtx(ctx).Error("Could not update submission delivery status.",
+				slog.String("submission_id", id),
+				slog.Any("error", err),
+			)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package middlewares
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	slogchi "github.com/samber/slog-chi"
+
+	"github.com/immanent-tech/www-immanent-tech/models"
+	"github.com/immanent-tech/www-immanent-tech/server/respond"
+)
+
+// ErrInvalidAPIKeys is returned when the list of API keys cannot be parsed.
+var ErrInvalidAPIKeys = errors.New("invalid api keys")
+
+// APIKeys maps the SHA-256 hash of each API key to the name of the client it was issued to.
+type APIKeys map[[sha256.Size]byte]string
+
+// ParseAPIKeys parses a comma-separated list of API keys, each in the form "client:hash", where hash is the hex
+// encoded SHA-256 hash of the key issued to the client. Only hashes are configured, so that the configuration does not
+// contain usable keys.
+func ParseAPIKeys(list string) (APIKeys, error) {
+	keys := make(APIKeys)
+	for entry := range strings.SplitSeq(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		client, hash, found := strings.Cut(entry, ":")
+		if !found || client == "" {
+			return nil, fmt.Errorf("%w: entry must be in the form client:hash", ErrInvalidAPIKeys)
+		}
+		decoded, err := hex.DecodeString(hash)
+		if err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("%w: hash for client %s is not a hex encoded sha256 hash", ErrInvalidAPIKeys, client)
+		}
+		keys[[sha256.Size]byte(decoded)] = client
+	}
+	return keys, nil
+}
+
+// HashAPIKey returns the hex encoded SHA-256 hash of the API key, as used in the list of API keys.
+func HashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+// RequireAPIKey middleware will only pass control to the next handler if the request has a valid API key as a bearer
+// token in the Authorization header. The name of the client the key was issued to is stored in the request context.
+// If not, it will return a 401: Unauthorized JSON response.
+func RequireAPIKey(keys APIKeys) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			key, found := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+			client, valid := keys[sha256.Sum256([]byte(strings.TrimSpace(key)))]
+			if !found || !valid {
+				res.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+				respond.APIError(res, req, http.StatusUnauthorized, &models.APIError{
+					Code:    "unauthorized",
+					Message: "A valid API key is required.",
+				})
+				return
+			}
+			slogchi.AddCustomAttributes(req, slog.String("api_client", client))
+			next.ServeHTTP(res, req.WithContext(models.APIClientToCtx(req.Context(), client)))
+		})
+	}
+}
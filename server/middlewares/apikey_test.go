@@ -0,0 +1,92 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package middlewares_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/immanent-tech/www-immanent-tech/models"
+	"github.com/immanent-tech/www-immanent-tech/server/middlewares"
+)
+
+func TestParseAPIKeys(t *testing.T) {
+	hash, otherHash := middlewares.HashAPIKey("secret"), middlewares.HashAPIKey("other")
+
+	tests := []struct {
+		name    string
+		list    string
+		want    int
+		wantErr error
+	}{
+		{name: "empty"},
+		{name: "one key", list: "partner:" + hash, want: 1},
+		{name: "spaces and empty entries", list: " partner:" + hash + ", ,other:" + otherHash, want: 2},
+		{name: "missing client", list: ":" + hash, wantErr: middlewares.ErrInvalidAPIKeys},
+		{name: "missing hash", list: "partner", wantErr: middlewares.ErrInvalidAPIKeys},
+		{name: "not hex", list: "partner:secret", wantErr: middlewares.ErrInvalidAPIKeys},
+		{name: "wrong length", list: "partner:abcd", wantErr: middlewares.ErrInvalidAPIKeys},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := middlewares.ParseAPIKeys(tt.list)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseAPIKeys() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && len(got) != tt.want {
+				t.Errorf("ParseAPIKeys() returned %d keys, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestRequireAPIKey(t *testing.T) {
+	keys, err := middlewares.ParseAPIKeys("partner:" + middlewares.HashAPIKey("secret"))
+	if err != nil {
+		t.Fatalf("ParseAPIKeys() error = %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+		wantClient    string
+	}{
+		{name: "valid key", authorization: "Bearer secret", wantStatus: http.StatusOK, wantClient: "partner"},
+		{name: "no key", wantStatus: http.StatusUnauthorized},
+		{name: "wrong key", authorization: "Bearer wrong", wantStatus: http.StatusUnauthorized},
+		{name: "not a bearer token", authorization: "Basic secret", wantStatus: http.StatusUnauthorized},
+		{
+			name:          "hash instead of key",
+			authorization: "Bearer " + middlewares.HashAPIKey("secret"),
+			wantStatus:    http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var client string
+			handler := middlewares.RequireAPIKey(keys)(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+				client = models.APIClientFromCtx(req.Context())
+			}))
+			req := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/api/v1/contact", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			if res.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", res.Code, tt.wantStatus)
+			}
+			if client != tt.wantClient {
+				t.Errorf("client = %q, want %q", client, tt.wantClient)
+			}
+			if tt.wantStatus == http.StatusUnauthorized && res.Header().Get("WWW-Authenticate") == "" {
+				t.Error("WWW-Authenticate header not set")
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package middlewares
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+
+	"github.com/realclientip/realclientip-go"
+	slogchi "github.com/samber/slog-chi"
+
+	"github.com/immanent-tech/www-immanent-tech/models"
+)
+
+// ErrNoTrustedProxies is returned when a client IP strategy that relies on a header set by a proxy has no trusted
+// proxies, so the header could be set by anyone.
+var ErrNoTrustedProxies = errors.New("no trusted proxies")
+
+// ClientIPStrategy is the method by which the IP address of the client is determined.
+type ClientIPStrategy string
+
+const (
+	// ClientIPRemoteAddr uses the address of the connection. Use it when the server is not behind a proxy.
+	ClientIPRemoteAddr ClientIPStrategy = "remoteaddr"
+	// ClientIPForwardedFor uses the rightmost address in the X-Forwarded-For header that is not a trusted proxy. If
+	// there are no trusted proxies, the rightmost non-private address is used.
+	ClientIPForwardedFor ClientIPStrategy = "xforwardedfor"
+	// ClientIPForwarded uses the rightmost address in the RFC 7239 Forwarded header that is not a trusted proxy. If
+	// there are no trusted proxies, the rightmost non-private address is used.
+	ClientIPForwarded ClientIPStrategy = "forwarded"
+	// ClientIPCloudflare uses the CF-Connecting-IP header set by Cloudflare, if the request was forwarded by a trusted
+	// proxy. Otherwise, the rightmost non-private address in the X-Forwarded-For header is used. It requires trusted
+	// proxies.
+	ClientIPCloudflare ClientIPStrategy = "cfconnectingip"
+)
+
+// privateRanges are the private and local networks, which are always trusted, as they can only be those of the
+// proxies of the platform the server runs on, rather than of clients.
+var privateRanges = []string{
+	"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "100.64.0.0/10", "169.254.0.0/16", "127.0.0.0/8",
+	"fc00::/7", "fe80::/10", "::1/128",
+}
+
+// ClientIPResolver determines the IP address of the client that made a request.
+type ClientIPResolver struct {
+	strategy realclientip.Strategy
+}
+
+// NewClientIPResolver creates a new ClientIPResolver with the given strategy and list of trusted proxy addresses or
+// CIDRs. The trusted proxies are found from the forwarding chain in the headers, rather than the address of the
+// connection, which on platforms such as Cloud Run is always that of the platform's own proxy. The client is then the
+// address that forwarded the request to the rightmost trusted proxy, which cannot be spoofed by clients that reach the
+// server without going through the trusted proxies.
+func NewClientIPResolver(strategy ClientIPStrategy, trustedProxies []string) (*ClientIPResolver, error) {
+	var trusted []net.IPNet
+	if len(trustedProxies) > 0 {
+		ranges, err := realclientip.AddressesAndRangesToIPNets(append(trustedProxies, privateRanges...)...)
+		if err != nil {
+			return nil, fmt.Errorf("parse trusted proxies: %w", err)
+		}
+		trusted = ranges
+	}
+
+	var (
+		headerStrategy realclientip.Strategy
+		err            error
+	)
+	switch strategy {
+	case ClientIPRemoteAddr:
+		headerStrategy = realclientip.RemoteAddrStrategy{}
+	case ClientIPForwardedFor, ClientIPForwarded, "":
+		header := "X-Forwarded-For"
+		if strategy == ClientIPForwarded {
+			header = "Forwarded"
+		}
+		if len(trusted) > 0 {
+			headerStrategy, err = realclientip.NewRightmostTrustedRangeStrategy(header, trusted)
+		} else {
+			headerStrategy, err = realclientip.NewRightmostNonPrivateStrategy(header)
+		}
+	case ClientIPCloudflare:
+		if len(trusted) == 0 {
+			return nil, fmt.Errorf("%w: the %s strategy can be spoofed without them", ErrNoTrustedProxies, strategy)
+		}
+		headerStrategy, err = newTrustedHeaderStrategy("CF-Connecting-IP", trusted)
+	default:
+		return nil, fmt.Errorf("unknown client ip strategy %q", strategy)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create client ip strategy: %w", err)
+	}
+
+	// Fall back to the address of the connection, such as when running locally without a proxy.
+	return &ClientIPResolver{
+		strategy: realclientip.NewChainStrategy(headerStrategy, realclientip.RemoteAddrStrategy{}),
+	}, nil
+}
+
+// ClientIP returns the IP address of the client that made the request, without any zone. An empty string is returned
+// if no address could be determined.
+func (r *ClientIPResolver) ClientIP(req *http.Request) string {
+	// We don't want to include the zone in the address.
+	clientIP, _ := realclientip.SplitHostZone(r.strategy.ClientIP(req.Header, req.RemoteAddr))
+	return clientIP
+}
+
+// Middleware stores the IP address of the client that made the request in the request context, for use by later
+// handlers, and records it in the request log.
+func (r *ClientIPResolver) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		clientIP := r.ClientIP(req)
+		if clientIP != "" {
+			slogchi.AddCustomAttributes(req, slog.String("client_ip", clientIP))
+			req = req.WithContext(models.ClientIPToCtx(req.Context(), clientIP))
+		}
+		next.ServeHTTP(res, req)
+	})
+}
+
+// trustedHeaderStrategy uses a header containing the client address set by a proxy, but only when the request was
+// forwarded by a trusted proxy. The proxy that forwarded the request is the rightmost non-private address in the
+// X-Forwarded-For header. If it is not trusted, the request bypassed the trusted proxies, so it is the client.
+type trustedHeaderStrategy struct {
+	header         realclientip.SingleIPHeaderStrategy
+	hop            realclientip.RightmostNonPrivateStrategy
+	trustedProxies []net.IPNet
+}
+
+func newTrustedHeaderStrategy(header string, trustedProxies []net.IPNet) (*trustedHeaderStrategy, error) {
+	headerStrategy, err := realclientip.NewSingleIPHeaderStrategy(header)
+	if err != nil {
+		return nil, fmt.Errorf("create header strategy: %w", err)
+	}
+	hopStrategy, err := realclientip.NewRightmostNonPrivateStrategy("X-Forwarded-For")
+	if err != nil {
+		return nil, fmt.Errorf("create hop strategy: %w", err)
+	}
+	return &trustedHeaderStrategy{
+		header:         headerStrategy,
+		hop:            hopStrategy,
+		trustedProxies: trustedProxies,
+	}, nil
+}
+
+// ClientIP returns the address in the header if the request was forwarded by a trusted proxy, otherwise the address
+// that forwarded the request. An empty string is returned if the request was not forwarded.
+func (s *trustedHeaderStrategy) ClientIP(headers http.Header, remoteAddr string) string {
+	hop := s.hop.ClientIP(headers, remoteAddr)
+	host, _ := realclientip.SplitHostZone(hop)
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return ""
+	}
+	for _, network := range s.trustedProxies {
+		if network.Contains(ip) {
+			return s.header.ClientIP(headers, remoteAddr)
+		}
+	}
+	return hop
+}
@@ -0,0 +1,191 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/immanent-tech/www-immanent-tech/models"
+	"github.com/immanent-tech/www-immanent-tech/server/middlewares"
+)
+
+// The documentation address ranges are treated as private when finding the rightmost non-private address, so public
+// addresses are used for clients and proxies, with 173.245.48.0/20 (a range of Cloudflare) as the trusted proxies.
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name           string
+		strategy       middlewares.ClientIPStrategy
+		trustedProxies []string
+		remoteAddr     string
+		headers        map[string]string
+		want           string
+	}{
+		{
+			name:       "remote address ignores headers",
+			strategy:   middlewares.ClientIPRemoteAddr,
+			remoteAddr: "203.0.113.5:4321",
+			headers:    map[string]string{"X-Forwarded-For": "93.184.216.34"},
+			want:       "203.0.113.5",
+		},
+		{
+			name:       "remote address without zone",
+			strategy:   middlewares.ClientIPRemoteAddr,
+			remoteAddr: "[fe80::1%eth0]:4321",
+			want:       "fe80::1",
+		},
+		{
+			name:       "default strategy is x-forwarded-for",
+			remoteAddr: "10.0.0.2:4321",
+			headers:    map[string]string{"X-Forwarded-For": "93.184.216.34"},
+			want:       "93.184.216.34",
+		},
+		{
+			name:       "x-forwarded-for rightmost non-private",
+			strategy:   middlewares.ClientIPForwardedFor,
+			remoteAddr: "10.0.0.2:4321",
+			headers:    map[string]string{"X-Forwarded-For": "192.0.2.7, 93.184.216.34, 10.0.0.3"},
+			want:       "93.184.216.34",
+		},
+		{
+			name:           "x-forwarded-for through trusted proxy",
+			strategy:       middlewares.ClientIPForwardedFor,
+			trustedProxies: []string{"173.245.48.0/20"},
+			remoteAddr:     "169.254.1.1:4321",
+			headers:        map[string]string{"X-Forwarded-For": "192.0.2.7, 93.184.216.34, 173.245.48.1"},
+			want:           "93.184.216.34",
+		},
+		{
+			name:           "x-forwarded-for through trusted and private proxies",
+			strategy:       middlewares.ClientIPForwardedFor,
+			trustedProxies: []string{"173.245.48.0/20"},
+			remoteAddr:     "169.254.1.1:4321",
+			headers:        map[string]string{"X-Forwarded-For": "93.184.216.34, 173.245.48.1, 10.0.0.3"},
+			want:           "93.184.216.34",
+		},
+		{
+			name:           "x-forwarded-for bypassing trusted proxy",
+			strategy:       middlewares.ClientIPForwardedFor,
+			trustedProxies: []string{"173.245.48.0/20"},
+			remoteAddr:     "169.254.1.1:4321",
+			headers:        map[string]string{"X-Forwarded-For": "93.184.216.34, 198.18.0.99"},
+			want:           "198.18.0.99",
+		},
+		{
+			name:       "x-forwarded-for missing",
+			strategy:   middlewares.ClientIPForwardedFor,
+			remoteAddr: "192.0.2.99:4321",
+			want:       "192.0.2.99",
+		},
+		{
+			name:       "forwarded",
+			strategy:   middlewares.ClientIPForwarded,
+			remoteAddr: "10.0.0.2:4321",
+			headers:    map[string]string{"Forwarded": "for=93.184.216.34;proto=https"},
+			want:       "93.184.216.34",
+		},
+		{
+			name:           "cloudflare",
+			strategy:       middlewares.ClientIPCloudflare,
+			trustedProxies: []string{"173.245.48.0/20"},
+			remoteAddr:     "169.254.1.1:4321",
+			headers: map[string]string{
+				"CF-Connecting-IP": "2001:db8::1",
+				"X-Forwarded-For":  "2001:db8::1, 173.245.48.1",
+			},
+			want: "2001:db8::1",
+		},
+		{
+			name:           "cloudflare bypassed",
+			strategy:       middlewares.ClientIPCloudflare,
+			trustedProxies: []string{"173.245.48.0/20"},
+			remoteAddr:     "169.254.1.1:4321",
+			headers: map[string]string{
+				"CF-Connecting-IP": "93.184.216.34",
+				"X-Forwarded-For":  "198.18.0.99",
+			},
+			want: "198.18.0.99",
+		},
+		{
+			name:           "cloudflare not forwarded",
+			strategy:       middlewares.ClientIPCloudflare,
+			trustedProxies: []string{"173.245.48.0/20"},
+			remoteAddr:     "192.0.2.99:4321",
+			headers:        map[string]string{"CF-Connecting-IP": "93.184.216.34"},
+			want:           "192.0.2.99",
+		},
+		{
+			name:       "no address",
+			strategy:   middlewares.ClientIPRemoteAddr,
+			remoteAddr: "invalid",
+			want:       "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver, err := middlewares.NewClientIPResolver(tt.strategy, tt.trustedProxies)
+			if err != nil {
+				t.Fatalf("NewClientIPResolver() error = %v", err)
+			}
+			req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			if got := resolver.ClientIP(req); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewClientIPResolverErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		strategy       middlewares.ClientIPStrategy
+		trustedProxies []string
+	}{
+		{
+			name:     "unknown strategy",
+			strategy: "xrealip",
+		},
+		{
+			name:           "invalid trusted proxy",
+			strategy:       middlewares.ClientIPForwardedFor,
+			trustedProxies: []string{"not-an-address"},
+		},
+		{
+			name:     "cloudflare without trusted proxies",
+			strategy: middlewares.ClientIPCloudflare,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := middlewares.NewClientIPResolver(tt.strategy, tt.trustedProxies); err == nil {
+				t.Error("NewClientIPResolver() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestClientIPMiddleware(t *testing.T) {
+	resolver, err := middlewares.NewClientIPResolver(middlewares.ClientIPCloudflare, []string{"173.245.48.0/20"})
+	if err != nil {
+		t.Fatalf("NewClientIPResolver() error = %v", err)
+	}
+	var got string
+	handler := resolver.Middleware(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		got = models.ClientIPFromCtx(req.Context())
+	}))
+
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+	req.Header.Set("CF-Connecting-IP", "93.184.216.34")
+	req.Header.Set("X-Forwarded-For", "93.184.216.34, 173.245.48.1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got != "93.184.216.34" {
+		t.Errorf("client ip in context = %q, want %q", got, "93.184.216.34")
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package middlewares
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/a-h/templ"
+	"github.com/immanent-tech/go-base/config"
+	"github.com/immanent-tech/go-base/validation"
+)
+
+const (
+	cspConfigPrefix = "CSP_"
+	// cspNoncePlaceholder is replaced in the policy with the nonce of each request.
+	cspNoncePlaceholder = "{nonce}"
+)
+
+// CSPConfig contains the additional sources allowed by the content security policy, as space or comma-separated
+// lists. The origin of the site and the third-party sources the site uses are always allowed.
+type CSPConfig struct {
+	ScriptSrc  string `koanf:"scriptsrc"  validate:"omitempty"`
+	StyleSrc   string `koanf:"stylesrc"   validate:"omitempty"`
+	ConnectSrc string `koanf:"connectsrc" validate:"omitempty"`
+	ImgSrc     string `koanf:"imgsrc"     validate:"omitempty"`
+	FrameSrc   string `koanf:"framesrc"   validate:"omitempty"`
+}
+
+var cspCfg = CSPConfig{}
+
+// Sources used by the site itself, which are always allowed; Cloudflare Turnstile, Umami analytics and Tailwind Plus
+// Elements from jsDelivr.
+const (
+	turnstileSource = "https://challenges.cloudflare.com"
+	umamiSource     = "https://cloud.umami.is"
+	jsDelivrSource  = "https://cdn.jsdelivr.net"
+)
+
+// loadCSPConfig loads the content security policy configuration and ensures this is only done
+// one time, no matter how many times it is called.
+var loadCSPConfig = sync.OnceValue(func() error {
+	if err := config.Load(cspConfigPrefix, &cspCfg); err != nil {
+		return fmt.Errorf("load config from environment: %w", err)
+	}
+	if err := validation.Validate.Struct(cspCfg); err != nil {
+		return fmt.Errorf("validate config: %w", err)
+	}
+	return nil
+})
+
+// ContentSecurityPolicy sets a content security policy that only allows scripts and style elements from the allowed
+// sources or with the nonce of the request, so that neither needs 'unsafe-inline'. Style attributes are still allowed,
+// as they cannot carry a nonce.
+type ContentSecurityPolicy struct {
+	policy string
+}
+
+// NewContentSecurityPolicy creates a new ContentSecurityPolicy. The config will be loaded from the environment.
+func NewContentSecurityPolicy() (*ContentSecurityPolicy, error) {
+	if err := loadCSPConfig(); err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	return NewContentSecurityPolicyWithConfig(cspCfg), nil
+}
+
+// NewContentSecurityPolicyWithConfig creates a new ContentSecurityPolicy with the given config.
+func NewContentSecurityPolicyWithConfig(policyCfg CSPConfig) *ContentSecurityPolicy {
+	nonce := "'nonce-" + cspNoncePlaceholder + "'"
+	directives := [][]string{
+		{"default-src", "'self'"},
+		append([]string{"script-src", "'self'", nonce, turnstileSource, umamiSource, jsDelivrSource},
+			sources(policyCfg.ScriptSrc)...),
+		append([]string{"style-src", "'self'", nonce}, sources(policyCfg.StyleSrc)...),
+		{"style-src-attr", "'unsafe-inline'"},
+		append([]string{"connect-src", "'self'", umamiSource}, sources(policyCfg.ConnectSrc)...),
+		append([]string{"img-src", "'self'", "data:"}, sources(policyCfg.ImgSrc)...),
+		append([]string{"frame-src", "'self'", turnstileSource}, sources(policyCfg.FrameSrc)...),
+		{"font-src", "'self'"},
+		{"object-src", "'none'"},
+		{"base-uri", "'self'"},
+		{"form-action", "'self'"},
+		{"frame-ancestors", "'none'"},
+	}
+	policy := make([]string, 0, len(directives))
+	for _, directive := range directives {
+		policy = append(policy, strings.Join(directive, " "))
+	}
+	return &ContentSecurityPolicy{
+		policy: strings.Join(policy, "; "),
+	}
+}
+
+// Middleware generates a nonce for each request, which is stored in the request context for templ to render in the
+// htmx config of the page, and sets the Content-Security-Policy header allowing the nonce.
+func (p *ContentSecurityPolicy) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		nonce := rand.Text()
+		res.Header().Set("Content-Security-Policy", strings.ReplaceAll(p.policy, cspNoncePlaceholder, nonce))
+		next.ServeHTTP(res, req.WithContext(templ.WithNonce(req.Context(), nonce)))
+	})
+}
+
+// sources splits a space or comma-separated list of sources.
+func sources(list string) []string {
+	return strings.FieldsFunc(list, func(r rune) bool {
+		return r == ' ' || r == ','
+	})
+}
@@ -0,0 +1,117 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+
+	"github.com/immanent-tech/www-immanent-tech/server/middlewares"
+)
+
+// directives returns the directives of the policy, by name.
+func directives(policy string) map[string][]string {
+	parsed := make(map[string][]string)
+	for directive := range strings.SplitSeq(policy, ";") {
+		fields := strings.Fields(directive)
+		if len(fields) > 0 {
+			parsed[fields[0]] = fields[1:]
+		}
+	}
+	return parsed
+}
+
+func TestContentSecurityPolicyNonce(t *testing.T) {
+	csp := middlewares.NewContentSecurityPolicyWithConfig(middlewares.CSPConfig{})
+	var nonce string
+	handler := csp.Middleware(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		nonce = templ.GetNonce(req.Context())
+	}))
+
+	seen := make(map[string]bool)
+	for range 2 {
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil))
+
+		if nonce == "" {
+			t.Fatal("no nonce in the request context")
+		}
+		if seen[nonce] {
+			t.Errorf("nonce %q was reused", nonce)
+		}
+		seen[nonce] = true
+
+		policy := directives(res.Header().Get("Content-Security-Policy"))
+		for _, name := range []string{"script-src", "style-src"} {
+			want := "'nonce-" + nonce + "'"
+			if !strings.Contains(strings.Join(policy[name], " "), want) {
+				t.Errorf("%s = %v, want it to contain %s", name, policy[name], want)
+			}
+		}
+	}
+}
+
+func TestContentSecurityPolicySources(t *testing.T) {
+	csp := middlewares.NewContentSecurityPolicyWithConfig(middlewares.CSPConfig{
+		ScriptSrc:  "https://scripts.example.com, https://more.example.com",
+		ConnectSrc: "https://api.example.com",
+		ImgSrc:     "https://images.example.com",
+	})
+	res := httptest.NewRecorder()
+	csp.Middleware(okHandler).ServeHTTP(res, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil))
+	policy := directives(res.Header().Get("Content-Security-Policy"))
+
+	tests := []struct {
+		directive string
+		want      []string
+		notWant   []string
+	}{
+		{
+			directive: "script-src",
+			want: []string{
+				"'self'",
+				"https://challenges.cloudflare.com",
+				"https://scripts.example.com",
+				"https://more.example.com",
+			},
+			notWant: []string{"'unsafe-inline'"},
+		},
+		{
+			directive: "style-src",
+			want:      []string{"'self'"},
+			notWant:   []string{"'unsafe-inline'"},
+		},
+		{
+			directive: "connect-src",
+			want:      []string{"'self'", "https://api.example.com"},
+		},
+		{
+			directive: "img-src",
+			want:      []string{"'self'", "data:", "https://images.example.com"},
+		},
+		{
+			directive: "frame-ancestors",
+			want:      []string{"'none'"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.directive, func(t *testing.T) {
+			sources := strings.Join(policy[tt.directive], " ")
+			for _, want := range tt.want {
+				if !strings.Contains(sources, want) {
+					t.Errorf("%s = %q, want it to contain %s", tt.directive, sources, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(sources, notWant) {
+					t.Errorf("%s = %q, want it not to contain %s", tt.directive, sources, notWant)
+				}
+			}
+		})
+	}
+}
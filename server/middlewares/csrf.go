@@ -0,0 +1,169 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package middlewares
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/immanent-tech/go-base/config"
+	slogchi "github.com/samber/slog-chi"
+	slogctx "github.com/veqryn/slog-context"
+
+	"github.com/immanent-tech/www-immanent-tech/models"
+	"github.com/immanent-tech/www-immanent-tech/server/respond"
+)
+
+const (
+	// DefaultCSRFTokenTTL is the default time for which an issued CSRF token is valid.
+	DefaultCSRFTokenTTL = 12 * time.Hour
+	// csrfCookieName is the name of the cookie containing the CSRF secret of the client.
+	csrfCookieName = "csrf"
+	// csrfSecureCookieName is the name of the cookie in production, where it is only sent over https. The __Host-
+	// prefix requires the cookie to be secure and not scoped to a domain, so it cannot be set by subdomains.
+	csrfSecureCookieName = "__Host-csrf"
+	// csrfFormField is the name of the form field that can contain the CSRF token, for requests without the header.
+	csrfFormField = "csrf_token"
+	// csrfSecretLength is the length of a CSRF secret, as generated by rand.Text.
+	csrfSecretLength = 26
+	// csrfClockSkew is the allowance for tokens issued by other instances with a clock slightly ahead.
+	csrfClockSkew = time.Minute
+)
+
+var (
+	// ErrMissingCSRFToken is returned when an unsafe request does not contain a CSRF token.
+	ErrMissingCSRFToken = errors.New("missing csrf token")
+	// ErrInvalidCSRFToken is returned when the CSRF token of an unsafe request is malformed, has expired or was not
+	// issued to the client.
+	ErrInvalidCSRFToken = errors.New("invalid csrf token")
+)
+
+// CSRF protects forms against cross-site request forgery with signed double-submit tokens. Each client is given a
+// random secret in a cookie, and every render of a form is issued a new token, signed with the server key and bound to
+// the secret and the time it was issued. A token is only valid when submitted with the secret it was issued for and
+// before it expires, so a token cannot be forged without the key nor used by another client.
+type CSRF struct {
+	key []byte
+	ttl time.Duration
+}
+
+// NewCSRF creates a new CSRF with the given signing key and token lifetime. Tokens signed with the same key are valid
+// across all instances of the server.
+func NewCSRF(key []byte, ttl time.Duration) *CSRF {
+	return &CSRF{
+		key: key,
+		ttl: ttl,
+	}
+}
+
+// Middleware issues a CSRF token for the request, which is stored in the request context for rendering into forms.
+// Requests with unsafe methods must contain a valid token in the X-CSRF-Token header, or a csrf_token field for
+// url-encoded forms. If not, a 403: Forbidden response is returned.
+func (c *CSRF) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		secret := c.secret(res, req)
+
+		switch req.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		default:
+			if err := c.verify(secret, submittedCSRFToken(req), time.Now()); err != nil {
+				slogchi.AddCustomAttributes(req, slog.String("csrf", "invalid"))
+				slogctx.FromCtx(req.Context()).Warn("Request failed CSRF verification.",
+					slog.Any("error", err),
+				)
+				respond.Error(http.StatusForbidden,
+					"Unable to verify your request.",
+					"Please reload the page and try again.",
+				).ServeHTTP(res, req)
+				return
+			}
+		}
+
+		ctx := models.CSRFTokenToCtx(req.Context(), c.issue(secret, time.Now()))
+		next.ServeHTTP(res, req.WithContext(ctx))
+	})
+}
+
+// secret returns the CSRF secret of the client from its cookie. If the client does not have a secret, a new one is
+// generated and set in the cookie.
+func (c *CSRF) secret(res http.ResponseWriter, req *http.Request) string {
+	name := csrfCookieName
+	if config.IsProduction() {
+		name = csrfSecureCookieName
+	}
+	if cookie, err := req.Cookie(name); err == nil && len(cookie.Value) == csrfSecretLength {
+		return cookie.Value
+	}
+	secret := rand.Text()
+	http.SetCookie(res, &http.Cookie{
+		Name:     name,
+		Value:    secret,
+		Path:     "/",
+		Secure:   config.IsProduction(),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return secret
+}
+
+// issue returns a new token for the secret, issued at the given time. The token is the base36 issue time and the
+// signature of the secret and issue time, joined by a ".".
+func (c *CSRF) issue(secret string, now time.Time) string {
+	issued := strconv.FormatInt(now.Unix(), 36)
+	return issued + "." + c.sign(secret, issued)
+}
+
+// verify checks the token was issued for the secret and has not expired.
+func (c *CSRF) verify(secret, token string, now time.Time) error {
+	if token == "" {
+		return ErrMissingCSRFToken
+	}
+	issued, signature, found := strings.Cut(token, ".")
+	if !found {
+		return fmt.Errorf("%w: malformed token", ErrInvalidCSRFToken)
+	}
+	seconds, err := strconv.ParseInt(issued, 36, 64)
+	if err != nil {
+		return fmt.Errorf("%w: malformed issue time", ErrInvalidCSRFToken)
+	}
+	issuedAt := time.Unix(seconds, 0)
+	if now.Sub(issuedAt) > c.ttl || issuedAt.Sub(now) > csrfClockSkew {
+		return fmt.Errorf("%w: token expired", ErrInvalidCSRFToken)
+	}
+	if !hmac.Equal([]byte(signature), []byte(c.sign(secret, issued))) {
+		return fmt.Errorf("%w: signature mismatch", ErrInvalidCSRFToken)
+	}
+	return nil
+}
+
+// sign returns the signature of the secret and issue time. The signature is prefixed with a purpose, so that it
+// cannot be confused with other values signed by the same key.
+func (c *CSRF) sign(secret, issued string) string {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte("csrf|" + secret + "|" + issued))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// submittedCSRFToken returns the CSRF token submitted with the request. The form field is only read for url-encoded
+// forms, so that multipart bodies are not parsed before any size limits are applied by the handler.
+func submittedCSRFToken(req *http.Request) string {
+	if token := req.Header.Get(models.CSRFTokenHeader); token != "" {
+		return token
+	}
+	if mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type")); err == nil &&
+		mediaType == "application/x-www-form-urlencoded" {
+		return req.PostFormValue(csrfFormField)
+	}
+	return ""
+}
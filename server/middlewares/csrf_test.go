@@ -0,0 +1,245 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package middlewares_test
+
+import (
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/immanent-tech/www-immanent-tech/models"
+	"github.com/immanent-tech/www-immanent-tech/server/middlewares"
+)
+
+const testCSRFSecret = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+func TestVerifyCSRFToken(t *testing.T) {
+	csrf := middlewares.NewCSRF([]byte("test-key"), time.Hour)
+	now := time.Date(2026, time.October, 17, 12, 0, 0, 0, time.UTC)
+	token := csrf.IssueCSRFToken(testCSRFSecret, now)
+
+	tests := []struct {
+		name    string
+		csrf    *middlewares.CSRF
+		secret  string
+		token   string
+		now     time.Time
+		wantErr error
+	}{
+		{
+			name:   "valid",
+			csrf:   csrf,
+			secret: testCSRFSecret,
+			token:  token,
+			now:    now.Add(time.Minute),
+		},
+		{
+			name:   "issued by an instance with a clock slightly ahead",
+			csrf:   csrf,
+			secret: testCSRFSecret,
+			token:  token,
+			now:    now.Add(-30 * time.Second),
+		},
+		{
+			name:    "missing",
+			csrf:    csrf,
+			secret:  testCSRFSecret,
+			now:     now,
+			wantErr: middlewares.ErrMissingCSRFToken,
+		},
+		{
+			name:    "malformed",
+			csrf:    csrf,
+			secret:  testCSRFSecret,
+			token:   "not-a-token",
+			now:     now,
+			wantErr: middlewares.ErrInvalidCSRFToken,
+		},
+		{
+			name:    "malformed issue time",
+			csrf:    csrf,
+			secret:  testCSRFSecret,
+			token:   "!!." + strings.SplitN(token, ".", 2)[1],
+			now:     now,
+			wantErr: middlewares.ErrInvalidCSRFToken,
+		},
+		{
+			name:    "expired",
+			csrf:    csrf,
+			secret:  testCSRFSecret,
+			token:   token,
+			now:     now.Add(time.Hour + time.Second),
+			wantErr: middlewares.ErrInvalidCSRFToken,
+		},
+		{
+			name:    "issued in the future",
+			csrf:    csrf,
+			secret:  testCSRFSecret,
+			token:   token,
+			now:     now.Add(-2 * time.Minute),
+			wantErr: middlewares.ErrInvalidCSRFToken,
+		},
+		{
+			name:    "issued for another client",
+			csrf:    csrf,
+			secret:  "ZYXWVUTSRQPONMLKJIHGFEDCBA",
+			token:   token,
+			now:     now,
+			wantErr: middlewares.ErrInvalidCSRFToken,
+		},
+		{
+			name:    "signed with another key",
+			csrf:    middlewares.NewCSRF([]byte("other-key"), time.Hour),
+			secret:  testCSRFSecret,
+			token:   token,
+			now:     now,
+			wantErr: middlewares.ErrInvalidCSRFToken,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.csrf.VerifyCSRFToken(tt.secret, tt.token, tt.now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyCSRFToken() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// csrfHandler returns a handler protected by the CSRF middleware, which records the token it was issued.
+func csrfHandler(csrf *middlewares.CSRF, issued *string) http.Handler {
+	return csrf.Middleware(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		*issued = models.CSRFTokenFromCtx(req.Context())
+		res.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestCSRFMiddlewareIssue(t *testing.T) {
+	var issued string
+	handler := csrfHandler(middlewares.NewCSRF([]byte("test-key"), time.Hour), &issued)
+
+	// A client without a secret is given one.
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/contact", nil))
+	if res.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", res.Code, http.StatusOK)
+	}
+	if issued == "" {
+		t.Error("no token in the request context")
+	}
+	cookies := res.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value == "" || !cookies[0].HttpOnly {
+		t.Fatalf("cookies = %v, want a single http only secret", cookies)
+	}
+
+	// A client with a secret keeps it.
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/contact", nil)
+	req.AddCookie(cookies[0])
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	if got := res.Result().Cookies(); len(got) != 0 {
+		t.Errorf("cookies = %v, want the existing secret to be kept", got)
+	}
+}
+
+func TestCSRFMiddlewareVerify(t *testing.T) {
+	csrf := middlewares.NewCSRF([]byte("test-key"), time.Hour)
+	token := csrf.IssueCSRFToken(testCSRFSecret, time.Now())
+	cookie := &http.Cookie{Name: "csrf", Value: testCSRFSecret}
+
+	tests := []struct {
+		name       string
+		newRequest func(t *testing.T) *http.Request
+		wantStatus int
+	}{
+		{
+			name: "header",
+			newRequest: func(t *testing.T) *http.Request {
+				t.Helper()
+				req := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/contact", nil)
+				req.Header.Set(models.CSRFTokenHeader, token)
+				req.AddCookie(cookie)
+				return req
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "url-encoded form field",
+			newRequest: func(t *testing.T) *http.Request {
+				t.Helper()
+				body := url.Values{"csrf_token": {token}}.Encode()
+				req := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/contact", strings.NewReader(body))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				req.AddCookie(cookie)
+				return req
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "multipart form field is not read",
+			newRequest: func(t *testing.T) *http.Request {
+				t.Helper()
+				var body strings.Builder
+				writer := multipart.NewWriter(&body)
+				if err := writer.WriteField("csrf_token", token); err != nil {
+					t.Fatalf("write field: %v", err)
+				}
+				writer.Close() //nolint:errcheck
+				req := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/contact",
+					strings.NewReader(body.String()))
+				req.Header.Set("Content-Type", writer.FormDataContentType())
+				req.AddCookie(cookie)
+				return req
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "missing token",
+			newRequest: func(t *testing.T) *http.Request {
+				t.Helper()
+				req := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/contact", nil)
+				req.AddCookie(cookie)
+				return req
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "missing secret",
+			newRequest: func(t *testing.T) *http.Request {
+				t.Helper()
+				req := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/contact", nil)
+				req.Header.Set(models.CSRFTokenHeader, token)
+				return req
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "safe method",
+			newRequest: func(t *testing.T) *http.Request {
+				t.Helper()
+				return httptest.NewRequestWithContext(t.Context(), http.MethodHead, "/contact", nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var issued string
+			res := httptest.NewRecorder()
+			csrfHandler(csrf, &issued).ServeHTTP(res, tt.newRequest(t))
+
+			if res.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", res.Code, tt.wantStatus)
+			}
+			if passed := issued != ""; passed != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("request passed to the handler = %t", passed)
+			}
+		})
+	}
+}
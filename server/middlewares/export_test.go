@@ -0,0 +1,16 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package middlewares
+
+import "time"
+
+// IssueCSRFToken exposes issuing a token at a given time for tests.
+func (c *CSRF) IssueCSRFToken(secret string, now time.Time) string {
+	return c.issue(secret, now)
+}
+
+// VerifyCSRFToken exposes verifying a token at a given time for tests.
+func (c *CSRF) VerifyCSRFToken(secret, token string, now time.Time) error {
+	return c.verify(secret, token, now)
+}
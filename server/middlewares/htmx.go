@@ -7,6 +7,8 @@ import (
 	"net/http"
 
 	"github.com/angelofallars/htmx-go"
+
+	"github.com/immanent-tech/www-immanent-tech/server/respond"
 )
 
 // SetupHTMX middleware performs general setup for serving htmx-powered content.
@@ -19,11 +21,14 @@ func SetupHTMX(next http.Handler) http.Handler {
 }
 
 // RequireHTMX middleware will only pass control to the next handler if the request is htmx powered. If not, it will
-// return 403: Forbidden response.
+// return 403: Forbidden response, rendered as an error page or JSON error as appropriate for the client.
 func RequireHTMX(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
 		if !htmx.IsHTMX(req) {
-			http.Error(res, "Not allowed", http.StatusForbidden)
+			respond.Error(http.StatusForbidden,
+				"Not allowed.",
+				"This page cannot be requested directly.",
+			).ServeHTTP(res, req)
 			return
 		}
 		next.ServeHTTP(res, req)
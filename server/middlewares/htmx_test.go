@@ -0,0 +1,88 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/immanent-tech/www-immanent-tech/server/middlewares"
+)
+
+func TestRequireHTMX(t *testing.T) {
+	tests := []struct {
+		name        string
+		headers     map[string]string
+		wantStatus  int
+		wantType    string
+		wantContain string
+	}{
+		{
+			name:       "htmx",
+			headers:    map[string]string{"HX-Request": "true"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:        "browser",
+			headers:     map[string]string{"Accept": "text/html"},
+			wantStatus:  http.StatusForbidden,
+			wantType:    "text/html",
+			wantContain: "<html",
+		},
+		{
+			name:        "other client",
+			wantStatus:  http.StatusForbidden,
+			wantType:    "application/json",
+			wantContain: `"forbidden"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/partial", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			res := httptest.NewRecorder()
+			middlewares.RequireHTMX(okHandler).ServeHTTP(res, req)
+
+			if res.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", res.Code, tt.wantStatus)
+			}
+			if got := res.Header().Get("Content-Type"); !strings.HasPrefix(got, tt.wantType) {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantType)
+			}
+			if !strings.Contains(res.Body.String(), tt.wantContain) {
+				t.Errorf("body does not contain %q: %s", tt.wantContain, res.Body.String())
+			}
+		})
+	}
+}
+
+// TestRateLimitHTMX checks rate-limited htmx requests are shown a notification, as htmx does not swap error responses
+// into forms.
+func TestRateLimitHTMX(t *testing.T) {
+	handler := middlewares.NewRateLimiter(nil).
+		Limit("test", middlewares.RateLimit{Rate: 0.01, Burst: 1})(okHandler)
+
+	var res *httptest.ResponseRecorder
+	for range 2 {
+		req := newLimitedRequest(t, "/contact", "192.0.2.1")
+		req.Header.Set("HX-Request", "true")
+		res = httptest.NewRecorder()
+		handler.ServeHTTP(res, req)
+	}
+
+	if res.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", res.Code, http.StatusTooManyRequests)
+	}
+	body := res.Body.String()
+	if !strings.Contains(body, `hx-swap-oob="beforeend:#notifications"`) || strings.Contains(body, "<html") {
+		t.Errorf("response is not a notification: %s", body)
+	}
+	if res.Header().Get("Retry-After") == "" {
+		t.Error("no Retry-After header")
+	}
+}
@@ -5,77 +5,115 @@ package middlewares
 
 import (
 	"log/slog"
+	"math"
 	"net/http"
-	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/didip/tollbooth/v8"
 	"github.com/didip/tollbooth/v8/limiter"
-	"github.com/immanent-tech/go-base/config"
-	"github.com/realclientip/realclientip-go"
+	slogchi "github.com/samber/slog-chi"
 	slogctx "github.com/veqryn/slog-context"
-)
 
-const (
-	clientIPHeader = "X-Forwarded-For"
+	"github.com/immanent-tech/www-immanent-tech/models"
+	"github.com/immanent-tech/www-immanent-tech/server/respond"
 )
 
+// bucketTTL is the time after which the token bucket of a client that has not made any requests is discarded.
+const bucketTTL = time.Hour
+
+// RateLimit is the limit on the rate of requests from each client.
+type RateLimit struct {
+	// Rate is the number of requests per second a client can make. A rate of 0 disables the limit.
+	Rate float64
+	// Burst is the number of requests a client can make in quick succession. It is at least 1.
+	Burst int
+}
+
 // RateLimiter holds options for controlling a rate limiter middleware.
 type RateLimiter struct {
-	strategy realclientip.RightmostNonPrivateStrategy
-	limiter  *limiter.Limiter
+	exempt []string
 }
 
-// NewRateLimiter initialises data for a rate limiter middleware.
-func NewRateLimiter() RateLimiter {
-	// Set up rate-limiting.
-	strategy, err := realclientip.NewRightmostNonPrivateStrategy(clientIPHeader)
-	if err != nil {
-		panic("realclientip.NewRightmostNonPrivateStrategy returned error (bad input)")
-	}
-	lmt := tollbooth.NewLimiter(5, nil)
-	lmt.SetIPLookup(limiter.IPLookup{
-		Name:           clientIPHeader,
-		IndexFromRight: 0,
-	})
-	return RateLimiter{
-		strategy: strategy,
-		limiter:  lmt,
+// NewRateLimiter initialises data for a rate limiter middleware. Clients are identified by the IP address stored in the
+// request context by ClientIPResolver.Middleware, which must be used before any limits. Requests to the exempt paths
+// are not limited, where a path ending in "/*" exempts all paths beneath it.
+func NewRateLimiter(exemptPaths []string) *RateLimiter {
+	return &RateLimiter{
+		exempt: exemptPaths,
 	}
 }
 
-// RateLimit middleware will try to rate limit incoming requests with a pre-defined strategy.
-func RateLimit(ratelimiter RateLimiter) func(next http.Handler) http.Handler {
+// Limit returns a middleware that limits the rate of requests from each client to the routes it is used on. Each call
+// creates a separate limit, so the name is used to identify the limit in logs. All responses include the RateLimit-*
+// headers describing the limit. Requests over the limit receive a 429: Too Many Requests response with a Retry-After
+// header, rendered as appropriate for the client.
+func (l *RateLimiter) Limit(name string, limit RateLimit) func(next http.Handler) http.Handler {
+	if limit.Rate <= 0 {
+		return func(next http.Handler) http.Handler {
+			return next
+		}
+	}
+	burst := max(limit.Burst, 1)
+	lmt := tollbooth.NewLimiter(limit.Rate, &limiter.ExpirableOptions{DefaultExpirationTTL: bucketTTL}).
+		SetBurst(burst)
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
-			// Ignore rate-limiting in development environment or for health probes in GCP.
-			if !config.IsProduction() || slices.Contains([]string{"/livenessProbe"}, req.URL.Path) {
-				next.ServeHTTP(res, req)
-				return
-			}
-			// Ignore rate-limiting from self.
-			if req.Host == "immanent.tech" {
+			if l.isExempt(req.URL.Path) {
 				next.ServeHTTP(res, req)
 				return
 			}
 			// Find the client IP.
-			clientIP := ratelimiter.strategy.ClientIP(req.Header, req.RemoteAddr)
+			clientIP := models.ClientIPFromCtx(req.Context())
 			if clientIP == "" {
 				slogctx.FromCtx(req.Context()).Error("Unable to determine client IP.")
-				http.Error(res, "I don't know who you are", http.StatusForbidden)
+				respond.Error(http.StatusForbidden,
+					"Unable to identify you.",
+					"Your request could not be associated with an address.",
+				).ServeHTTP(res, req)
 				return
 			}
-			// We don't want to include the zone in our limiter key
-			clientIP, _ = realclientip.SplitHostZone(clientIP)
 
-			if httpErr := tollbooth.LimitByKeys(ratelimiter.limiter, []string{clientIP}); httpErr != nil {
+			httpErr, remaining := tollbooth.LimitByKeysAndReturn(lmt, []string{clientIP})
+			res.Header().Set("RateLimit-Limit", strconv.Itoa(burst))
+			res.Header().Set("RateLimit-Remaining", strconv.Itoa(remaining))
+			res.Header().Set("RateLimit-Reset", strconv.Itoa(seconds(float64(burst-remaining)/limit.Rate)))
+			if httpErr != nil {
+				slogchi.AddCustomAttributes(req, slog.String("rate_limit", name))
 				slogctx.FromCtx(req.Context()).Warn("Request rate-limited.",
+					slog.String("limit", name),
 					slog.String("error", httpErr.Message),
 					slog.Int("code", httpErr.StatusCode),
 				)
-				http.Error(res, httpErr.Message, httpErr.StatusCode)
+				res.Header().Set("Retry-After", strconv.Itoa(seconds(1/limit.Rate)))
+				respond.Error(httpErr.StatusCode,
+					"Too many requests.",
+					"Please wait a moment and try again.",
+				).ServeHTTP(res, req)
 				return
 			}
 			next.ServeHTTP(res, req)
 		})
 	}
 }
+
+// isExempt reports whether requests to the path are exempt from rate limiting.
+func (l *RateLimiter) isExempt(path string) bool {
+	for _, exempt := range l.exempt {
+		if prefix, found := strings.CutSuffix(exempt, "*"); found {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if path == exempt {
+			return true
+		}
+	}
+	return false
+}
+
+// seconds returns the duration in seconds, rounded up to a whole number of at least 1.
+func seconds(duration float64) int {
+	return max(int(math.Ceil(duration)), 1)
+}
@@ -0,0 +1,129 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/immanent-tech/www-immanent-tech/models"
+	"github.com/immanent-tech/www-immanent-tech/server/middlewares"
+)
+
+var okHandler = http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+	res.WriteHeader(http.StatusOK)
+})
+
+// newLimitedRequest creates a request to the path from the client IP, as stored by ClientIPResolver.Middleware.
+func newLimitedRequest(t *testing.T, path, clientIP string) *http.Request {
+	t.Helper()
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, path, nil)
+	if clientIP != "" {
+		req = req.WithContext(models.ClientIPToCtx(req.Context(), clientIP))
+	}
+	return req
+}
+
+func TestRateLimit(t *testing.T) {
+	handler := middlewares.NewRateLimiter(nil).
+		Limit("test", middlewares.RateLimit{Rate: 0.01, Burst: 2})(okHandler)
+
+	for i, want := range []struct {
+		status    int
+		remaining string
+	}{
+		{status: http.StatusOK, remaining: "1"},
+		{status: http.StatusOK, remaining: "0"},
+		{status: http.StatusTooManyRequests, remaining: "0"},
+	} {
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, newLimitedRequest(t, "/", "192.0.2.1"))
+		if res.Code != want.status {
+			t.Fatalf("request %d status = %d, want %d", i, res.Code, want.status)
+		}
+		if got := res.Header().Get("RateLimit-Limit"); got != "2" {
+			t.Errorf("request %d RateLimit-Limit = %q, want 2", i, got)
+		}
+		if got := res.Header().Get("RateLimit-Remaining"); got != want.remaining {
+			t.Errorf("request %d RateLimit-Remaining = %q, want %s", i, got, want.remaining)
+		}
+		if got := res.Header().Get("RateLimit-Reset"); got == "" || got == "0" {
+			t.Errorf("request %d RateLimit-Reset = %q, want a positive number of seconds", i, got)
+		}
+		retryAfter := res.Header().Get("Retry-After")
+		if limited := want.status == http.StatusTooManyRequests; limited != (retryAfter == "100") {
+			t.Errorf("request %d Retry-After = %q", i, retryAfter)
+		}
+	}
+
+	// Other clients have their own limit.
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, newLimitedRequest(t, "/", "192.0.2.2"))
+	if res.Code != http.StatusOK {
+		t.Errorf("other client status = %d, want %d", res.Code, http.StatusOK)
+	}
+}
+
+func TestRateLimitSeparateLimits(t *testing.T) {
+	limiter := middlewares.NewRateLimiter(nil)
+	strict := limiter.Limit("strict", middlewares.RateLimit{Rate: 0.01, Burst: 1})(okHandler)
+	generous := limiter.Limit("generous", middlewares.RateLimit{Rate: 0.01, Burst: 5})(okHandler)
+
+	strict.ServeHTTP(httptest.NewRecorder(), newLimitedRequest(t, "/contact", "192.0.2.1"))
+	res := httptest.NewRecorder()
+	generous.ServeHTTP(res, newLimitedRequest(t, "/", "192.0.2.1"))
+	if res.Code != http.StatusOK {
+		t.Errorf("status under a separate limit = %d, want %d", res.Code, http.StatusOK)
+	}
+}
+
+func TestRateLimitExempt(t *testing.T) {
+	tests := []struct {
+		path       string
+		wantStatus int
+	}{
+		{path: "/robots.txt", wantStatus: http.StatusOK},
+		{path: "/static/app.css", wantStatus: http.StatusOK},
+		{path: "/static/fonts/inter.woff2", wantStatus: http.StatusOK},
+		{path: "/robots.txt.bak", wantStatus: http.StatusTooManyRequests},
+		{path: "/statically", wantStatus: http.StatusTooManyRequests},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			handler := middlewares.NewRateLimiter([]string{"/robots.txt", "/static/*"}).
+				Limit("test", middlewares.RateLimit{Rate: 0.01, Burst: 1})(okHandler)
+
+			// The first request uses the burst, so only exempt paths succeed on the second.
+			handler.ServeHTTP(httptest.NewRecorder(), newLimitedRequest(t, tt.path, "192.0.2.1"))
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, newLimitedRequest(t, tt.path, "192.0.2.1"))
+			if res.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", res.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	handler := middlewares.NewRateLimiter(nil).Limit("test", middlewares.RateLimit{})(okHandler)
+	for range 10 {
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, newLimitedRequest(t, "/", "192.0.2.1"))
+		if res.Code != http.StatusOK || res.Header().Get("RateLimit-Limit") != "" {
+			t.Fatalf("status = %d with RateLimit-Limit %q, want the request unlimited",
+				res.Code, res.Header().Get("RateLimit-Limit"))
+		}
+	}
+}
+
+func TestRateLimitNoClientIP(t *testing.T) {
+	handler := middlewares.NewRateLimiter(nil).
+		Limit("test", middlewares.RateLimit{Rate: 1, Burst: 1})(okHandler)
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, newLimitedRequest(t, "/", ""))
+	if res.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", res.Code, http.StatusForbidden)
+	}
+}
@@ -0,0 +1,11 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package outbox
+
+import "time"
+
+// Backoff exports backoff for testing.
+func (o *Outbox) Backoff(attempts int) time.Duration {
+	return o.backoff(attempts)
+}
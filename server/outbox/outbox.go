@@ -0,0 +1,440 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+// Package outbox provides a durable, on-disk queue of email messages. Messages are written to the outbox and then
+// delivered by a background worker, with failed deliveries retried with an exponential backoff until they succeed or
+// are dead-lettered. The outbox is local to each server instance, which delivers its own messages and tries to drain
+// the outbox when it is shut down.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/immanent-tech/go-base/config"
+	"github.com/immanent-tech/go-base/validation"
+	slogctx "github.com/veqryn/slog-context"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/immanent-tech/www-immanent-tech/providers/mailer"
+	"github.com/immanent-tech/www-immanent-tech/server/spam"
+)
+
+const (
+	configPrefix = "OUTBOX_"
+)
+
+var (
+	pendingBucket    = []byte("pending")
+	deadLetterBucket = []byte("deadletter")
+	quarantineBucket = []byte("quarantine")
+)
+
+var (
+	// ErrClosed is returned when trying to add a message to an outbox that has been shut down.
+	ErrClosed = errors.New("outbox is closed")
+)
+
+// Config contains the outbox configuration options.
+type Config struct {
+	// Path is the location of the outbox database file. It must not be shared with other server instances, as the
+	// database can only be opened by a single process. Any undelivered messages are lost with it, such as when the
+	// instance stops on Cloud Run, where the filesystem is held in memory. A file in the temporary directory is used if
+	// it is not set.
+	Path string `koanf:"path" validate:"omitempty"`
+	// MaxAttempts is the number of delivery attempts made before a message is dead-lettered.
+	MaxAttempts int `koanf:"maxattempts" validate:"required,min=1"`
+	// MinBackoff is the delay before the first retry of a failed delivery.
+	MinBackoff config.Duration `koanf:"minbackoff" validate:"omitempty"`
+	// MaxBackoff is the maximum delay between retries.
+	MaxBackoff config.Duration `koanf:"maxbackoff" validate:"omitempty"`
+	// PollInterval is how often the worker checks for messages that are due for a retry.
+	PollInterval config.Duration `koanf:"pollinterval" validate:"omitempty"`
+}
+
+var cfg = Config{
+	MaxAttempts:  8,
+	MinBackoff:   config.NewDuration(30 * time.Second),
+	MaxBackoff:   config.NewDuration(1 * time.Hour),
+	PollInterval: config.NewDuration(15 * time.Second),
+}
+
+// loadConfig loads the outbox configuration and ensures this is only done
+// one time, no matter how many times it is called.
+var loadConfig = sync.OnceValue(func() error {
+	if err := config.Load(configPrefix, &cfg); err != nil {
+		return fmt.Errorf("load config from environment: %w", err)
+	}
+	if err := validation.Validate.Struct(cfg); err != nil {
+		return fmt.Errorf("validate config: %w", err)
+	}
+	return nil
+})
+
+// Entry is a message stored in the outbox, along with its delivery state.
+type Entry struct {
+	ID          string          `json:"id"`
+	Message     *mailer.Message `json:"message"`
+	Attempts    int             `json:"attempts"`
+	CreatedAt   time.Time       `json:"created_at"`
+	NextAttempt time.Time       `json:"next_attempt"`
+	LastError   string          `json:"last_error,omitempty"`
+	Spam        *spam.Verdict   `json:"spam,omitempty"`
+}
+
+// Result is the outcome of a delivery attempt.
+type Result int
+
+const (
+	// ResultDelivered indicates the message was delivered.
+	ResultDelivered Result = iota
+	// ResultRetrying indicates delivery failed and will be retried.
+	ResultRetrying
+	// ResultDeadLettered indicates delivery failed and the message was dead-lettered.
+	ResultDeadLettered
+)
+
+// DeliveryHook is called after each delivery attempt with the entry and the result of the attempt.
+type DeliveryHook func(ctx context.Context, entry *Entry, result Result)
+
+// Option is a functional option for configuring an Outbox.
+type Option func(*Outbox)
+
+// WithDeliveryHook option sets a hook that will be called after each delivery attempt.
+func WithDeliveryHook(hook DeliveryHook) Option {
+	return func(o *Outbox) {
+		o.hook = hook
+	}
+}
+
+// Outbox is a durable queue of messages to be delivered by a mailer.Mailer. It implements mailer.Mailer itself, so it
+// can be used in place of the mailer it wraps.
+type Outbox struct {
+	db     *bolt.DB
+	mailer mailer.Mailer
+	cfg    Config
+	hook   DeliveryHook
+	wake   chan struct{}
+	stop   chan struct{}
+	wg     sync.WaitGroup
+	mu     sync.RWMutex
+	closed bool
+}
+
+// New opens (or creates) the outbox with config loaded from the environment. Messages will be delivered with the
+// given mailer.
+func New(m mailer.Mailer, options ...Option) (*Outbox, error) {
+	if err := loadConfig(); err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	return NewWithConfig(m, cfg, options...)
+}
+
+// NewWithConfig opens (or creates) the outbox with the given config. Messages will be delivered with the given mailer.
+func NewWithConfig(m mailer.Mailer, outboxCfg Config, options ...Option) (*Outbox, error) {
+	if err := validation.Validate.Struct(outboxCfg); err != nil {
+		return nil, fmt.Errorf("validate config: %w", err)
+	}
+	path := databasePath(outboxCfg.Path)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("create outbox directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open outbox database: %w", err)
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{pendingBucket, deadLetterBucket, quarantineBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return fmt.Errorf("create bucket %s: %w", bucket, err)
+			}
+		}
+		return nil
+	}); err != nil {
+		db.Close() //nolint:errcheck
+		return nil, fmt.Errorf("initialise outbox database: %w", err)
+	}
+
+	box := &Outbox{
+		db:     db,
+		mailer: m,
+		cfg:    outboxCfg,
+		wake:   make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+	}
+	for option := range slices.Values(options) {
+		option(box)
+	}
+
+	return box, nil
+}
+
+// databasePath returns the path of the outbox database, which is a file in the temporary directory if no path is
+// configured.
+func databasePath(path string) string {
+	if path != "" {
+		return path
+	}
+	return filepath.Join(os.TempDir(), "www-immanent-tech", "outbox.db")
+}
+
+// Send will durably store the message in the outbox for delivery by the worker. A nil error means the message has
+// been queued, not that it has been delivered.
+func (o *Outbox) Send(ctx context.Context, msg *mailer.Message) error {
+	now := time.Now()
+	entry := &Entry{
+		Message:     msg,
+		CreatedAt:   now,
+		NextAttempt: now,
+	}
+	if err := o.add(pendingBucket, entry); err != nil {
+		return err
+	}
+
+	slogctx.FromCtx(ctx).Debug("Message added to outbox.",
+		slog.String("outbox_id", entry.ID),
+	)
+
+	// Wake the worker, if it is not already awake.
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// Quarantine will store a message that has been identified as spam in the outbox. Quarantined messages are never
+// delivered, but are kept for review.
+func (o *Outbox) Quarantine(ctx context.Context, msg *mailer.Message, verdict *spam.Verdict) error {
+	entry := &Entry{
+		Message:   msg,
+		CreatedAt: time.Now(),
+		Spam:      verdict,
+	}
+	if err := o.add(quarantineBucket, entry); err != nil {
+		return err
+	}
+
+	slogctx.FromCtx(ctx).Debug("Message quarantined in outbox.",
+		slog.String("outbox_id", entry.ID),
+	)
+	return nil
+}
+
+// add stores a new entry in the given bucket, assigning it an ID.
+func (o *Outbox) add(bucketName []byte, entry *Entry) error {
+	if err := entry.Message.Valid(); err != nil {
+		return fmt.Errorf("invalid message: %w", err)
+	}
+
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	if o.closed {
+		return ErrClosed
+	}
+
+	if err := o.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(bucketName)
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return fmt.Errorf("generate id: %w", err)
+		}
+		entry.ID = fmt.Sprintf("%016x", seq)
+		return putEntry(bucket, entry)
+	}); err != nil {
+		return fmt.Errorf("store message: %w", err)
+	}
+	return nil
+}
+
+// Start runs the background worker that delivers messages from the outbox. Any pending messages are flushed
+// immediately, regardless of their retry schedule, as they may have been left behind by a previous instance. The
+// worker runs until Shutdown is called.
+func (o *Outbox) Start(ctx context.Context) {
+	ctx = context.WithoutCancel(ctx)
+	o.wg.Go(func() {
+		o.deliver(ctx, o.stop, true)
+
+		ticker := time.NewTicker(o.cfg.PollInterval.Duration)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-o.stop:
+				return
+			case <-o.wake:
+				o.deliver(ctx, o.stop, false)
+			case <-ticker.C:
+				o.deliver(ctx, o.stop, false)
+			}
+		}
+	})
+}
+
+// Shutdown stops the worker, waiting for any in-progress delivery to finish, then makes a final attempt to deliver
+// any messages that are due before closing the outbox. Messages that cannot be delivered before the context is done
+// are left in the outbox for the next instance to deliver.
+func (o *Outbox) Shutdown(ctx context.Context) error {
+	o.mu.Lock()
+	if o.closed {
+		o.mu.Unlock()
+		return nil
+	}
+	o.closed = true
+	o.mu.Unlock()
+
+	close(o.stop)
+	o.wg.Wait()
+
+	o.deliver(ctx, nil, false)
+
+	if err := o.db.Close(); err != nil {
+		return fmt.Errorf("close outbox database: %w", err)
+	}
+	return nil
+}
+
+// deliver attempts delivery of each pending message that is due (or all pending messages if all is true). Delivery
+// will stop early if the context is done or the stop channel is closed.
+func (o *Outbox) deliver(ctx context.Context, stop <-chan struct{}, all bool) {
+	logger := slogctx.FromCtx(ctx)
+
+	entries, err := o.pending(time.Now(), all)
+	if err != nil {
+		logger.Error("Could not read pending messages from outbox.",
+			slog.Any("error", err),
+		)
+		return
+	}
+
+	for _, entry := range entries {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stop:
+			return
+		default:
+		}
+
+		sendErr := o.mailer.Send(ctx, entry.Message)
+		if err := o.update(entry, sendErr); err != nil {
+			logger.Error("Could not update outbox message.",
+				slog.String("outbox_id", entry.ID),
+				slog.Any("error", err),
+			)
+			continue
+		}
+		var result Result
+		switch {
+		case sendErr == nil:
+			result = ResultDelivered
+			logger.Info("Delivered message from outbox.",
+				slog.String("outbox_id", entry.ID),
+				slog.Int("attempts", entry.Attempts),
+			)
+		case entry.Attempts >= o.cfg.MaxAttempts:
+			result = ResultDeadLettered
+			logger.Error("Message dead-lettered after too many delivery attempts.",
+				slog.String("outbox_id", entry.ID),
+				slog.Int("attempts", entry.Attempts),
+				slog.Any("error", sendErr),
+			)
+		default:
+			result = ResultRetrying
+			logger.Warn("Could not deliver message from outbox, will retry.",
+				slog.String("outbox_id", entry.ID),
+				slog.Int("attempts", entry.Attempts),
+				slog.Time("next_attempt", entry.NextAttempt),
+				slog.Any("error", sendErr),
+			)
+		}
+		if o.hook != nil {
+			o.hook(ctx, entry, result)
+		}
+	}
+}
+
+// pending returns the messages that are due for delivery at the given time (or all pending messages if all is true).
+func (o *Outbox) pending(now time.Time, all bool) ([]*Entry, error) {
+	var entries []*Entry
+	err := o.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(pendingBucket).ForEach(func(_, value []byte) error {
+			entry := &Entry{}
+			if err := json.Unmarshal(value, entry); err != nil {
+				return fmt.Errorf("decode entry: %w", err)
+			}
+			if all || !entry.NextAttempt.After(now) {
+				entries = append(entries, entry)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read pending: %w", err)
+	}
+	return entries, nil
+}
+
+// update records the outcome of a delivery attempt. Delivered messages are removed from the outbox. Failed messages
+// are rescheduled or, if they have reached the maximum number of attempts, moved to the dead-letter bucket.
+func (o *Outbox) update(entry *Entry, sendErr error) error {
+	entry.Attempts++
+	err := o.db.Update(func(tx *bolt.Tx) error {
+		pending := tx.Bucket(pendingBucket)
+		if sendErr == nil {
+			if err := pending.Delete([]byte(entry.ID)); err != nil {
+				return fmt.Errorf("remove entry: %w", err)
+			}
+			return nil
+		}
+
+		entry.LastError = sendErr.Error()
+		if entry.Attempts >= o.cfg.MaxAttempts {
+			if err := pending.Delete([]byte(entry.ID)); err != nil {
+				return fmt.Errorf("remove entry: %w", err)
+			}
+			return putEntry(tx.Bucket(deadLetterBucket), entry)
+		}
+
+		entry.NextAttempt = time.Now().Add(o.backoff(entry.Attempts))
+		return putEntry(pending, entry)
+	})
+	if err != nil {
+		return fmt.Errorf("update entry: %w", err)
+	}
+	return nil
+}
+
+// backoff returns the delay before the next delivery attempt, doubling the minimum backoff for each attempt made up to
+// the maximum backoff. Up to 10% jitter is added so that retries from multiple instances are spread out.
+func (o *Outbox) backoff(attempts int) time.Duration {
+	delay := o.cfg.MinBackoff.Duration
+	for i := 1; i < attempts && delay < o.cfg.MaxBackoff.Duration; i++ {
+		delay *= 2
+	}
+	delay = min(delay, o.cfg.MaxBackoff.Duration)
+	if jitter := int64(delay / 10); jitter > 0 {
+		delay += time.Duration(rand.Int64N(jitter))
+	}
+	return delay
+}
+
+func putEntry(bucket *bolt.Bucket, entry *Entry) error {
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode entry: %w", err)
+	}
+	if err := bucket.Put([]byte(entry.ID), value); err != nil {
+		return fmt.Errorf("store entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/immanent-tech/go-base/config"
+
+	"github.com/immanent-tech/www-immanent-tech/providers/mailer"
+	"github.com/immanent-tech/www-immanent-tech/server/outbox"
+)
+
+var errDeliveryFailed = errors.New("delivery failed")
+
+// testMailer records the messages it is asked to send, failing with err if set.
+type testMailer struct {
+	mu   sync.Mutex
+	sent []*mailer.Message
+	err  error
+}
+
+func (m *testMailer) Send(_ context.Context, msg *mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func (m *testMailer) messages() []*mailer.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.sent
+}
+
+func newTestConfig(t *testing.T) outbox.Config {
+	t.Helper()
+	return outbox.Config{
+		Path:         filepath.Join(t.TempDir(), "outbox.db"),
+		MaxAttempts:  3,
+		MinBackoff:   config.NewDuration(time.Second),
+		MaxBackoff:   config.NewDuration(10 * time.Second),
+		PollInterval: config.NewDuration(time.Hour),
+	}
+}
+
+func newTestMessage(subject string) *mailer.Message {
+	return &mailer.Message{
+		From:    &mail.Address{Address: "visitor@example.com"},
+		To:      []*mail.Address{{Address: "hello@example.com"}},
+		Subject: subject,
+		Text:    "hello",
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	box, err := outbox.NewWithConfig(&testMailer{}, newTestConfig(t))
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+	t.Cleanup(func() {
+		box.Shutdown(t.Context())
+	})
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: time.Second},
+		{attempts: 2, want: 2 * time.Second},
+		{attempts: 3, want: 4 * time.Second},
+		{attempts: 4, want: 8 * time.Second},
+		{attempts: 5, want: 10 * time.Second},
+		{attempts: 20, want: 10 * time.Second},
+	}
+	for _, tt := range tests {
+		got := box.Backoff(tt.attempts)
+		// Up to 10% jitter is added to the delay.
+		if got < tt.want || got >= tt.want+tt.want/10 {
+			t.Errorf("Backoff(%d) = %v, want between %v and %v", tt.attempts, got, tt.want, tt.want+tt.want/10)
+		}
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	sender := &testMailer{}
+	results := make(chan outbox.Result, 1)
+	box, err := outbox.NewWithConfig(sender, newTestConfig(t),
+		outbox.WithDeliveryHook(func(_ context.Context, _ *outbox.Entry, result outbox.Result) {
+			results <- result
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+	box.Start(t.Context())
+	t.Cleanup(func() {
+		box.Shutdown(t.Context())
+	})
+
+	if err := box.Send(t.Context(), newTestMessage("queued")); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if result := waitForResult(t, results); result != outbox.ResultDelivered {
+		t.Errorf("result = %v, want %v", result, outbox.ResultDelivered)
+	}
+	if sent := sender.messages(); len(sent) != 1 || sent[0].Subject != "queued" {
+		t.Errorf("mailer sent %v, want the queued message", sent)
+	}
+}
+
+func TestDeadLetter(t *testing.T) {
+	cfg := newTestConfig(t)
+	// Retry immediately, so that the test does not wait for the backoff.
+	cfg.MinBackoff = config.NewDuration(0)
+	cfg.MaxBackoff = config.NewDuration(0)
+	cfg.PollInterval = config.NewDuration(10 * time.Millisecond)
+
+	var (
+		mu       sync.Mutex
+		attempts []int
+	)
+	results := make(chan outbox.Result, cfg.MaxAttempts)
+	box, err := outbox.NewWithConfig(&testMailer{err: errDeliveryFailed}, cfg,
+		outbox.WithDeliveryHook(func(_ context.Context, entry *outbox.Entry, result outbox.Result) {
+			mu.Lock()
+			attempts = append(attempts, entry.Attempts)
+			mu.Unlock()
+			results <- result
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+	box.Start(t.Context())
+	t.Cleanup(func() {
+		box.Shutdown(t.Context())
+	})
+
+	if err := box.Send(t.Context(), newTestMessage("undeliverable")); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	for attempt := 1; attempt <= cfg.MaxAttempts; attempt++ {
+		want := outbox.ResultRetrying
+		if attempt == cfg.MaxAttempts {
+			want = outbox.ResultDeadLettered
+		}
+		if result := waitForResult(t, results); result != want {
+			t.Fatalf("attempt %d result = %v, want %v", attempt, result, want)
+		}
+	}
+
+	// A dead-lettered message is not retried.
+	select {
+	case result := <-results:
+		t.Errorf("dead-lettered message was retried with result %v", result)
+	case <-time.After(50 * time.Millisecond):
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for i, got := range attempts {
+		if got != i+1 {
+			t.Errorf("attempts = %v, want each attempt counted", attempts)
+			break
+		}
+	}
+}
+
+func TestShutdownDrains(t *testing.T) {
+	sender := &testMailer{}
+	box, err := outbox.NewWithConfig(sender, newTestConfig(t))
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+
+	// Without a running worker, the message is only delivered by the final attempt on shutdown.
+	if err := box.Send(t.Context(), newTestMessage("draining")); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if err := box.Shutdown(t.Context()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if sent := sender.messages(); len(sent) != 1 || sent[0].Subject != "draining" {
+		t.Errorf("mailer sent %v, want the pending message", sent)
+	}
+
+	if err := box.Send(t.Context(), newTestMessage("late")); !errors.Is(err, outbox.ErrClosed) {
+		t.Errorf("Send() after Shutdown() error = %v, want %v", err, outbox.ErrClosed)
+	}
+	if err := box.Shutdown(t.Context()); err != nil {
+		t.Errorf("second Shutdown() error = %v", err)
+	}
+}
+
+func TestShutdownKeepsUndelivered(t *testing.T) {
+	cfg := newTestConfig(t)
+
+	box, err := outbox.NewWithConfig(&testMailer{err: errDeliveryFailed}, cfg)
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+	if err := box.Send(t.Context(), newTestMessage("persisted")); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if err := box.Shutdown(t.Context()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	// The next instance flushes the message left behind, regardless of its retry schedule.
+	sender := &testMailer{}
+	results := make(chan outbox.Result, 1)
+	box, err = outbox.NewWithConfig(sender, cfg,
+		outbox.WithDeliveryHook(func(_ context.Context, _ *outbox.Entry, result outbox.Result) {
+			results <- result
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+	box.Start(t.Context())
+	t.Cleanup(func() {
+		box.Shutdown(t.Context())
+	})
+	if result := waitForResult(t, results); result != outbox.ResultDelivered {
+		t.Errorf("result = %v, want %v", result, outbox.ResultDelivered)
+	}
+	if sent := sender.messages(); len(sent) != 1 || sent[0].Subject != "persisted" {
+		t.Errorf("mailer sent %v, want the persisted message", sent)
+	}
+}
+
+func TestShutdownCancelled(t *testing.T) {
+	sender := &testMailer{}
+	box, err := outbox.NewWithConfig(sender, newTestConfig(t))
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+	if err := box.Send(t.Context(), newTestMessage("abandoned")); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if err := box.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if sent := sender.messages(); len(sent) != 0 {
+		t.Errorf("mailer sent %d messages after the shutdown deadline", len(sent))
+	}
+}
+
+func waitForResult(t *testing.T, results <-chan outbox.Result) outbox.Result {
+	t.Helper()
+	select {
+	case result := <-results:
+		return result
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for delivery")
+		return 0
+	}
+}
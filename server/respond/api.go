@@ -0,0 +1,27 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package respond
+
+import (
+	"log/slog"
+	"net/http"
+
+	slogctx "github.com/veqryn/slog-context"
+
+	"github.com/immanent-tech/www-immanent-tech/models"
+)
+
+// APIError writes an error response from the API.
+func APIError(res http.ResponseWriter, req *http.Request, status int, apiErr *models.APIError) {
+	JSON(res, req, status, &models.APIErrorResponse{Error: apiErr})
+}
+
+// JSON writes a JSON response from the API.
+func JSON(res http.ResponseWriter, req *http.Request, status int, value any) {
+	if err := models.WriteJSON(res, status, value); err != nil {
+		slogctx.FromCtx(req.Context()).Error("Could not write response.",
+			slog.Any("error", err),
+		)
+	}
+}
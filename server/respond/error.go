@@ -0,0 +1,65 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package respond
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/angelofallars/htmx-go"
+
+	"github.com/immanent-tech/www-immanent-tech/models"
+	"github.com/immanent-tech/www-immanent-tech/web/templates"
+)
+
+// ErrorPage contains an error to be shown to the user in place of the requested content.
+type ErrorPage struct {
+	status      int
+	title       string
+	description string
+}
+
+// Error handles rejecting a request with the given status. htmx requests are shown a notification containing the title
+// and description, as htmx does not swap the content of error responses into forms with hx-swap="none". Clients that
+// accept HTML are shown a full error page. Other clients, such as those of the API, receive a JSON error.
+func Error(status int, title, description string) http.HandlerFunc {
+	page := &ErrorPage{
+		status:      status,
+		title:       title,
+		description: description,
+	}
+	return func(res http.ResponseWriter, req *http.Request) {
+		if !htmx.IsHTMX(req) && !acceptsHTML(req) {
+			APIError(res, req, status, &models.APIError{
+				Code:    strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_"),
+				Message: title + " " + description,
+			})
+			return
+		}
+		RenderPage(page).ServeHTTP(res, req)
+	}
+}
+
+func (p *ErrorPage) FullResponse(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	templ.Handler(
+		templates.Page(templates.Error(p.title, p.description), templates.WithPageTitle(http.StatusText(p.status))),
+		templ.WithStatus(p.status),
+	).ServeHTTP(w, r)
+}
+
+func (p *ErrorPage) PartialResponse(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	NewNotification(&templates.Notification{
+		Title:       p.title,
+		Description: &p.description,
+		Status:      p.status,
+	}, 0).PartialResponse(w, r)
+}
+
+// acceptsHTML reports whether the client accepts an HTML response, as browsers do.
+func acceptsHTML(req *http.Request) bool {
+	return strings.Contains(req.Header.Get("Accept"), "text/html")
+}
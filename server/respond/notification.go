@@ -0,0 +1,37 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package respond
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/a-h/templ"
+
+	"github.com/immanent-tech/www-immanent-tech/web/templates"
+)
+
+// Notification contains a message that will be displayed to the user as a notification.
+type Notification struct {
+	notification *templates.Notification
+	timeout      time.Duration
+}
+
+// NewNotification creates a Notification displaying the given message. The notification is dismissed after the
+// timeout, or the default timeout if it is 0.
+func NewNotification(notification *templates.Notification, timeout time.Duration) *Notification {
+	return &Notification{
+		notification: notification,
+		timeout:      timeout,
+	}
+}
+
+// PartialResponse renders the notification into the notification container on the page as an OOB response. The
+// response status will match the status of the notification.
+func (n *Notification) PartialResponse(res http.ResponseWriter, req *http.Request) {
+	templ.Handler(
+		templates.ShowNotification(n.notification, templates.WithNotificationTimeout(n.timeout)),
+		templ.WithStatus(n.notification.Status),
+	).ServeHTTP(res, req)
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+// Package respond contains the responses shared by the handlers and middlewares of the server, such as rendering
+// pages for htmx and non-htmx requests, notifications and errors.
+package respond
+
+import (
+	"net/http"
+
+	"github.com/angelofallars/htmx-go"
+)
+
+// PartialResponseHandler is content that can be rendered as a partial response to a htmx request.
+type PartialResponseHandler interface {
+	PartialResponse(w http.ResponseWriter, r *http.Request)
+}
+
+// FullResponseHandler is content that can be rendered as either a full page or a partial response.
+type FullResponseHandler interface {
+	PartialResponseHandler
+	FullResponse(w http.ResponseWriter, r *http.Request)
+}
+
+// RenderPage renders the content as a full page for non-htmx and history restore requests, and as a partial response
+// for other htmx requests.
+func RenderPage(content FullResponseHandler) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		if content == nil {
+			// If there is no response, return 204: No Content.
+			res.WriteHeader(http.StatusNoContent)
+			return
+		}
+		switch {
+		case !htmx.IsHTMX(req) || htmx.IsHistoryRestoreRequest(req): // Non-HTMX or HistoryRestoreRequests render a full-page.
+			if htmx.IsHistoryRestoreRequest(req) {
+				res.Header().Set("Cache-Control", "private, max-age=0, must-revalidate")
+			}
+			content.FullResponse(res, req)
+		default: // HTMX request renders partial content.
+			content.PartialResponse(res, req)
+		}
+	}
+}
+
+// RenderPartial renders the content as a partial response. Non-htmx requests receive a 406: Not Acceptable response.
+func RenderPartial(content PartialResponseHandler) http.HandlerFunc {
+	return func(res http.ResponseWriter, req *http.Request) {
+		if content == nil {
+			// If there is no response, return 204: No Content.
+			res.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if !htmx.IsHTMX(req) {
+			// If the request is not a HTMX request, return 406: Not Acceptable.
+			res.WriteHeader(http.StatusNotAcceptable)
+			return
+		}
+
+		content.PartialResponse(res, req)
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package respond_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/immanent-tech/www-immanent-tech/models"
+	"github.com/immanent-tech/www-immanent-tech/server/respond"
+	"github.com/immanent-tech/www-immanent-tech/web/templates"
+)
+
+// content records which response was rendered.
+type content struct {
+	rendered string
+}
+
+func (c *content) FullResponse(_ http.ResponseWriter, _ *http.Request) {
+	c.rendered = "full"
+}
+
+func (c *content) PartialResponse(_ http.ResponseWriter, _ *http.Request) {
+	c.rendered = "partial"
+}
+
+func TestRenderPage(t *testing.T) {
+	tests := []struct {
+		name             string
+		headers          map[string]string
+		wantRendered     string
+		wantCacheControl string
+	}{
+		{
+			name:         "browser",
+			wantRendered: "full",
+		},
+		{
+			name:         "htmx",
+			headers:      map[string]string{"HX-Request": "true"},
+			wantRendered: "partial",
+		},
+		{
+			name:             "history restore",
+			headers:          map[string]string{"HX-Request": "true", "HX-History-Restore-Request": "true"},
+			wantRendered:     "full",
+			wantCacheControl: "private, max-age=0, must-revalidate",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			res := httptest.NewRecorder()
+			page := &content{}
+			respond.RenderPage(page).ServeHTTP(res, req)
+
+			if page.rendered != tt.wantRendered {
+				t.Errorf("rendered %s response, want %s", page.rendered, tt.wantRendered)
+			}
+			if got := res.Header().Get("Cache-Control"); got != tt.wantCacheControl {
+				t.Errorf("Cache-Control = %q, want %q", got, tt.wantCacheControl)
+			}
+		})
+	}
+}
+
+func TestRenderPageNoContent(t *testing.T) {
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+	res := httptest.NewRecorder()
+	respond.RenderPage(nil).ServeHTTP(res, req)
+
+	if res.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want %d", res.Code, http.StatusNoContent)
+	}
+}
+
+func TestRenderPartial(t *testing.T) {
+	tests := []struct {
+		name         string
+		htmx         bool
+		wantStatus   int
+		wantRendered string
+	}{
+		{
+			name:         "htmx",
+			htmx:         true,
+			wantStatus:   http.StatusOK,
+			wantRendered: "partial",
+		},
+		{
+			name:       "not htmx",
+			wantStatus: http.StatusNotAcceptable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil)
+			if tt.htmx {
+				req.Header.Set("HX-Request", "true")
+			}
+			res := httptest.NewRecorder()
+			partial := &content{}
+			respond.RenderPartial(partial).ServeHTTP(res, req)
+
+			if res.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", res.Code, tt.wantStatus)
+			}
+			if partial.rendered != tt.wantRendered {
+				t.Errorf("rendered %q response, want %q", partial.rendered, tt.wantRendered)
+			}
+		})
+	}
+}
+
+func TestNotification(t *testing.T) {
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/", nil)
+	res := httptest.NewRecorder()
+	respond.NewNotification(&templates.Notification{
+		Title:  "Unable to verify your submission.",
+		Status: http.StatusForbidden,
+	}, 0).PartialResponse(res, req)
+
+	if res.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", res.Code, http.StatusForbidden)
+	}
+	body := res.Body.String()
+	if !strings.Contains(body, `hx-swap-oob="beforeend:#notifications"`) {
+		t.Errorf("notification is not swapped out of band: %s", body)
+	}
+	if !strings.Contains(body, "Unable to verify your submission.") {
+		t.Errorf("notification does not contain the title: %s", body)
+	}
+}
+
+func TestError(t *testing.T) {
+	tests := []struct {
+		name        string
+		headers     map[string]string
+		wantType    string
+		wantContain string
+		wantPage    bool
+	}{
+		{
+			name:        "htmx",
+			headers:     map[string]string{"HX-Request": "true", "Accept": "text/html"},
+			wantType:    "text/html",
+			wantContain: `hx-swap-oob="beforeend:#notifications"`,
+		},
+		{
+			name:        "browser",
+			headers:     map[string]string{"Accept": "text/html,application/xhtml+xml"},
+			wantType:    "text/html",
+			wantContain: "Request blocked.",
+			wantPage:    true,
+		},
+		{
+			name:     "api",
+			headers:  map[string]string{"Accept": "application/json"},
+			wantType: "application/json",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/contact", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			res := httptest.NewRecorder()
+			respond.Error(http.StatusForbidden, "Request blocked.", "Please reload the page.").ServeHTTP(res, req)
+
+			if res.Code != http.StatusForbidden {
+				t.Errorf("status = %d, want %d", res.Code, http.StatusForbidden)
+			}
+			if got := res.Header().Get("Content-Type"); !strings.HasPrefix(got, tt.wantType) {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantType)
+			}
+			body := res.Body.String()
+			if !strings.Contains(body, tt.wantContain) {
+				t.Errorf("body does not contain %q: %s", tt.wantContain, body)
+			}
+			if isPage := strings.Contains(body, "<html"); isPage != tt.wantPage {
+				t.Errorf("rendered full page = %t, want %t", isPage, tt.wantPage)
+			}
+		})
+	}
+}
+
+func TestErrorAPI(t *testing.T) {
+	req := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/api/v1/contact", nil)
+	res := httptest.NewRecorder()
+	respond.Error(http.StatusTooManyRequests, "Too many requests.", "Please try again later.").ServeHTTP(res, req)
+
+	var got models.APIErrorResponse
+	if err := json.NewDecoder(res.Body).Decode(&got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if got.Error == nil {
+		t.Fatal("response has no error")
+	}
+	if got.Error.Code != "too_many_requests" {
+		t.Errorf("code = %q, want %q", got.Error.Code, "too_many_requests")
+	}
+	if got.Error.Message != "Too many requests. Please try again later." {
+		t.Errorf("message = %q", got.Error.Message)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"log/slog"
 	"net"
 	"net/http"
+	"net/mail"
 	"os/signal"
 	"strconv"
 	"syscall"
@@ -18,10 +19,19 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	slogctx "github.com/veqryn/slog-context"
 
+	"github.com/immanent-tech/www-immanent-tech/providers/turnstile"
+	"github.com/immanent-tech/www-immanent-tech/providers/webhook"
+	"github.com/immanent-tech/www-immanent-tech/server/attachments"
+	"github.com/immanent-tech/www-immanent-tech/server/categories"
+	"github.com/immanent-tech/www-immanent-tech/server/forms"
 	"github.com/immanent-tech/www-immanent-tech/server/handlers"
 	"github.com/immanent-tech/www-immanent-tech/server/middlewares"
+	"github.com/immanent-tech/www-immanent-tech/server/outbox"
+	"github.com/immanent-tech/www-immanent-tech/server/spam"
+	"github.com/immanent-tech/www-immanent-tech/server/submissions"
 	"github.com/immanent-tech/www-immanent-tech/web"
 
+	"github.com/immanent-tech/go-base/config"
 	"github.com/immanent-tech/go-base/server/middlewares/etag"
 	"github.com/immanent-tech/go-base/server/middlewares/security"
 )
@@ -42,8 +52,142 @@ func Start(logger *slog.Logger) error {
 		return fmt.Errorf("unable to load server config: %w", err)
 	}
 
+	// Set up the mailer.
+	provider, err := newMailer(cfg.Mailer)
+	if err != nil {
+		return fmt.Errorf("unable to set up mailer: %w", err)
+	}
+	logger.Info("Using mail provider.",
+		slog.String("provider", cfg.Mailer),
+	)
+	// Set up the key used to sign values sent to clients.
+	key, err := signingKey(logger)
+	if err != nil {
+		return fmt.Errorf("unable to set up signing key: %w", err)
+	}
+	// Set up the store of contact form submissions.
+	store, err := submissions.New(key)
+	if err != nil {
+		return fmt.Errorf("unable to open submissions store: %w", err)
+	}
+	// Set up the outbox, through which all mail is queued for delivery.
+	box, err := outbox.New(provider, outbox.WithDeliveryHook(trackDelivery(store)))
+	if err != nil {
+		return fmt.Errorf("unable to open outbox: %w", err)
+	}
+	box.Start(ctx)
+
+	// Load the enquiry categories of the contact form.
+	contactCategories, err := categories.Load(cfg.Categories)
+	if err != nil {
+		return fmt.Errorf("unable to load contact categories: %w", err)
+	}
+	// Set up the limits on files attached to the contact form.
+	attachmentPolicy, err := attachments.New()
+	if err != nil {
+		return fmt.Errorf("unable to set up attachments: %w", err)
+	}
+	// Set up webhook notification of form submissions.
+	notifier, err := webhook.New()
+	if err != nil {
+		return fmt.Errorf("unable to set up webhooks: %w", err)
+	}
+
+	// Set up spam filtering of form submissions.
+	filter, err := spam.New(key)
+	if err != nil {
+		return fmt.Errorf("unable to set up spam filter: %w", err)
+	}
+	contactOptions := []handlers.ContactOption{
+		handlers.WithSpamFilter(filter, box),
+		handlers.WithSubmissionStore(store),
+		handlers.WithWebhooks(notifier),
+		handlers.WithAttachments(attachmentPolicy),
+	}
+	// Set up auto-replies to contact form submissions, if an address to send them from has been configured.
+	if cfg.AutoReply != "" {
+		from, err := mail.ParseAddress(cfg.AutoReply)
+		if err != nil {
+			return fmt.Errorf("unable to parse auto-reply address: %w", err)
+		}
+		contactOptions = append(contactOptions, handlers.WithAutoReply(from))
+	}
+	// Set up turnstile verification of form submissions in production, where the turnstile widget is shown.
+	if config.IsProduction() {
+		verifier, err := turnstile.New()
+		if err != nil {
+			return fmt.Errorf("unable to set up turnstile verification: %w", err)
+		}
+		contactOptions = append(contactOptions, handlers.WithTurnstile(verifier))
+	}
+
+	// Set up API keys for API clients.
+	apiKeys, err := middlewares.ParseAPIKeys(cfg.APIKeys)
+	if err != nil {
+		return fmt.Errorf("unable to parse api keys: %w", err)
+	}
+	// Set up API keys for administrators.
+	adminKeys, err := middlewares.ParseAPIKeys(cfg.AdminKeys)
+	if err != nil {
+		return fmt.Errorf("unable to parse admin keys: %w", err)
+	}
+
+	// Set up CSRF protection of forms.
+	csrf := middlewares.NewCSRF(key, middlewares.DefaultCSRFTokenTTL)
+	// Set up idempotency of submissions, so that repeated submissions are only processed once.
+	idempotency := forms.NewIdempotencyStore(forms.DefaultIdempotencyTTL)
+
+	// Set up resolving the IP address of clients, for rate limiting, logging and spam checks.
+	clientIPResolver, err := cfg.clientIPResolver()
+	if err != nil {
+		return fmt.Errorf("unable to set up client ip resolution: %w", err)
+	}
+	// Set up rate limiting of requests from each client.
+	rateLimiter := middlewares.NewRateLimiter(splitList(cfg.RateLimitExempt))
+	pagesLimit := rateLimiter.Limit("pages", rateLimit(cfg.RateLimitPages, cfg.RateLimitPagesBurst))
+
 	// Set up routes.
-	// rateLimiter := middlewares.NewRateLimiter()
+
+	// Build the manifest of fingerprinted asset URLs.
+	if err := web.LoadAssets(); err != nil {
+		return fmt.Errorf("unable to load assets: %w", err)
+	}
+
+	// Pre-render the pages that do not depend on request data.
+	landingPage, err := handlers.NewLandingPage(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to render landing page: %w", err)
+	}
+	workPage, err := handlers.NewWorkPage(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to render work page: %w", err)
+	}
+
+	// Compress the static content.
+	staticContent, err := handlers.StaticFileHandler(web.StaticContentFS)
+	if err != nil {
+		return fmt.Errorf("unable to load static content: %w", err)
+	}
+
+	// Register the public pages and generate the sitemap of them.
+	pages, err := handlers.NewPageRegistry(
+		handlers.LandingPageInfo,
+		handlers.WorkPageInfo,
+		handlers.ContactPageInfo,
+	)
+	if err != nil {
+		return fmt.Errorf("unable to register pages: %w", err)
+	}
+	sitemap, err := handlers.SitemapHandler(pages)
+	if err != nil {
+		return fmt.Errorf("unable to generate sitemap: %w", err)
+	}
+
+	// Set up the content security policy, with a nonce for each request.
+	csp, err := middlewares.NewContentSecurityPolicy()
+	if err != nil {
+		return fmt.Errorf("unable to set up content security policy: %w", err)
+	}
 
 	// Set up a new chi router.
 	router := chi.NewRouter()
@@ -55,36 +199,82 @@ func Start(logger *slog.Logger) error {
 	router.Use(
 		middleware.RequestID,
 		middlewares.Logger,
+		clientIPResolver.Middleware,
 		middleware.Recoverer,
 		security.SetupCORS,
-		security.ContentSecurityPolicy,
+		csp.Middleware,
 		security.GeneralSecurity,
 		security.CrossOriginProtection,
 		security.GeneralSecurity,
 		security.PreventCSRF,
 		middleware.Compress(defaultCompressionLevel, compressMimetypes...),
 		middleware.StripSlashes,
-		etag.Etag,
 		middlewares.SetupHTMX,
 	)
 
 	// Error handling.
 	router.NotFound(handlers.NotFound())
-	// Static content.
-	router.Handle("/content/*", handlers.StaticFileHandler(http.FS(web.StaticContentFS)))
-	router.Handle("/robots.txt", handlers.RobotsHandler())
+	// Static content, which is served from memory and requested many times by each page, so is not rate limited.
+	router.Get("/content/*", staticContent)
+	router.Head("/content/*", staticContent)
+	router.With(etag.Etag).Handle("/robots.txt", handlers.RobotsHandler())
+	// Image variants, which are limited like pages as resizing an image is expensive.
+	router.Group(func(r chi.Router) {
+		r.Use(
+			pagesLimit,
+		)
+		images := handlers.ImageHandler()
+		r.Get("/images/*", images)
+		r.Head("/images/*", images)
+	})
+	router.With(pagesLimit).Get(handlers.SitemapPath, sitemap)
+	router.With(pagesLimit).Get(handlers.SitemapPartPattern, sitemap)
+
+	// Pre-rendered pages, which are served with their own ETags and compression.
+	router.Group(func(r chi.Router) {
+		r.Use(
+			pagesLimit,
+		)
+		r.Get("/", landingPage)
+		r.Get("/work", workPage)
+	})
 
 	// Public facing routes.
 	router.Group(func(r chi.Router) {
 		r.Use(
+			pagesLimit,
+			csrf.Middleware,
 			etag.Etag,
 		)
-		r.Get("/", handlers.NewLandingPage())
-		r.Get("/work", handlers.NewWorkPage())
-		r.Get("/contact", handlers.Contact())
-		r.Post("/contact", handlers.HandleSubmitContact())
+		r.Get("/contact", handlers.Contact(filter, contactCategories, attachmentPolicy))
 	})
 
+	// Contact form submissions, which have a stricter limit than pages.
+	router.With(
+		rateLimiter.Limit("contact", rateLimit(cfg.RateLimitContact, cfg.RateLimitContactBurst)),
+		csrf.Middleware,
+		idempotency.Middleware,
+	).Post("/contact", handlers.HandleSubmitContact(box, contactCategories, contactOptions...))
+
+	// API routes, for submissions from our apps and exports for administrators.
+	router.Route("/api/v1", func(r chi.Router) {
+		r.Use(
+			rateLimiter.Limit("api", rateLimit(cfg.RateLimitAPI, cfg.RateLimitAPIBurst)),
+		)
+		r.With(
+			middlewares.RequireAPIKey(apiKeys),
+			idempotency.Middleware,
+		).Post("/contact", handlers.HandleAPISubmitContact(box, contactCategories, contactOptions...))
+		r.With(
+			middlewares.RequireAPIKey(adminKeys),
+		).Get("/submissions", handlers.HandleExportSubmissions(store))
+	})
+
+	// Ensure the sitemap only lists pages that are served.
+	if err := pages.Verify(router); err != nil {
+		return fmt.Errorf("unable to verify pages: %w", err)
+	}
+
 	svr := &http.Server{
 		Protocols:         new(http.Protocols),
 		Handler:           router,
@@ -143,6 +333,24 @@ func Start(logger *slog.Logger) error {
 		)
 	}
 
+	// Wait for any webhook notifications still being sent.
+	if err := notifier.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Webhooks failed to shutdown gracefully.",
+			slog.Any("error", err),
+		)
+	}
+	// Drain the outbox of any mail that can be delivered before shutdown.
+	if err := box.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Outbox failed to shutdown gracefully.",
+			slog.Any("error", err),
+		)
+	}
+	if err := store.Close(); err != nil {
+		logger.Error("Submissions store failed to close.",
+			slog.Any("error", err),
+		)
+	}
+
 	logger.Info("Server shutdown gracefully",
 		slog.Time("stop_time", time.Now()),
 	)
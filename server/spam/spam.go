@@ -0,0 +1,263 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+// Package spam provides lightweight heuristics for detecting spam form submissions, complementing any CAPTCHA. A
+// submission is scored on a number of signals (a honeypot field, a signed render timestamp, links and keywords in the
+// content and how recently the sender last submitted) and is considered spam if the score reaches a threshold.
+package spam
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/immanent-tech/go-base/config"
+	"github.com/immanent-tech/go-base/validation"
+
+	"github.com/immanent-tech/www-immanent-tech/providers/mailer"
+)
+
+const (
+	configPrefix = "SPAM_"
+)
+
+const (
+	// ReasonHoneypot indicates the honeypot field was filled in.
+	ReasonHoneypot = "honeypot"
+	// ReasonInvalidStamp indicates the render timestamp was missing or its signature was invalid.
+	ReasonInvalidStamp = "invalid-stamp"
+	// ReasonTooFast indicates the form was submitted too soon after it was rendered.
+	ReasonTooFast = "too-fast"
+	// ReasonTooStale indicates the form was submitted too long after it was rendered.
+	ReasonTooStale = "too-stale"
+	// ReasonLinks indicates the content contained too many links.
+	ReasonLinks = "links"
+	// ReasonKeywords indicates the content contained spam keywords.
+	ReasonKeywords = "keywords"
+	// ReasonCooldown indicates the sender submitted again before the cooldown expired.
+	ReasonCooldown = "cooldown"
+)
+
+// linkPattern matches things that look like links in submitted text.
+var linkPattern = regexp.MustCompile(`(?i)(https?://|www\.|\[url)`)
+
+// Config contains the spam filter configuration options.
+type Config struct {
+	// MinAge is the minimum time between rendering and submitting a form. Humans take a while to fill out forms.
+	MinAge config.Duration `koanf:"minage" validate:"omitempty"`
+	// MaxAge is the maximum time between rendering and submitting a form.
+	MaxAge config.Duration `koanf:"maxage" validate:"omitempty"`
+	// Cooldown is the minimum time between submissions from the same sender.
+	Cooldown config.Duration `koanf:"cooldown" validate:"omitempty"`
+	// MaxLinks is the number of links allowed in the content before it is scored as spam.
+	MaxLinks int `koanf:"maxlinks" validate:"min=0"`
+	// Keywords is a comma-separated list of keywords that are scored as spam.
+	Keywords string `koanf:"keywords"`
+	// Threshold is the score at which a submission is considered spam.
+	Threshold int `koanf:"threshold" validate:"required,min=1"`
+}
+
+var cfg = Config{
+	MinAge:    config.NewDuration(3 * time.Second),
+	MaxAge:    config.NewDuration(2 * time.Hour),
+	Cooldown:  config.NewDuration(1 * time.Minute),
+	MaxLinks:  2,
+	Keywords:  "backlinks,bitcoin,casino,crypto investment,forex,guest post,seo services,viagra",
+	Threshold: 5,
+}
+
+// loadConfig loads the spam filter configuration and ensures this is only done
+// one time, no matter how many times it is called.
+var loadConfig = sync.OnceValue(func() error {
+	if err := config.Load(configPrefix, &cfg); err != nil {
+		return fmt.Errorf("load config from environment: %w", err)
+	}
+	if err := validation.Validate.Struct(cfg); err != nil {
+		return fmt.Errorf("validate config: %w", err)
+	}
+	return nil
+})
+
+// Quarantine stores messages that have been identified as spam, rather than delivering them.
+type Quarantine interface {
+	Quarantine(ctx context.Context, msg *mailer.Message, verdict *Verdict) error
+}
+
+// Submission contains the parts of a form submission that are checked for spam.
+type Submission struct {
+	// Honeypot is the value of a hidden field that should be left empty by humans.
+	Honeypot string
+	// Stamp is the signed render timestamp generated by Filter.Stamp.
+	Stamp string
+	// Sender identifies who made the submission, typically their email address.
+	Sender string
+	// Content is the free text of the submission.
+	Content string
+}
+
+// Verdict is the result of checking a submission for spam.
+type Verdict struct {
+	Spam    bool     `json:"spam"`
+	Score   int      `json:"score"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// Clean reports whether the submission was checked and showed no signs of spam at all. A nil verdict, for a submission
+// that was not checked, is not clean.
+func (v *Verdict) Clean() bool {
+	return v != nil && v.Score == 0
+}
+
+func (v *Verdict) add(score int, reason string) {
+	v.Score += score
+	v.Reasons = append(v.Reasons, reason)
+}
+
+// Filter checks form submissions for spam.
+type Filter struct {
+	cfg      Config
+	key      []byte
+	keywords []string
+	mu       sync.Mutex
+	lastSeen map[string]time.Time
+}
+
+// New creates a new spam Filter, which signs render timestamps with the given key. The filter config will be loaded
+// from the environment.
+func New(key []byte) (*Filter, error) {
+	if err := loadConfig(); err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	return NewWithConfig(key, cfg)
+}
+
+// NewWithConfig creates a new spam Filter with the given config, which signs render timestamps with the given key.
+func NewWithConfig(key []byte, filterCfg Config) (*Filter, error) {
+	if err := validation.Validate.Struct(filterCfg); err != nil {
+		return nil, fmt.Errorf("validate config: %w", err)
+	}
+	filter := &Filter{
+		cfg:      filterCfg,
+		key:      key,
+		lastSeen: make(map[string]time.Time),
+	}
+	for keyword := range strings.SplitSeq(filterCfg.Keywords, ",") {
+		if keyword = strings.ToLower(strings.TrimSpace(keyword)); keyword != "" {
+			filter.keywords = append(filter.keywords, keyword)
+		}
+	}
+	return filter, nil
+}
+
+// Stamp returns a signed timestamp for the given render time, to be included in a hidden form field and returned with
+// the submission.
+func (f *Filter) Stamp(now time.Time) string {
+	payload := strconv.FormatInt(now.Unix(), 10)
+	return payload + "." + f.sign(payload)
+}
+
+// Check scores the submission for spam. Submissions from a sender that has been recorded within the cooldown period
+// are scored as spam.
+func (f *Filter) Check(submission *Submission) *Verdict {
+	now := time.Now()
+	verdict := &Verdict{}
+
+	// Bots tend to fill in every field.
+	if submission.Honeypot != "" {
+		verdict.add(f.cfg.Threshold, ReasonHoneypot)
+	}
+
+	// Check the time between render and submission.
+	if rendered, ok := f.verifyStamp(submission.Stamp); !ok {
+		verdict.add(f.cfg.Threshold, ReasonInvalidStamp)
+	} else {
+		switch age := now.Sub(rendered); {
+		case age < f.cfg.MinAge.Duration:
+			verdict.add(f.cfg.Threshold, ReasonTooFast)
+		case f.cfg.MaxAge.Duration > 0 && age > f.cfg.MaxAge.Duration:
+			verdict.add(f.cfg.Threshold, ReasonTooStale)
+		}
+	}
+
+	// Score links and keywords in the content.
+	content := strings.ToLower(submission.Content)
+	if links := len(linkPattern.FindAllStringIndex(content, -1)); links > f.cfg.MaxLinks {
+		verdict.add(2*(links-f.cfg.MaxLinks), ReasonLinks)
+	}
+	var keywords int
+	for _, keyword := range f.keywords {
+		if strings.Contains(content, keyword) {
+			keywords++
+		}
+	}
+	if keywords > 0 {
+		verdict.add(2*keywords, ReasonKeywords)
+	}
+
+	// Check for repeated submissions from the sender.
+	if f.seenRecently(strings.ToLower(submission.Sender), now) {
+		verdict.add(f.cfg.Threshold, ReasonCooldown)
+	}
+
+	verdict.Spam = verdict.Score >= f.cfg.Threshold
+	return verdict
+}
+
+// Record records a submission from the sender, so that another submission from them within the cooldown period is
+// detected. It should be called once a submission has been accepted, rather than when it is checked, so that a sender
+// correcting an invalid submission is not penalised.
+func (f *Filter) Record(sender string) {
+	sender = strings.ToLower(sender)
+	if sender == "" || f.cfg.Cooldown.Duration <= 0 {
+		return
+	}
+	now := time.Now()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Prune expired senders.
+	for key, seen := range f.lastSeen {
+		if now.Sub(seen) >= f.cfg.Cooldown.Duration {
+			delete(f.lastSeen, key)
+		}
+	}
+	f.lastSeen[sender] = now
+}
+
+// seenRecently reports whether the sender had a submission recorded within the cooldown period before the given time.
+func (f *Filter) seenRecently(sender string, now time.Time) bool {
+	if sender == "" || f.cfg.Cooldown.Duration <= 0 {
+		return false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	seen, found := f.lastSeen[sender]
+	return found && now.Sub(seen) < f.cfg.Cooldown.Duration
+}
+
+// verifyStamp checks the signature of the stamp and returns the render time it contains.
+func (f *Filter) verifyStamp(stamp string) (time.Time, bool) {
+	payload, signature, found := strings.Cut(stamp, ".")
+	if !found || !hmac.Equal([]byte(signature), []byte(f.sign(payload))) {
+		return time.Time{}, false
+	}
+	seconds, err := strconv.ParseInt(payload, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(seconds, 0), true
+}
+
+func (f *Filter) sign(payload string) string {
+	mac := hmac.New(sha256.New, f.key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
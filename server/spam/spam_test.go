@@ -0,0 +1,220 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package spam_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/immanent-tech/go-base/config"
+
+	"github.com/immanent-tech/www-immanent-tech/server/spam"
+)
+
+var testKey = []byte("test-key")
+
+func newTestFilter(t *testing.T) *spam.Filter {
+	t.Helper()
+	filter, err := spam.NewWithConfig(testKey, spam.Config{
+		MinAge:    config.NewDuration(3 * time.Second),
+		MaxAge:    config.NewDuration(2 * time.Hour),
+		Cooldown:  config.NewDuration(time.Minute),
+		MaxLinks:  2,
+		Keywords:  "casino, Viagra,",
+		Threshold: 5,
+	})
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+	return filter
+}
+
+func TestCheck(t *testing.T) {
+	filter := newTestFilter(t)
+	validStamp := filter.Stamp(time.Now().Add(-time.Minute))
+	otherFilter, err := spam.NewWithConfig([]byte("other-key"), spam.Config{Threshold: 5})
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		submission  *spam.Submission
+		wantSpam    bool
+		wantScore   int
+		wantReasons []string
+	}{
+		{
+			name:       "clean",
+			submission: &spam.Submission{Stamp: validStamp, Content: "I would like to know more."},
+		},
+		{
+			name:        "honeypot filled in",
+			submission:  &spam.Submission{Honeypot: "https://example.com", Stamp: validStamp},
+			wantSpam:    true,
+			wantScore:   5,
+			wantReasons: []string{spam.ReasonHoneypot},
+		},
+		{
+			name:        "missing stamp",
+			submission:  &spam.Submission{},
+			wantSpam:    true,
+			wantScore:   5,
+			wantReasons: []string{spam.ReasonInvalidStamp},
+		},
+		{
+			name:        "tampered stamp",
+			submission:  &spam.Submission{Stamp: strings.Replace(validStamp, ".", "0.", 1)},
+			wantSpam:    true,
+			wantScore:   5,
+			wantReasons: []string{spam.ReasonInvalidStamp},
+		},
+		{
+			name:        "stamp signed with another key",
+			submission:  &spam.Submission{Stamp: otherFilter.Stamp(time.Now().Add(-time.Minute))},
+			wantSpam:    true,
+			wantScore:   5,
+			wantReasons: []string{spam.ReasonInvalidStamp},
+		},
+		{
+			name:        "submitted too fast",
+			submission:  &spam.Submission{Stamp: filter.Stamp(time.Now())},
+			wantSpam:    true,
+			wantScore:   5,
+			wantReasons: []string{spam.ReasonTooFast},
+		},
+		{
+			name:       "submitted just after the minimum age",
+			submission: &spam.Submission{Stamp: filter.Stamp(time.Now().Add(-5 * time.Second))},
+		},
+		{
+			name:       "submitted just before the maximum age",
+			submission: &spam.Submission{Stamp: filter.Stamp(time.Now().Add(-2*time.Hour + time.Minute))},
+		},
+		{
+			name:        "submitted too late",
+			submission:  &spam.Submission{Stamp: filter.Stamp(time.Now().Add(-3 * time.Hour))},
+			wantSpam:    true,
+			wantScore:   5,
+			wantReasons: []string{spam.ReasonTooStale},
+		},
+		{
+			name: "links within the limit",
+			submission: &spam.Submission{
+				Stamp:   validStamp,
+				Content: "See https://example.com and www.example.org.",
+			},
+		},
+		{
+			name: "one link over the limit",
+			submission: &spam.Submission{
+				Stamp:   validStamp,
+				Content: "https://a.example http://b.example www.c.example",
+			},
+			wantScore:   2,
+			wantReasons: []string{spam.ReasonLinks},
+		},
+		{
+			name: "links over the threshold",
+			submission: &spam.Submission{
+				Stamp:   validStamp,
+				Content: "https://a.example https://b.example https://c.example https://d.example [URL=e]",
+			},
+			wantSpam:    true,
+			wantScore:   6,
+			wantReasons: []string{spam.ReasonLinks},
+		},
+		{
+			name:        "keyword",
+			submission:  &spam.Submission{Stamp: validStamp, Content: "Best CASINO offers."},
+			wantScore:   2,
+			wantReasons: []string{spam.ReasonKeywords},
+		},
+		{
+			name: "keywords and links combined",
+			submission: &spam.Submission{
+				Stamp:   validStamp,
+				Content: "casino viagra https://a.example https://b.example https://c.example",
+			},
+			wantSpam:    true,
+			wantScore:   6,
+			wantReasons: []string{spam.ReasonLinks, spam.ReasonKeywords},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict := filter.Check(tt.submission)
+			if verdict.Spam != tt.wantSpam || verdict.Score != tt.wantScore ||
+				!slices.Equal(verdict.Reasons, tt.wantReasons) {
+				t.Errorf("Check() = %+v, want spam %t, score %d, reasons %v",
+					verdict, tt.wantSpam, tt.wantScore, tt.wantReasons)
+			}
+		})
+	}
+}
+
+func TestCheckCooldown(t *testing.T) {
+	filter := newTestFilter(t)
+	submission := &spam.Submission{
+		Stamp:  filter.Stamp(time.Now().Add(-time.Minute)),
+		Sender: "Visitor@example.com",
+	}
+
+	// Checking a submission does not record the sender, so an invalid submission can be corrected and resubmitted.
+	for range 2 {
+		if verdict := filter.Check(submission); verdict.Spam {
+			t.Fatalf("Check() before Record() = %+v, want not spam", verdict)
+		}
+	}
+
+	filter.Record("visitor@EXAMPLE.com")
+	verdict := filter.Check(submission)
+	if !verdict.Spam || !slices.Contains(verdict.Reasons, spam.ReasonCooldown) {
+		t.Errorf("Check() after Record() = %+v, want spam for the cooldown", verdict)
+	}
+
+	other := &spam.Submission{Stamp: submission.Stamp, Sender: "other@example.com"}
+	if verdict := filter.Check(other); verdict.Spam {
+		t.Errorf("Check() from another sender = %+v, want not spam", verdict)
+	}
+}
+
+func TestCheckCooldownExpires(t *testing.T) {
+	filter, err := spam.NewWithConfig(testKey, spam.Config{
+		Cooldown:  config.NewDuration(10 * time.Millisecond),
+		Threshold: 5,
+	})
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+	submission := &spam.Submission{Stamp: filter.Stamp(time.Now()), Sender: "visitor@example.com"}
+
+	filter.Record(submission.Sender)
+	time.Sleep(20 * time.Millisecond)
+	if verdict := filter.Check(submission); verdict.Spam {
+		t.Errorf("Check() after the cooldown = %+v, want not spam", verdict)
+	}
+}
+
+func TestVerdictClean(t *testing.T) {
+	tests := []struct {
+		name    string
+		verdict *spam.Verdict
+		want    bool
+	}{
+		{name: "not checked"},
+		{name: "no signals", verdict: &spam.Verdict{}, want: true},
+		{name: "suspicious", verdict: &spam.Verdict{Score: 2, Reasons: []string{spam.ReasonKeywords}}},
+		{name: "spam", verdict: &spam.Verdict{Spam: true, Score: 5, Reasons: []string{spam.ReasonHoneypot}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.verdict.Clean(); got != tt.want {
+				t.Errorf("Clean() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
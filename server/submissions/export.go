@@ -0,0 +1,132 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package submissions
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format is a format in which submissions can be exported.
+type Format string
+
+const (
+	// FormatCSV exports submissions as CSV, with a header row.
+	FormatCSV Format = "csv"
+	// FormatJSON exports submissions as a JSON array.
+	FormatJSON Format = "json"
+)
+
+// ErrUnknownFormat is returned when exporting submissions in a format that is not supported.
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// csvFormulaPrefixes are the characters that cause a spreadsheet to interpret a CSV value as a formula.
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// ContentType returns the media type of the format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatJSON:
+		return "application/json"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// Export writes the submissions created in the time range [from, to) to out in the given format. A zero from or to
+// leaves that end of the range unbounded.
+func (s *Store) Export(out io.Writer, format Format, from, to time.Time) error {
+	switch format {
+	case FormatCSV:
+		return s.exportCSV(out, from, to)
+	case FormatJSON:
+		return s.exportJSON(out, from, to)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+}
+
+// exportJSON writes the submissions as a JSON array.
+func (s *Store) exportJSON(out io.Writer, from, to time.Time) error {
+	list := []*Submission{}
+	if err := s.Range(from, to, func(submission *Submission) error {
+		list = append(list, submission)
+		return nil
+	}); err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(list); err != nil {
+		return fmt.Errorf("encode json: %w", err)
+	}
+	return nil
+}
+
+// exportCSV writes the submissions as CSV, with a header row. Values are escaped so that they are not interpreted as
+// formulas when the export is opened in a spreadsheet.
+func (s *Store) exportCSV(out io.Writer, from, to time.Time) error {
+	writer := csv.NewWriter(out)
+	if err := writer.Write([]string{
+		"id", "reference", "source", "client", "category", "created_at", "updated_at", "request_id", "client_ip_hash",
+		"contact_email", "delivery_status", "spam", "spam_score", "spam_reasons", "attachments", "details",
+	}); err != nil {
+		return fmt.Errorf("write csv header: %w", err)
+	}
+	if err := s.Range(from, to, func(submission *Submission) error {
+		var isSpam, score, reasons string
+		if submission.Spam != nil {
+			isSpam = strconv.FormatBool(submission.Spam.Spam)
+			score = strconv.Itoa(submission.Spam.Score)
+			reasons = strings.Join(submission.Spam.Reasons, ";")
+		}
+		record := []string{
+			submission.ID,
+			submission.Reference,
+			string(submission.Source),
+			submission.Client,
+			submission.Category,
+			submission.CreatedAt.Format(time.RFC3339),
+			submission.UpdatedAt.Format(time.RFC3339),
+			submission.RequestID,
+			submission.ClientIPHash,
+			submission.ContactEmail,
+			string(submission.Status),
+			isSpam,
+			score,
+			reasons,
+			strings.Join(submission.Attachments, ";"),
+			submission.Details,
+		}
+		for i, value := range record {
+			record[i] = escapeCSV(value)
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("write csv record: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("write csv: %w", err)
+	}
+	return nil
+}
+
+// escapeCSV prefixes a value that would be interpreted as a formula by a spreadsheet with a single quote, so that it
+// is shown as text (CSV injection).
+func escapeCSV(value string) string {
+	if value != "" && strings.ContainsRune(csvFormulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
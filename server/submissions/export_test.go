@@ -0,0 +1,111 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package submissions_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/immanent-tech/www-immanent-tech/server/submissions"
+)
+
+func TestExportCSV(t *testing.T) {
+	store, _ := newTestStore(t)
+	for _, details := range []string{
+		"=HYPERLINK(\"https://example.com\")",
+		"+1 555 0100",
+		"-2+3",
+		"@SUM(A1:A2)",
+		"\t=cmd",
+		"Plain text, with a comma.",
+	} {
+		if err := store.Add(t.Context(), &submissions.Submission{
+			ContactEmail: "=visitor@example.com",
+			Details:      details,
+		}); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := store.Export(&buf, submissions.FormatCSV, time.Time{}, time.Time{}); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	if len(records) != 7 {
+		t.Fatalf("export has %d records, want a header and 6 submissions", len(records))
+	}
+	header := records[0]
+	column := func(name string) int {
+		for i, value := range header {
+			if value == name {
+				return i
+			}
+		}
+		t.Fatalf("export has no %s column", name)
+		return -1
+	}
+	details, email := column("details"), column("contact_email")
+
+	want := []string{
+		"'=HYPERLINK(\"https://example.com\")",
+		"'+1 555 0100",
+		"'-2+3",
+		"'@SUM(A1:A2)",
+		"'\t=cmd",
+		"Plain text, with a comma.",
+	}
+	for i, record := range records[1:] {
+		if record[details] != want[i] {
+			t.Errorf("details = %q, want %q", record[details], want[i])
+		}
+		if record[email] != "'=visitor@example.com" {
+			t.Errorf("contact_email = %q, want it escaped", record[email])
+		}
+	}
+}
+
+func TestExportJSON(t *testing.T) {
+	store, _ := newTestStore(t)
+
+	var buf bytes.Buffer
+	if err := store.Export(&buf, submissions.FormatJSON, time.Time{}, time.Time{}); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if got := bytes.TrimSpace(buf.Bytes()); string(got) != "[]" {
+		t.Errorf("export of an empty store = %s, want an empty array", got)
+	}
+
+	// Values are not escaped in JSON, where they cannot be mistaken for formulas.
+	submission := &submissions.Submission{ContactEmail: "visitor@example.com", Details: "=1+1"}
+	if err := store.Add(t.Context(), submission); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	buf.Reset()
+	if err := store.Export(&buf, submissions.FormatJSON, time.Time{}, time.Time{}); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	var list []*submissions.Submission
+	if err := json.Unmarshal(buf.Bytes(), &list); err != nil {
+		t.Fatalf("decode export: %v", err)
+	}
+	if len(list) != 1 || list[0].Details != "=1+1" {
+		t.Errorf("export = %s, want the submission unescaped", buf.Bytes())
+	}
+}
+
+func TestExportUnknownFormat(t *testing.T) {
+	store, _ := newTestStore(t)
+	err := store.Export(&bytes.Buffer{}, submissions.Format("xml"), time.Time{}, time.Time{})
+	if !errors.Is(err, submissions.ErrUnknownFormat) {
+		t.Errorf("Export() error = %v, want %v", err, submissions.ErrUnknownFormat)
+	}
+}
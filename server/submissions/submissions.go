@@ -0,0 +1,311 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+// Package submissions provides an on-disk store of contact form submissions, as an audit trail that is independent of
+// email delivery. The store is local to each server instance. Where instances do not have persistent storage (such as
+// on Cloud Run), it only holds the submissions received by an instance while it is running, and the email and webhook
+// notifications sent for each submission are the lasting record.
+package submissions
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/immanent-tech/go-base/config"
+	"github.com/immanent-tech/go-base/validation"
+	slogctx "github.com/veqryn/slog-context"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/immanent-tech/www-immanent-tech/server/spam"
+)
+
+const (
+	configPrefix = "SUBMISSIONS_"
+	// HeaderID is the email header in which the submission ID is included in the notification email.
+	HeaderID = "X-Submission-Id"
+	// keyLength is the length of a submission key; a timestamp followed by a sequence number.
+	keyLength = 16
+)
+
+var submissionsBucket = []byte("submissions")
+
+var (
+	// ErrNotFound is returned when a submission does not exist.
+	ErrNotFound = errors.New("submission not found")
+	// ErrInvalidID is returned when a submission ID is malformed.
+	ErrInvalidID = errors.New("invalid submission id")
+)
+
+// Status is the delivery status of a submission.
+type Status string
+
+const (
+	// StatusReceived indicates the submission has been received but not yet handed off for delivery.
+	StatusReceived Status = "received"
+	// StatusQueued indicates the submission has been queued for delivery.
+	StatusQueued Status = "queued"
+	// StatusDelivered indicates the submission has been delivered.
+	StatusDelivered Status = "delivered"
+	// StatusFailed indicates the submission could not be queued for delivery.
+	StatusFailed Status = "failed"
+	// StatusDeadLettered indicates delivery of the submission was abandoned after too many attempts.
+	StatusDeadLettered Status = "dead-lettered"
+	// StatusQuarantined indicates the submission was suspected as spam and not delivered.
+	StatusQuarantined Status = "quarantined"
+)
+
+// final reports whether the status is the outcome of delivery, which is not replaced by later updates.
+func (s Status) final() bool {
+	return s == StatusDelivered || s == StatusDeadLettered
+}
+
+// Source is where a submission was made.
+type Source string
+
+const (
+	// SourceForm indicates the submission was made through the contact form.
+	SourceForm Source = "form"
+	// SourceAPI indicates the submission was made through the API.
+	SourceAPI Source = "api"
+)
+
+// Config contains the submission store configuration options.
+type Config struct {
+	// Path is the location of the submissions database file. It must not be shared with other server instances, as the
+	// database can only be opened by a single process. A file in the temporary directory is used if it is not set.
+	Path string `koanf:"path" validate:"omitempty"`
+}
+
+var cfg = Config{}
+
+// loadConfig loads the submission store configuration and ensures this is only done
+// one time, no matter how many times it is called.
+var loadConfig = sync.OnceValue(func() error {
+	if err := config.Load(configPrefix, &cfg); err != nil {
+		return fmt.Errorf("load config from environment: %w", err)
+	}
+	if err := validation.Validate.Struct(cfg); err != nil {
+		return fmt.Errorf("validate config: %w", err)
+	}
+	return nil
+})
+
+// Submission is a stored contact form submission.
+type Submission struct {
+	ID           string        `json:"id"`
+	Reference    string        `json:"reference"`
+	Source       Source        `json:"source,omitempty"`
+	Client       string        `json:"client,omitempty"`
+	Category     string        `json:"category,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+	RequestID    string        `json:"request_id,omitempty"`
+	ClientIPHash string        `json:"client_ip_hash,omitempty"`
+	ContactEmail string        `json:"contact_email"`
+	Details      string        `json:"details"`
+	Attachments  []string      `json:"attachments,omitempty"`
+	Status       Status        `json:"delivery_status"`
+	Spam         *spam.Verdict `json:"spam,omitempty"`
+}
+
+// Store is an on-disk store of submissions.
+type Store struct {
+	db  *bolt.DB
+	key []byte
+}
+
+// New opens (or creates) the submission store with config loaded from the environment. Client IP addresses are hashed
+// with the given key before being stored.
+func New(key []byte) (*Store, error) {
+	if err := loadConfig(); err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	return NewWithConfig(key, cfg)
+}
+
+// NewWithConfig opens (or creates) the submission store with the given config. Client IP addresses are hashed with
+// the given key before being stored.
+func NewWithConfig(key []byte, storeCfg Config) (*Store, error) {
+	if err := validation.Validate.Struct(storeCfg); err != nil {
+		return nil, fmt.Errorf("validate config: %w", err)
+	}
+	path := databasePath(storeCfg.Path)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("create submissions directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("open submissions database: %w", err)
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(submissionsBucket); err != nil {
+			return fmt.Errorf("create bucket: %w", err)
+		}
+		return nil
+	}); err != nil {
+		db.Close() //nolint:errcheck
+		return nil, fmt.Errorf("initialise submissions database: %w", err)
+	}
+	return &Store{db: db, key: key}, nil
+}
+
+// OpenReadOnly opens an existing submission store for reading. If path is empty, the path from the config loaded from
+// the environment is used. As the store can only be opened for writing by a single process, opening the store of a
+// running server may time out.
+func OpenReadOnly(path string) (*Store, error) {
+	if path == "" {
+		if err := loadConfig(); err != nil {
+			return nil, fmt.Errorf("load config: %w", err)
+		}
+		path = databasePath(cfg.Path)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("open submissions database: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// databasePath returns the path of the submissions database, which is a file in the temporary directory if no path is
+// configured.
+func databasePath(path string) string {
+	if path != "" {
+		return path
+	}
+	return filepath.Join(os.TempDir(), "www-immanent-tech", "submissions.db")
+}
+
+// Close closes the store.
+func (s *Store) Close() error {
+	if err := s.db.Close(); err != nil {
+		return fmt.Errorf("close submissions database: %w", err)
+	}
+	return nil
+}
+
+// HashClientIP returns a keyed hash of the client IP address, so that submissions from the same client can be
+// correlated without storing the address itself.
+func (s *Store) HashClientIP(ip string) string {
+	if ip == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(ip))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// Add stores a new submission, assigning its ID and creation time.
+func (s *Store) Add(ctx context.Context, submission *Submission) error {
+	now := time.Now().UTC()
+	submission.CreatedAt = now
+	submission.UpdatedAt = now
+	if submission.Status == "" {
+		submission.Status = StatusReceived
+	}
+
+	if err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(submissionsBucket)
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return fmt.Errorf("generate id: %w", err)
+		}
+		key := make([]byte, keyLength)
+		binary.BigEndian.PutUint64(key[:8], uint64(now.UnixNano()))
+		binary.BigEndian.PutUint64(key[8:], seq)
+		submission.ID = hex.EncodeToString(key)
+		return putSubmission(bucket, key, submission)
+	}); err != nil {
+		return fmt.Errorf("store submission: %w", err)
+	}
+
+	slogctx.FromCtx(ctx).Debug("Stored contact submission.",
+		slog.String("submission_id", submission.ID),
+	)
+	return nil
+}
+
+// SetStatus updates the delivery status of the submission with the given ID. The outcome of delivery is not replaced,
+// as the outbox may deliver the submission before it has been recorded as queued.
+func (s *Store) SetStatus(id string, status Status) error {
+	key, err := hex.DecodeString(id)
+	if err != nil || len(key) != keyLength {
+		return ErrInvalidID
+	}
+	if err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(submissionsBucket)
+		value := bucket.Get(key)
+		if value == nil {
+			return ErrNotFound
+		}
+		submission := &Submission{}
+		if err := json.Unmarshal(value, submission); err != nil {
+			return fmt.Errorf("decode submission: %w", err)
+		}
+		if submission.Status.final() {
+			return nil
+		}
+		submission.Status = status
+		submission.UpdatedAt = time.Now().UTC()
+		return putSubmission(bucket, key, submission)
+	}); err != nil {
+		return fmt.Errorf("update submission: %w", err)
+	}
+	return nil
+}
+
+// Range calls fn for each submission created in the time range [from, to), in order of creation. A zero from or to
+// leaves that end of the range unbounded.
+func (s *Store) Range(from, to time.Time, fn func(*Submission) error) error {
+	err := s.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(submissionsBucket).Cursor()
+
+		var key, value []byte
+		if from.IsZero() {
+			key, value = cursor.First()
+		} else {
+			seek := make([]byte, 8)
+			binary.BigEndian.PutUint64(seek, uint64(from.UnixNano()))
+			key, value = cursor.Seek(seek)
+		}
+
+		for ; key != nil; key, value = cursor.Next() {
+			if !to.IsZero() && int64(binary.BigEndian.Uint64(key[:8])) >= to.UnixNano() {
+				break
+			}
+			submission := &Submission{}
+			if err := json.Unmarshal(value, submission); err != nil {
+				return fmt.Errorf("decode submission: %w", err)
+			}
+			if err := fn(submission); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("read submissions: %w", err)
+	}
+	return nil
+}
+
+func putSubmission(bucket *bolt.Bucket, key []byte, submission *Submission) error {
+	value, err := json.Marshal(submission)
+	if err != nil {
+		return fmt.Errorf("encode submission: %w", err)
+	}
+	if err := bucket.Put(key, value); err != nil {
+		return fmt.Errorf("store submission: %w", err)
+	}
+	return nil
+}
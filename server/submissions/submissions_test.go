@@ -0,0 +1,154 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package submissions_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/immanent-tech/www-immanent-tech/server/spam"
+	"github.com/immanent-tech/www-immanent-tech/server/submissions"
+)
+
+func newTestStore(t *testing.T) (*submissions.Store, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "submissions.db")
+	store, err := submissions.NewWithConfig([]byte("test-key"), submissions.Config{Path: path})
+	if err != nil {
+		t.Fatalf("NewWithConfig() error = %v", err)
+	}
+	t.Cleanup(func() {
+		store.Close()
+	})
+	return store, path
+}
+
+func collect(t *testing.T, store *submissions.Store, from, to time.Time) []*submissions.Submission {
+	t.Helper()
+	var list []*submissions.Submission
+	if err := store.Range(from, to, func(submission *submissions.Submission) error {
+		list = append(list, submission)
+		return nil
+	}); err != nil {
+		t.Fatalf("Range() error = %v", err)
+	}
+	return list
+}
+
+func TestAddAndSetStatus(t *testing.T) {
+	store, _ := newTestStore(t)
+
+	submission := &submissions.Submission{
+		Reference:    "IT-ABCDEFGHIJ",
+		ContactEmail: "visitor@example.com",
+		Details:      "hello",
+		Spam:         &spam.Verdict{Score: 2, Reasons: []string{spam.ReasonLinks}},
+	}
+	if err := store.Add(t.Context(), submission); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if submission.ID == "" || submission.CreatedAt.IsZero() || submission.Status != submissions.StatusReceived {
+		t.Fatalf("Add() did not assign an id, creation time and status: %+v", submission)
+	}
+
+	if err := store.SetStatus(submission.ID, submissions.StatusDelivered); err != nil {
+		t.Fatalf("SetStatus() error = %v", err)
+	}
+	list := collect(t, store, time.Time{}, time.Time{})
+	if len(list) != 1 {
+		t.Fatalf("Range() returned %d submissions, want 1", len(list))
+	}
+	if got := list[0]; got.Status != submissions.StatusDelivered || got.Reference != submission.Reference ||
+		got.Spam == nil || got.Spam.Score != 2 {
+		t.Errorf("stored submission = %+v, want it delivered with its spam verdict", got)
+	}
+
+	// The outbox can deliver a submission before the handler records it as queued.
+	if err := store.SetStatus(submission.ID, submissions.StatusQueued); err != nil {
+		t.Fatalf("SetStatus() error = %v", err)
+	}
+	if got := collect(t, store, time.Time{}, time.Time{})[0]; got.Status != submissions.StatusDelivered {
+		t.Errorf("status after queueing a delivered submission = %q, want %q", got.Status, submissions.StatusDelivered)
+	}
+
+	if err := store.SetStatus("not-an-id", submissions.StatusFailed); !errors.Is(err, submissions.ErrInvalidID) {
+		t.Errorf("SetStatus() of a malformed id error = %v, want %v", err, submissions.ErrInvalidID)
+	}
+	if err := store.SetStatus("00000000000000000000000000000000", submissions.StatusFailed); !errors.Is(
+		err, submissions.ErrNotFound) {
+		t.Errorf("SetStatus() of an unknown id error = %v, want %v", err, submissions.ErrNotFound)
+	}
+}
+
+func TestRange(t *testing.T) {
+	store, _ := newTestStore(t)
+
+	var created []time.Time
+	for range 3 {
+		submission := &submissions.Submission{ContactEmail: "visitor@example.com", Details: "hello"}
+		if err := store.Add(t.Context(), submission); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+		created = append(created, submission.CreatedAt)
+		time.Sleep(time.Millisecond)
+	}
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     int
+	}{
+		{name: "unbounded", want: 3},
+		{name: "from the second", from: created[1], want: 2},
+		{name: "before the third", to: created[2], want: 2},
+		{name: "only the second", from: created[1], to: created[2], want: 1},
+		{name: "after all", from: created[2].Add(time.Second), want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := collect(t, store, tt.from, tt.to); len(got) != tt.want {
+				t.Errorf("Range() returned %d submissions, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenReadOnly(t *testing.T) {
+	store, path := newTestStore(t)
+	if err := store.Add(t.Context(), &submissions.Submission{ContactEmail: "visitor@example.com"}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	readOnly, err := submissions.OpenReadOnly(path)
+	if err != nil {
+		t.Fatalf("OpenReadOnly() error = %v", err)
+	}
+	defer readOnly.Close()
+	if got := collect(t, readOnly, time.Time{}, time.Time{}); len(got) != 1 {
+		t.Errorf("Range() returned %d submissions, want 1", len(got))
+	}
+}
+
+func TestHashClientIP(t *testing.T) {
+	store, _ := newTestStore(t)
+
+	hash := store.HashClientIP("192.0.2.1")
+	if hash == "" || hash == "192.0.2.1" || len(hash) != 32 {
+		t.Errorf("HashClientIP() = %q, want a 32 character hash", hash)
+	}
+	if store.HashClientIP("192.0.2.1") != hash {
+		t.Error("HashClientIP() is not stable")
+	}
+	if store.HashClientIP("192.0.2.2") == hash {
+		t.Error("HashClientIP() of different addresses are equal")
+	}
+	if store.HashClientIP("") != "" {
+		t.Error("HashClientIP() of an empty address is not empty")
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package web
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	// contentDir is the directory of StaticContentFS containing the static content.
+	contentDir = "content"
+	// contentPrefix is the URL path under which the static content is served.
+	contentPrefix = "/" + contentDir + "/"
+	// fingerprintLength is the number of hex characters of the content hash included in fingerprinted URLs.
+	fingerprintLength = 10
+)
+
+// cssURLPattern matches the url() references of a stylesheet, capturing the quote and the URL.
+var cssURLPattern = regexp.MustCompile(`url\(\s*(['"]?)([^'")\s]+)(['"]?)\s*\)`)
+
+// assetManifest maps static content to URLs fingerprinted with a hash of the content.
+type assetManifest struct {
+	// urls maps the name of each asset, relative to the content directory, to its fingerprinted URL.
+	urls map[string]string
+	// paths maps each fingerprinted URL to the URL of the asset without the fingerprint.
+	paths map[string]string
+	// sizes maps the name of each image, relative to the content directory, to its intrinsic size.
+	sizes map[string]image.Point
+	// encodings maps the name of each image, relative to the content directory, to how it is encoded.
+	encodings map[string]imageEncoding
+}
+
+// loadAssetManifest builds the asset manifest from the static content, ensuring this is only done one time.
+// Stylesheets are fingerprinted after all other assets, with their url() references rewritten to fingerprinted URLs
+// (see RewriteAssetURLs), so that the fingerprint of a stylesheet changes whenever any asset it references does.
+var loadAssetManifest = sync.OnceValues(func() (*assetManifest, error) {
+	manifest := &assetManifest{
+		urls:      make(map[string]string),
+		paths:     make(map[string]string),
+		sizes:     make(map[string]image.Point),
+		encodings: make(map[string]imageEncoding),
+	}
+	var stylesheets []string
+	err := fs.WalkDir(StaticContentFS, contentDir, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		if path.Ext(name) == ".css" {
+			stylesheets = append(stylesheets, name)
+			return nil
+		}
+		data, err := StaticContentFS.ReadFile(name)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", name, err)
+		}
+		asset := strings.TrimPrefix(name, contentDir+"/")
+		manifest.add(asset, data)
+
+		if ext := path.Ext(asset); slices.Contains(imageExtensions, ext) {
+			imageCfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+			if err != nil {
+				return fmt.Errorf("decode %s: %w", name, err)
+			}
+			manifest.sizes[asset] = image.Pt(imageCfg.Width, imageCfg.Height)
+			manifest.encodings[asset] = encodingOf(ext, data)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("build asset manifest: %w", err)
+	}
+	for _, name := range stylesheets {
+		data, err := StaticContentFS.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("build asset manifest: read %s: %w", name, err)
+		}
+		manifest.add(strings.TrimPrefix(name, contentDir+"/"), manifest.rewrite("/"+name, data))
+	}
+	return manifest, nil
+})
+
+// add adds the asset with the given content to the manifest, fingerprinted with a hash of the content.
+func (m *assetManifest) add(asset string, data []byte) {
+	hash := sha256.Sum256(data)
+	ext := path.Ext(asset)
+	fingerprinted := contentPrefix + strings.TrimSuffix(asset, ext) + "." +
+		hex.EncodeToString(hash[:])[:fingerprintLength] + ext
+
+	m.urls[asset] = fingerprinted
+	m.paths[fingerprinted] = contentPrefix + asset
+}
+
+// rewrite replaces the url() references of the stylesheet at the given URL path that are assets in the manifest with
+// their fingerprinted URLs. Relative references are resolved against the URL path of the stylesheet. Any query or
+// fragment of a reference is kept.
+func (m *assetManifest) rewrite(cssPath string, css []byte) []byte {
+	return cssURLPattern.ReplaceAllFunc(css, func(match []byte) []byte {
+		groups := cssURLPattern.FindSubmatch(match)
+		quote, ref := string(groups[1]), string(groups[2])
+		if quote != string(groups[3]) || strings.Contains(ref, ":") || strings.HasPrefix(ref, "//") {
+			// Mismatched quotes, data: and absolute URLs are left as they are.
+			return match
+		}
+		refPath, suffix := ref, ""
+		if i := strings.IndexAny(ref, "?#"); i >= 0 {
+			refPath, suffix = ref[:i], ref[i:]
+		}
+		if !strings.HasPrefix(refPath, "/") {
+			refPath = path.Join(path.Dir(cssPath), refPath)
+		}
+		asset, inContent := strings.CutPrefix(path.Clean(refPath), contentPrefix)
+		fingerprinted, found := m.urls[asset]
+		if !inContent || !found {
+			return match
+		}
+		return []byte("url(" + quote + fingerprinted + suffix + quote + ")")
+	})
+}
+
+// LoadAssets builds the manifest of fingerprinted asset URLs and image sizes. The manifest is built on first use, but
+// this can be called at startup so that any error is surfaced before serving requests.
+func LoadAssets() error {
+	_, err := loadAssetManifest()
+	return err
+}
+
+// AssetURL returns the URL of the named asset, relative to the content directory (e.g., "styles.css"), fingerprinted
+// with a hash of its content (e.g., "/content/styles.3f9a1c0b2d.css"). As the URL changes whenever the content does,
+// it can be cached indefinitely. If the asset is not in the manifest, its URL without a fingerprint is returned.
+func AssetURL(name string) string {
+	if manifest, err := loadAssetManifest(); err == nil {
+		if url, found := manifest.urls[name]; found {
+			return url
+		}
+	}
+	return contentPrefix + name
+}
+
+// ResolveAsset returns the URL path of the asset without a fingerprint, if the URL path is a fingerprinted asset URL.
+func ResolveAsset(urlPath string) (string, bool) {
+	manifest, err := loadAssetManifest()
+	if err != nil {
+		return "", false
+	}
+	resolved, found := manifest.paths[urlPath]
+	return resolved, found
+}
+
+// RewriteAssetURLs returns the stylesheet at the given URL path (e.g., "/content/fonts/inter/inter.css") with its
+// url() references to other static content replaced by their fingerprinted URLs, as used to fingerprint the
+// stylesheet itself. This allows the fonts and images a stylesheet references to be cached as long as it is. If the
+// manifest cannot be built, the stylesheet is returned unchanged.
+func RewriteAssetURLs(cssPath string, css []byte) []byte {
+	manifest, err := loadAssetManifest()
+	if err != nil {
+		return css
+	}
+	return manifest.rewrite(cssPath, css)
+}
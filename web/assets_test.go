@@ -0,0 +1,99 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package web_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/immanent-tech/www-immanent-tech/web"
+)
+
+func TestRewriteAssetURLs(t *testing.T) {
+	font := web.AssetURL("fonts/inter/Inter-Black.woff2")
+	logo := web.AssetURL("logo.png")
+	if font == "/content/fonts/inter/Inter-Black.woff2" || logo == "/content/logo.png" {
+		t.Fatal("assets are not fingerprinted")
+	}
+
+	tests := []struct {
+		name string
+		css  string
+		want string
+	}{
+		{
+			name: "relative",
+			css:  `src: url("Inter-Black.woff2") format("woff2");`,
+			want: `src: url("` + font + `") format("woff2");`,
+		},
+		{
+			name: "single quoted parent",
+			css:  `background: url('../../logo.png');`,
+			want: `background: url('` + logo + `');`,
+		},
+		{
+			name: "absolute unquoted",
+			css:  `background: url( /content/logo.png );`,
+			want: `background: url(` + logo + `);`,
+		},
+		{
+			name: "query and fragment kept",
+			css:  `src: url("Inter-Black.woff2?v=4#iefix");`,
+			want: `src: url("` + font + `?v=4#iefix");`,
+		},
+		{
+			name: "data url",
+			css:  `background: url("data:image/png;base64,AAAA");`,
+			want: `background: url("data:image/png;base64,AAAA");`,
+		},
+		{
+			name: "external url",
+			css:  `background: url(https://example.com/logo.png);`,
+			want: `background: url(https://example.com/logo.png);`,
+		},
+		{
+			name: "missing asset",
+			css:  `src: url("Missing.woff2");`,
+			want: `src: url("Missing.woff2");`,
+		},
+		{
+			name: "outside static content",
+			css:  `background: url(/images/logo.png);`,
+			want: `background: url(/images/logo.png);`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := string(web.RewriteAssetURLs("/content/fonts/inter/inter.css", []byte(tt.css)))
+			if got != tt.want {
+				t.Errorf("RewriteAssetURLs() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestStylesheetFingerprint checks a stylesheet is fingerprinted with the hash of its rewritten content, so that its
+// URL changes whenever an asset it references does.
+func TestStylesheetFingerprint(t *testing.T) {
+	data, err := web.StaticContentFS.ReadFile("content/fonts/inter/inter.css")
+	if err != nil {
+		t.Fatalf("read stylesheet: %v", err)
+	}
+	rewritten := web.RewriteAssetURLs("/content/fonts/inter/inter.css", data)
+	if !strings.Contains(string(rewritten), web.AssetURL("fonts/inter/InterVariable.woff2")) {
+		t.Error("stylesheet does not reference the fingerprinted fonts")
+	}
+
+	hash := sha256.Sum256(rewritten)
+	want := "/content/fonts/inter/inter." + hex.EncodeToString(hash[:])[:10] + ".css"
+	got := web.AssetURL("fonts/inter/inter.css")
+	if got != want {
+		t.Errorf("AssetURL() = %s, want %s", got, want)
+	}
+	if resolved, found := web.ResolveAsset(got); !found || resolved != "/content/fonts/inter/inter.css" {
+		t.Errorf("ResolveAsset(%s) = %s, %t", got, resolved, found)
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package web
+
+import (
+	"encoding/binary"
+	"fmt"
+	_ "image/jpeg" // Register the JPEG decoder.
+	_ "image/png"  // Register the PNG decoder.
+	"path"
+	"strconv"
+	"strings"
+
+	_ "golang.org/x/image/webp" // Register the WebP decoder.
+)
+
+// imagesPrefix is the URL path under which resized variants of images are served.
+const imagesPrefix = "/images/"
+
+// imageExtensions are the extensions of static content that are images which can be resized.
+var imageExtensions = []string{".png", ".webp", ".jpg", ".jpeg"}
+
+// imageEncoding is how an image is encoded, which determines how its variants are encoded.
+type imageEncoding int
+
+const (
+	// encodingLossless images have lossless variants.
+	encodingLossless imageEncoding = iota + 1
+	// encodingLossy images have lossy variants, as lossless variants would usually be larger than the image itself.
+	encodingLossy
+	// encodingLossyAlpha images are lossy with transparency. They are not resized, as the lossy variants have no
+	// transparency and lossless variants would usually be larger than the image itself.
+	encodingLossyAlpha
+)
+
+// imageWidths are the widths, in pixels, of the resized variants of images. Requested widths are rounded up to one of
+// these, so that only a few variants of each image are ever generated.
+var imageWidths = []int{320, 640, 960, 1280, 1920}
+
+// ImageSize returns the intrinsic width and height of the named image, relative to the content directory. If the
+// image is not in the manifest, found will be false.
+func ImageSize(name string) (width, height int, found bool) {
+	manifest, err := loadAssetManifest()
+	if err != nil {
+		return 0, 0, false
+	}
+	size, found := manifest.sizes[name]
+	return size.X, size.Y, found
+}
+
+// ImageResizable reports whether variants of the named image, relative to the content directory, are resized. All
+// images are resized, except lossy images with transparency, as lossy variants have no transparency.
+func ImageResizable(name string) bool {
+	encoding := imageEncodingOf(name)
+	return encoding == encodingLossless || encoding == encodingLossy
+}
+
+// ImageLossless reports whether the named image, relative to the content directory, is losslessly encoded, so that
+// its variants should be too. Variants of lossy images are encoded lossily, as lossless variants would usually be
+// larger than the image itself.
+func ImageLossless(name string) bool {
+	return imageEncodingOf(name) == encodingLossless
+}
+
+// imageEncodingOf returns how the named image, relative to the content directory, is encoded, or 0 if it is not in
+// the manifest.
+func imageEncodingOf(name string) imageEncoding {
+	manifest, err := loadAssetManifest()
+	if err != nil {
+		return 0
+	}
+	return manifest.encodings[name]
+}
+
+// ImageURL returns the URL of the variant of the named image, relative to the content directory, at the given width.
+// Like AssetURL, the URL is fingerprinted with a hash of the image. A width of 0 returns the URL of the full-size
+// image.
+func ImageURL(name string, width int) string {
+	url := imagesPrefix + strings.TrimPrefix(AssetURL(name), contentPrefix)
+	if width > 0 {
+		url += "?w=" + strconv.Itoa(width)
+	}
+	return url
+}
+
+// ImageSrcset returns a srcset listing the URL of each variant of the named image with its width, including the
+// full-size image. If the image is not resized (see ImageResizable), an empty srcset is returned, as every variant
+// would be the full-size image.
+func ImageSrcset(name string) string {
+	width, _, found := ImageSize(name)
+	if !found || !ImageResizable(name) {
+		return ""
+	}
+	var candidates []string
+	for _, variant := range imageWidths {
+		if variant < width {
+			candidates = append(candidates, ImageURL(name, variant)+" "+strconv.Itoa(variant)+"w")
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	candidates = append(candidates, ImageURL(name, width)+" "+strconv.Itoa(width)+"w")
+	return strings.Join(candidates, ", ")
+}
+
+// ImageVariantWidth returns the width of the variant of the named image to serve for the requested width. This is the
+// smallest variant at least as wide as requested, or the full-size image if no variant is. A requested width of 0, or
+// any width of an image that is not resized, returns the width of the full-size image. Images are never upscaled.
+func ImageVariantWidth(name string, requested int) int {
+	width, _, found := ImageSize(name)
+	if !found {
+		return 0
+	}
+	if requested <= 0 || !ImageResizable(name) {
+		return width
+	}
+	for _, variant := range imageWidths {
+		if variant >= requested && variant < width {
+			return variant
+		}
+	}
+	return width
+}
+
+// ResolveImage returns the name of the image, relative to the content directory, for the URL path of an image variant,
+// and whether the URL path is fingerprinted.
+func ResolveImage(urlPath string) (name string, fingerprinted bool, found bool) {
+	contentPath := contentPrefix + strings.TrimPrefix(urlPath, imagesPrefix)
+	if assetPath, ok := ResolveAsset(contentPath); ok {
+		contentPath = assetPath
+		fingerprinted = true
+	}
+	name = strings.TrimPrefix(contentPath, contentPrefix)
+	if _, _, found = ImageSize(name); !found {
+		return "", false, false
+	}
+	return name, fingerprinted, true
+}
+
+// ReadImage returns the content of the named image, relative to the content directory.
+func ReadImage(name string) ([]byte, error) {
+	data, err := StaticContentFS.ReadFile(path.Join(contentDir, name))
+	if err != nil {
+		return nil, fmt.Errorf("read image: %w", err)
+	}
+	return data, nil
+}
+
+// encodingOf returns how the image data with the given extension is encoded. PNG images are always lossless and JPEG
+// images are always lossy, without transparency. WebP images are lossless if their image data is a VP8L chunk, rather
+// than a lossy VP8 chunk, which has transparency if it is preceded by an ALPH chunk.
+func encodingOf(ext string, data []byte) imageEncoding {
+	switch ext {
+	case ".png":
+		return encodingLossless
+	case ".webp":
+		// After the RIFF header, a WebP file is a sequence of chunks, each a FourCC, a little-endian size and the
+		// data, padded to an even length.
+		encoding := encodingLossy
+		for offset := 12; offset+8 <= len(data); {
+			switch string(data[offset : offset+4]) {
+			case "VP8L":
+				return encodingLossless
+			case "ALPH":
+				encoding = encodingLossyAlpha
+			case "VP8 ":
+				return encoding
+			}
+			size := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+			offset += 8 + size + size%2
+		}
+		return encoding
+	default:
+		return encodingLossy
+	}
+}
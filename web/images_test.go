@@ -0,0 +1,114 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package web_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/immanent-tech/www-immanent-tech/web"
+)
+
+func TestImageResizable(t *testing.T) {
+	tests := []struct {
+		name          string
+		wantResizable bool
+		wantLossless  bool
+	}{
+		{name: "logo.png", wantResizable: true, wantLossless: true},
+		{name: "logo-1024.webp", wantResizable: true, wantLossless: true},
+		{name: "foragd-screenshot.webp", wantResizable: true, wantLossless: false},
+		{name: "logo-512.webp", wantResizable: false, wantLossless: false},
+		{name: "missing.png", wantResizable: false, wantLossless: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := web.ImageResizable(tt.name); got != tt.wantResizable {
+				t.Errorf("ImageResizable(%q) = %t, want %t", tt.name, got, tt.wantResizable)
+			}
+			if got := web.ImageLossless(tt.name); got != tt.wantLossless {
+				t.Errorf("ImageLossless(%q) = %t, want %t", tt.name, got, tt.wantLossless)
+			}
+		})
+	}
+}
+
+func TestImageVariantWidth(t *testing.T) {
+	tests := []struct {
+		name      string
+		image     string
+		requested int
+		want      int
+	}{
+		{name: "full size", image: "logo.png", requested: 0, want: 4096},
+		{name: "negative", image: "logo.png", requested: -1, want: 4096},
+		{name: "rounded up", image: "logo.png", requested: 100, want: 320},
+		{name: "exact", image: "logo.png", requested: 640, want: 640},
+		{name: "between variants", image: "logo.png", requested: 641, want: 960},
+		{name: "wider than variants", image: "logo.png", requested: 2000, want: 4096},
+		{name: "wider than image", image: "logo.png", requested: 8000, want: 4096},
+		{name: "never upscaled", image: "apple-touch-icon.png", requested: 100, want: 180},
+		{name: "lossy", image: "foragd-screenshot.webp", requested: 320, want: 320},
+		{name: "not resized", image: "logo-512.webp", requested: 320, want: 512},
+		{name: "missing", image: "missing.png", requested: 320, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := web.ImageVariantWidth(tt.image, tt.requested); got != tt.want {
+				t.Errorf("ImageVariantWidth(%q, %d) = %d, want %d", tt.image, tt.requested, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImageSrcset(t *testing.T) {
+	tests := []struct {
+		name  string
+		image string
+		want  []string
+	}{
+		{
+			name:  "resized",
+			image: "logo.png",
+			want: []string{
+				web.ImageURL("logo.png", 320) + " 320w",
+				web.ImageURL("logo.png", 640) + " 640w",
+				web.ImageURL("logo.png", 960) + " 960w",
+				web.ImageURL("logo.png", 1280) + " 1280w",
+				web.ImageURL("logo.png", 1920) + " 1920w",
+				web.ImageURL("logo.png", 4096) + " 4096w",
+			},
+		},
+		{
+			name:  "narrower than variants",
+			image: "apple-touch-icon.png",
+		},
+		{
+			name:  "lossy",
+			image: "foragd-screenshot.webp",
+			want: []string{
+				web.ImageURL("foragd-screenshot.webp", 320) + " 320w",
+				web.ImageURL("foragd-screenshot.webp", 640) + " 640w",
+				web.ImageURL("foragd-screenshot.webp", 960) + " 960w",
+				web.ImageURL("foragd-screenshot.webp", 1280) + " 1280w",
+				web.ImageURL("foragd-screenshot.webp", 1600) + " 1600w",
+			},
+		},
+		{
+			name:  "not resized",
+			image: "logo-512.webp",
+		},
+		{
+			name:  "missing",
+			image: "missing.png",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, want := web.ImageSrcset(tt.image), strings.Join(tt.want, ", "); got != want {
+				t.Errorf("ImageSrcset(%q) = %q, want %q", tt.image, got, want)
+			}
+		})
+	}
+}
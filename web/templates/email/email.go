@@ -0,0 +1,46 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+// Package email contains templates for emails sent by the server. Emails are written as templ components and rendered
+// to html, with styles inlined for compatibility with mail clients, along with a plain text alternative derived from
+// the html.
+package email
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/a-h/templ"
+	"golang.org/x/net/html"
+)
+
+// Body is a rendered email body, with html and plain text alternatives.
+type Body struct {
+	HTML string
+	Text string
+}
+
+// Render renders the component into an email Body.
+func Render(ctx context.Context, component templ.Component) (*Body, error) {
+	var builder strings.Builder
+	if err := component.Render(ctx, &builder); err != nil {
+		return nil, fmt.Errorf("render email: %w", err)
+	}
+
+	doc, err := html.Parse(strings.NewReader(builder.String()))
+	if err != nil {
+		return nil, fmt.Errorf("parse email: %w", err)
+	}
+	inlineStyles(doc)
+
+	builder.Reset()
+	if err := html.Render(&builder, doc); err != nil {
+		return nil, fmt.Errorf("render email: %w", err)
+	}
+
+	return &Body{
+		HTML: builder.String(),
+		Text: toText(doc),
+	}, nil
+}
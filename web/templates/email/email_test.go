@@ -0,0 +1,77 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package email_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/a-h/templ"
+
+	"github.com/immanent-tech/www-immanent-tech/web/templates/email"
+)
+
+// update regenerates the golden files from the rendered emails: go test ./web/templates/email -update.
+var update = flag.Bool("update", false, "update golden files")
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name      string
+		component templ.Component
+	}{
+		{
+			name: "notification",
+			component: email.ContactNotificationEmail(&email.ContactNotification{
+				Reference:    "IT-ABCDEFGHIJ",
+				Category:     "General enquiry",
+				ContactEmail: "visitor@example.com",
+				Details:      "Hello,\n\nI would like to know more about <your> services & pricing.\n  - Thanks",
+				Attachments:  []string{"brief.pdf", "notes.txt"},
+			}),
+		},
+		{
+			name: "notification_no_attachments",
+			component: email.ContactNotificationEmail(&email.ContactNotification{
+				Reference:    "IT-ABCDEFGHIJ",
+				Category:     "General enquiry",
+				ContactEmail: "visitor@example.com",
+				Details:      "I would like to know more.",
+			}),
+		},
+		{
+			name:      "autoreply",
+			component: email.AutoReplyEmail(&email.AutoReply{Reference: "IT-ABCDEFGHIJ"}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := email.Render(t.Context(), tt.component)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			assertGolden(t, filepath.Join("testdata", tt.name+".html"), body.HTML)
+			assertGolden(t, filepath.Join("testdata", tt.name+".txt"), body.Text)
+		})
+	}
+}
+
+// assertGolden compares got with the contents of the golden file, or updates the golden file when -update is given.
+func assertGolden(t *testing.T, path, got string) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o600); err != nil {
+			t.Fatalf("write golden file: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file: %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s does not match:\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package email
+
+import (
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// rule is a style rule applied to email elements. The selector is either an element name or a class name prefixed
+// with a ".".
+type rule struct {
+	selector     string
+	declarations string
+}
+
+// stylesheet contains the styles for emails. As many mail clients ignore <style> elements, the styles are inlined into
+// the style attribute of each matching element when rendered. Rules are applied in order, with element rules listed
+// before class rules so that class rules take precedence. Any style attribute already on an element takes precedence
+// over the stylesheet.
+var stylesheet = []rule{
+	{"body", "margin: 0; padding: 24px; background-color: #f3f4f6; color: #1f2937; " +
+		"font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Helvetica, Arial, sans-serif; " +
+		"font-size: 16px; line-height: 1.5;"},
+	{"h1", "margin: 0 0 16px; font-size: 20px; font-weight: 600; line-height: 1.3;"},
+	{"p", "margin: 0 0 16px;"},
+	{"a", "color: #6d28d9;"},
+	{"ul", "margin: 0 0 16px; padding-left: 24px;"},
+	{"blockquote", "margin: 0 0 16px; padding: 8px 16px; border-left: 4px solid #d1d5db; color: #374151;"},
+	{"table", "border-collapse: collapse; margin: 0 0 16px;"},
+	{"th", "padding: 4px 16px 4px 0; text-align: left; vertical-align: top; font-weight: 600;"},
+	{"td", "padding: 4px 0; vertical-align: top;"},
+	{".container", "max-width: 600px; margin: 0 auto; padding: 24px; background-color: #ffffff; " +
+		"border-radius: 8px;"},
+	{".preserve", "white-space: pre-wrap;"},
+	{".footer", "margin: 24px 0 0; color: #6b7280; font-size: 14px;"},
+}
+
+// inlineStyles walks the document and sets the style attribute of each element from the stylesheet.
+func inlineStyles(node *html.Node) {
+	if node.Type == html.ElementNode {
+		var declarations []string
+		for _, r := range stylesheet {
+			if matches(node, r.selector) {
+				declarations = append(declarations, r.declarations)
+			}
+		}
+		if len(declarations) > 0 {
+			setStyle(node, strings.Join(declarations, " "))
+		}
+	}
+	for child := range node.ChildNodes() {
+		inlineStyles(child)
+	}
+}
+
+// setStyle sets the style attribute of the element, keeping any existing style declarations after those given so
+// that they take precedence.
+func setStyle(node *html.Node, style string) {
+	for i, attr := range node.Attr {
+		if attr.Key == "style" {
+			node.Attr[i].Val = style + " " + attr.Val
+			return
+		}
+	}
+	node.Attr = append(node.Attr, html.Attribute{Key: "style", Val: style})
+}
+
+// matches reports whether the element matches the selector.
+func matches(node *html.Node, selector string) bool {
+	if class, found := strings.CutPrefix(selector, "."); found {
+		return hasClass(node, class)
+	}
+	return node.Data == selector
+}
+
+// hasClass reports whether the element has the class.
+func hasClass(node *html.Node, class string) bool {
+	for _, attr := range node.Attr {
+		if attr.Key == "class" && slices.Contains(strings.Fields(attr.Val), class) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+// Copyright 2026 Joshua Rich <joshua.rich@gmail.com>.
+// SPDX-License-Identifier: 	AGPL-3.0-or-later
+
+package email
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	whitespace     = regexp.MustCompile(`\s+`)
+	excessNewlines = regexp.MustCompile(`\n{3,}`)
+)
+
+// textWriter builds the plain text alternative of an html email.
+type textWriter struct {
+	builder strings.Builder
+}
+
+// toText derives a plain text alternative from the html document. Block elements are separated by blank lines,
+// blockquotes are quoted with "> ", list items are prefixed with "- " and links are followed by their URL. Whitespace
+// is collapsed, except in <pre> elements and elements with the "preserve" class.
+func toText(doc *html.Node) string {
+	writer := &textWriter{}
+	writer.walk(doc, false)
+	return writer.String()
+}
+
+// String returns the text, tidied of trailing whitespace on each line and excess blank lines.
+func (w *textWriter) String() string {
+	lines := strings.Split(w.builder.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	text := excessNewlines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(text) + "\n"
+}
+
+func (w *textWriter) write(text string) {
+	w.builder.WriteString(text)
+}
+
+// atLineStart reports whether the next text written will start a new line.
+func (w *textWriter) atLineStart() bool {
+	text := w.builder.String()
+	return text == "" || strings.HasSuffix(text, "\n")
+}
+
+// breakLines ensures the text ends with at least count newlines.
+func (w *textWriter) breakLines(count int) {
+	text := w.builder.String()
+	if text == "" {
+		return
+	}
+	trailing := len(text) - len(strings.TrimRight(text, "\n"))
+	for range count - trailing {
+		w.builder.WriteString("\n")
+	}
+}
+
+//nolint:cyclop
+func (w *textWriter) walk(node *html.Node, preserve bool) {
+	switch node.Type {
+	case html.TextNode:
+		text := node.Data
+		if !preserve {
+			text = whitespace.ReplaceAllString(text, " ")
+			if w.atLineStart() {
+				text = strings.TrimLeft(text, " ")
+			}
+		}
+		w.write(text)
+		return
+	case html.ElementNode:
+	default:
+		w.walkChildren(node, preserve)
+		return
+	}
+
+	preserve = preserve || node.DataAtom == atom.Pre || hasClass(node, "preserve")
+
+	switch node.DataAtom {
+	case atom.Head, atom.Style, atom.Script, atom.Title:
+		return
+	case atom.Br:
+		w.write("\n")
+	case atom.Hr:
+		w.breakLines(2)
+		w.write("---")
+		w.breakLines(2)
+	case atom.P, atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Pre, atom.Table, atom.Ul, atom.Ol:
+		w.breakLines(2)
+		w.walkChildren(node, preserve)
+		w.breakLines(2)
+	case atom.Div, atom.Tr:
+		w.breakLines(1)
+		w.walkChildren(node, preserve)
+		w.breakLines(1)
+	case atom.Li:
+		w.breakLines(1)
+		w.write("- ")
+		w.walkChildren(node, preserve)
+		w.breakLines(1)
+	case atom.Th:
+		w.walkChildren(node, preserve)
+		w.write(": ")
+	case atom.Blockquote:
+		quoted := &textWriter{}
+		quoted.walkChildren(node, preserve)
+		w.breakLines(2)
+		for line := range strings.Lines(strings.TrimSpace(quoted.builder.String())) {
+			w.write("> " + line)
+		}
+		w.breakLines(2)
+	case atom.A:
+		start := w.builder.Len()
+		w.walkChildren(node, preserve)
+		text := w.builder.String()[start:]
+		if href := attribute(node, "href"); href != "" && href != text && strings.TrimPrefix(href, "mailto:") != text {
+			w.write(" (" + href + ")")
+		}
+	default:
+		w.walkChildren(node, preserve)
+	}
+}
+
+func (w *textWriter) walkChildren(node *html.Node, preserve bool) {
+	for child := range node.ChildNodes() {
+		w.walk(child, preserve)
+	}
+}
+
+// attribute returns the value of the attribute of the element, or an empty string if it does not have it.
+func attribute(node *html.Node, key string) string {
+	for _, attr := range node.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
@@ -13,6 +13,7 @@ import "github.com/a-h/templ"
 import templruntime "github.com/a-h/templ/runtime"
 
 import "github.com/immanent-tech/www-immanent-tech/web/templates/partials"
+import "github.com/immanent-tech/www-immanent-tech/web"
 
 func Landing() templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
@@ -43,7 +44,20 @@ func Landing() templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "<div class=\"mx-auto max-w-2xl py-32 sm:py-48 lg:py-56 text-center\"><div class=\"aura aura-rainbow\"><img src=\"/content/immanent-tech-icon-dark.svg\" width=\"256\" height=\"256\" alt=\"Immanent Tech Logo\" class=\"mx-auto\"></div><div class=\"text-center mt-8\"><h1 class=\"text-5xl font-semibold tracking-tight text-balance\">Immanent Tech</h1><p class=\"mt-8 text-lg font-medium text-pretty text-neutral-content\">Building inherent, simple and useful tech solutions</p><div class=\"mt-10 flex items-center justify-center gap-x-6\"><a href=\"/work\" role=\"button\" class=\"btn btn-primary\">Our Work</a> <a href=\"/contact\" role=\"button\" class=\"text-sm/6 font-semibold btn btn-secondary\">Contact <span aria-hidden=\"true\">→</span></a></div></div></div><div aria-live=\"assertive\" class=\"pointer-events-none fixed inset-0 flex items-end sm:items-start z-999\"><div id=\"notifications\" class=\"flex w-full flex-col items-center space-y-4 m-4 sm:items-end\"></div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "<div class=\"mx-auto max-w-2xl py-32 sm:py-48 lg:py-56 text-center\"><div class=\"aura aura-rainbow\"><img src=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var2 string
+		templ_7745c5c3_Var2, templ_7745c5c3_Err = templ.ResolveAttributeValue(web.AssetURL("immanent-tech-icon-dark.svg"))
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `templates/landing.templ`, Line: 17, Col: 57}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ_7745c5c3_Var2)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "\" width=\"256\" height=\"256\" alt=\"Immanent Tech Logo\" class=\"mx-auto\"></div><div class=\"text-center mt-8\"><h1 class=\"text-5xl font-semibold tracking-tight text-balance\">Immanent Tech</h1><p class=\"mt-8 text-lg font-medium text-pretty text-neutral-content\">Building inherent, simple and useful tech solutions</p><div class=\"mt-10 flex items-center justify-center gap-x-6\"><a href=\"/work\" role=\"button\" class=\"btn btn-primary\">Our Work</a> <a href=\"/contact\" role=\"button\" class=\"text-sm/6 font-semibold btn btn-secondary\">Contact <span aria-hidden=\"true\">→</span></a></div></div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -78,7 +78,7 @@ func ShowNotification(notification *Notification, options ...notificationOption)
 		switch {
 		case notification.Status <= 200:
 			alertColor = "alert-success"
-		case notification.Status >= 400 && notification.Status <= 500:
+		case notification.Status >= 400 && notification.Status < 500:
 			alertColor = "alert-warning"
 		case notification.Status >= 500:
 			alertColor = "alert-error"
@@ -156,7 +156,7 @@ func ShowNotification(notification *Notification, options ...notificationOption)
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-		case notification.Status >= 400 && notification.Status <= 500:
+		case notification.Status >= 400 && notification.Status < 500:
 			fontColor = "warning-content"
 			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 10, "<svg aria-hidden=\"true\" xmlns=\"http://www.w3.org/2000/svg\" width=\"24\" height=\"24\" viewBox=\"0 0 24 24\" fill=\"none\" stroke=\"currentColor\" stroke-width=\"2\" stroke-linecap=\"round\" stroke-linejoin=\"round\" class=\"icon icon-tabler icons-tabler-outline icon-tabler-alert-triangle size-6 text-warning-content\"><path stroke=\"none\" d=\"M0 0h24v24H0z\" fill=\"none\"></path><path d=\"M12 9v4\"></path><path d=\"M10.363 3.591l-8.106 13.534a1.914 1.914 0 0 0 1.636 2.871h16.214a1.914 1.914 0 0 0 1.636 -2.87l-8.106 -13.536a1.914 1.914 0 0 0 -3.274 0\"></path><path d=\"M12 16h.01\"></path></svg>")
 			if templ_7745c5c3_Err != nil {
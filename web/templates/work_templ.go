@@ -40,7 +40,17 @@ func Work() templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div class=\"mx-auto sm:max-w-4xl\"><div class=\"mx-auto max-w-2xl lg:mx-0\"><h2 class=\"text-4xl font-semibold tracking-tight text-pretty sm:text-5xl\">Our work</h2><p class=\"mt-6 text-lg/8\">We build small and wonderfully useful web things.</p></div><ul role=\"list\" class=\"mx-auto mt-20 grid max-w-2xl grid-cols-1 gap-x-8 gap-y-16 sm:grid-cols-2 lg:mx-0 lg:max-w-none lg:grid-cols-3\"><li><a href=\"https://foragd.app\"><img src=\"/content/foragd-screenshot.webp\" alt=\"\" class=\"aspect-3/2 w-full rounded-2xl object-cover outline-1 -outline-offset-1 outline-neutral/5\"></a><h3 class=\"mt-6 text-lg/8 font-semibold tracking-tight\">Foragd</h3><p class=\"text-base/7\">A beautiful, web based, online feed reader.</p><ul role=\"list\" class=\"mt-6 flex gap-x-6\"><li><a href=\"https://foragd.app\" target=\"_blank\" rel=\"noopener\" class=\"link link-hover\"><span class=\"sr-only\">Website</span>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div class=\"mx-auto sm:max-w-4xl\"><div class=\"mx-auto max-w-2xl lg:mx-0\"><h2 class=\"text-4xl font-semibold tracking-tight text-pretty sm:text-5xl\">Our work</h2><p class=\"mt-6 text-lg/8\">We build small and wonderfully useful web things.</p></div><ul role=\"list\" class=\"mx-auto mt-20 grid max-w-2xl grid-cols-1 gap-x-8 gap-y-16 sm:grid-cols-2 lg:mx-0 lg:max-w-none lg:grid-cols-3\"><li><a href=\"https://foragd.app\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = ResponsiveImage("foragd-screenshot.webp", "", "(min-width: 1024px) 18rem, (min-width: 640px) 21rem, 100vw", templ.Attributes{
+			"class": "aspect-3/2 w-full rounded-2xl object-cover outline-1 -outline-offset-1 outline-neutral/5",
+		}).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "</a><h3 class=\"mt-6 text-lg/8 font-semibold tracking-tight\">Foragd</h3><p class=\"text-base/7\">A beautiful, web based, online feed reader.</p><ul role=\"list\" class=\"mt-6 flex gap-x-6\"><li><a href=\"https://foragd.app\" target=\"_blank\" rel=\"noopener\" class=\"link link-hover\"><span class=\"sr-only\">Website</span>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -48,7 +58,7 @@ func Work() templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "</a></li><li><a href=\"https://github.com/immanent-tech/foragd\" class=\"link link-hover\"><span class=\"sr-only\">Github</span>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "</a></li><li><a href=\"https://github.com/immanent-tech/foragd\" class=\"link link-hover\"><span class=\"sr-only\">Github</span>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -56,7 +66,7 @@ func Work() templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "</a></li></ul></li><li><a href=\"https://github.com/immanent-tech/go-syndication\"><img src=\"https://opengraph.githubassets.com/0/immanent-tech/go-syndication\" alt=\"go-syndication GitHub Repository Thumbnail\" class=\"aspect-3/2 w-full rounded-2xl object-cover outline-1 -outline-offset-1 outline-neutral\"></a><h3 class=\"mt-6 text-lg/8 font-semibold tracking-tight\">go-syndication</h3><p class=\"text-base/7\">Syndication (RDF/RSS/Atom/JSONFeed) library for Go.</p><ul role=\"list\" class=\"mt-6 flex gap-x-6\"><li><a href=\"https://github.com/immanent-tech/go-syndication\" class=\"link link-hover\"><span class=\"sr-only\">Github</span>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "</a></li></ul></li><li><a href=\"https://github.com/immanent-tech/go-syndication\"><img src=\"https://opengraph.githubassets.com/0/immanent-tech/go-syndication\" alt=\"go-syndication GitHub Repository Thumbnail\" class=\"aspect-3/2 w-full rounded-2xl object-cover outline-1 -outline-offset-1 outline-neutral\"></a><h3 class=\"mt-6 text-lg/8 font-semibold tracking-tight\">go-syndication</h3><p class=\"text-base/7\">Syndication (RDF/RSS/Atom/JSONFeed) library for Go.</p><ul role=\"list\" class=\"mt-6 flex gap-x-6\"><li><a href=\"https://github.com/immanent-tech/go-syndication\" class=\"link link-hover\"><span class=\"sr-only\">Github</span>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -64,7 +74,7 @@ func Work() templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "</a></li></ul></li><li><a href=\"https://github.com/immanent-tech/slog-elasticsearch\"><img src=\"https://opengraph.githubassets.com/0/immanent-tech/slog-elasticsearch\" alt=\"slog-elasticsearch GitHub Repository Thumbnail\" class=\"aspect-3/2 w-full rounded-2xl object-cover outline-1 -outline-offset-1 outline-neutral\"></a><h3 class=\"mt-6 text-lg/8 font-semibold tracking-tight\">slog-elasticsearch</h3><p class=\"text-base/7\">slog Elasticsearch handler.</p><ul role=\"list\" class=\"mt-6 flex gap-x-6\"><li><a href=\"https://github.com/immanent-tech/slog-elasticsearch\" class=\"link link-hover\"><span class=\"sr-only\">Github</span>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "</a></li></ul></li><li><a href=\"https://github.com/immanent-tech/slog-elasticsearch\"><img src=\"https://opengraph.githubassets.com/0/immanent-tech/slog-elasticsearch\" alt=\"slog-elasticsearch GitHub Repository Thumbnail\" class=\"aspect-3/2 w-full rounded-2xl object-cover outline-1 -outline-offset-1 outline-neutral\"></a><h3 class=\"mt-6 text-lg/8 font-semibold tracking-tight\">slog-elasticsearch</h3><p class=\"text-base/7\">slog Elasticsearch handler.</p><ul role=\"list\" class=\"mt-6 flex gap-x-6\"><li><a href=\"https://github.com/immanent-tech/slog-elasticsearch\" class=\"link link-hover\"><span class=\"sr-only\">Github</span>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -72,7 +82,7 @@ func Work() templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "</a></li></ul></li></ul></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "</a></li></ul></li></ul></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}